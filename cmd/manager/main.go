@@ -1,6 +1,8 @@
 package main
 
 import (
+	"os"
+
 	"github.com/armory/spinnaker-operator/pkg/accounts"
 	"github.com/armory/spinnaker-operator/pkg/accounts/kubernetes"
 	"github.com/armory/spinnaker-operator/pkg/apis"
@@ -21,5 +23,8 @@ func main() {
 	spinnakeraccount.TypesFactory = interfaces.DefaultTypesFactory
 	accounts.TypesFactory = interfaces.DefaultTypesFactory
 	kubernetes.TypesFactory = interfaces.DefaultTypesFactory
+	if len(os.Args) > 1 && os.Args[1] == operator.ValidateAccountCommand {
+		os.Exit(operator.ValidateAccount(os.Args[2:], apis.AddToScheme, os.Stdout))
+	}
 	operator.Start(apis.AddToScheme)
 }
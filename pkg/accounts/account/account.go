@@ -4,6 +4,7 @@ import (
 	"context"
 	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
 	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -48,3 +49,70 @@ type Account interface {
 type AccountValidator interface {
 	Validate(interfaces.SpinnakerService, client.Client, context.Context, logr.Logger) error
 }
+
+// Endpoint is a URL an account connects to
+type Endpoint struct {
+	// Field is the path of the setting holding the URL
+	Field string
+	// Path is the JSON pointer to the setting in the SpinnakerAccount, used to suggest fixes
+	Path string
+	URL  string
+	// Schemes accepted for the URL
+	Schemes []string
+}
+
+// EndpointProvider is implemented by accounts declaring the endpoints they connect to
+type EndpointProvider interface {
+	GetEndpoints() []Endpoint
+}
+
+// TargetProvider is implemented by accounts able to tell what they point at, e.g. a cluster
+type TargetProvider interface {
+	// GetTarget returns the normalized identity of the account's target, empty if unknown
+	GetTarget() string
+}
+
+// RequiredFieldsChecker is implemented by account types checking the fields their SpinnakerAccount must set
+type RequiredFieldsChecker interface {
+	CheckRequiredFields(account interfaces.SpinnakerAccount) field.ErrorList
+}
+
+// CanonicalField is a setting of spec.settings restricted to canonical values
+type CanonicalField struct {
+	Name string
+	// Allowed values of the setting, it must be a boolean if empty
+	Allowed []string
+}
+
+// CanonicalFieldsProvider is implemented by account types declaring settings restricted to canonical values
+type CanonicalFieldsProvider interface {
+	GetCanonicalFields() []CanonicalField
+}
+
+// NumericBound is the range of values of a numeric setting of spec.settings
+type NumericBound struct {
+	Name string
+	// Min and Max are inclusive, use math.Inf for an unbounded side
+	Min, Max float64
+}
+
+// NumericBoundsProvider is implemented by account types declaring the range of their numeric settings
+type NumericBoundsProvider interface {
+	GetNumericBounds() []NumericBound
+}
+
+// SettingConflict is a pair of settings of spec.settings that can't be enabled together
+type SettingConflict struct {
+	First, Second string
+}
+
+// SettingConflictsProvider is implemented by account types declaring mutually exclusive settings
+type SettingConflictsProvider interface {
+	GetSettingConflicts() []SettingConflict
+}
+
+// LabelSelectorsProvider is implemented by account types with settings of spec.settings holding label selectors,
+// either as a string or as a LabelSelector object
+type LabelSelectorsProvider interface {
+	GetLabelSelectorSettings() []string
+}
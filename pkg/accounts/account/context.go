@@ -0,0 +1,128 @@
+package account
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ValidationOptions control which checks account validators perform
+type ValidationOptions struct {
+	// Connectivity enables checks connecting to the account's endpoints
+	Connectivity bool
+	// ProviderConnectivity overrides Connectivity for the providers it lists, keyed by lowercase provider name
+	ProviderConnectivity map[string]bool
+	// ExpiryWarningWindow is how long before their expiry credentials raise a warning
+	ExpiryWarningWindow time.Duration
+	// Strict denies accounts that would otherwise only get a warning
+	Strict bool
+	// APIReader reads from the API server, bypassing the manager's cache and the list/watch permissions it needs
+	APIReader client.Reader
+	// Previous is the account being replaced by an update, if any
+	Previous Account
+}
+
+// ValidationContext carries the validation options of a request and collects the warnings raised by validators
+type ValidationContext struct {
+	Options  ValidationOptions
+	mu       sync.Mutex
+	warnings []string
+	probed   bool
+}
+
+type validationContextKey struct{}
+
+func NewValidationContext(ctx context.Context, opts ValidationOptions) context.Context {
+	return context.WithValue(ctx, validationContextKey{}, &ValidationContext{Options: opts})
+}
+
+func ValidationContextFrom(ctx context.Context) (*ValidationContext, bool) {
+	c, ok := ctx.Value(validationContextKey{}).(*ValidationContext)
+	return c, ok
+}
+
+// Warn records a warning that doesn't fail validation. Warnings are dropped if there's no validation context.
+func Warn(ctx context.Context, format string, args ...interface{}) {
+	if c, ok := ValidationContextFrom(ctx); ok {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.warnings = append(c.warnings, fmt.Sprintf(format, args...))
+	}
+}
+
+// Warnings returns the warnings recorded so far
+func (c *ValidationContext) Warnings() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string{}, c.warnings...)
+}
+
+// ConnectivityEnabled returns true if validators may connect to the account's endpoints, in which case the
+// validation is recorded as probed. Without a validation context, connectivity checks are enabled.
+func ConnectivityEnabled(ctx context.Context) bool {
+	return ProviderConnectivityEnabled(ctx, "")
+}
+
+// ProviderConnectivityEnabled returns true if validators may connect to the endpoints of the provider's accounts,
+// falling back to ConnectivityEnabled for providers without an override.
+func ProviderConnectivityEnabled(ctx context.Context, provider string) bool {
+	c, ok := ValidationContextFrom(ctx)
+	if !ok {
+		return true
+	}
+	enabled, ok := c.Options.ProviderConnectivity[strings.ToLower(provider)]
+	if !ok {
+		enabled = c.Options.Connectivity
+	}
+	if enabled {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.probed = true
+	}
+	return enabled
+}
+
+// Probed returns true if validators were allowed to connect to the account's endpoints.
+// Results of validations that weren't probed only depend on the account.
+func (c *ValidationContext) Probed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.probed
+}
+
+// ExpiryWarningWindow returns how long before their expiry credentials raise a warning.
+// Without a validation context, DefaultExpiryWarningWindow is used.
+func ExpiryWarningWindow(ctx context.Context) time.Duration {
+	if c, ok := ValidationContextFrom(ctx); ok {
+		return c.Options.ExpiryWarningWindow
+	}
+	return DefaultExpiryWarningWindow
+}
+
+// Strict returns true if validators should deny accounts instead of warning about them
+func Strict(ctx context.Context) bool {
+	if c, ok := ValidationContextFrom(ctx); ok {
+		return c.Options.Strict
+	}
+	return false
+}
+
+// APIReader returns the reader bypassing the manager's cache, or fallback if there's none
+func APIReader(ctx context.Context, fallback client.Reader) client.Reader {
+	if c, ok := ValidationContextFrom(ctx); ok && c.Options.APIReader != nil {
+		return c.Options.APIReader
+	}
+	return fallback
+}
+
+// PreviousFrom returns the account being replaced by an update, if any
+func PreviousFrom(ctx context.Context) (Account, bool) {
+	if c, ok := ValidationContextFrom(ctx); ok && c.Options.Previous != nil {
+		return c.Options.Previous, true
+	}
+	return nil, false
+}
@@ -0,0 +1,46 @@
+package account
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidationContext(t *testing.T) {
+	// Without a context, warnings are dropped and connectivity is enabled
+	Warn(context.TODO(), "dropped")
+	assert.True(t, ConnectivityEnabled(context.TODO()))
+
+	ctx := NewValidationContext(context.TODO(), ValidationOptions{Connectivity: false})
+	Warn(ctx, "warning %d", 1)
+	Warn(ctx, "warning %d", 2)
+	c, ok := ValidationContextFrom(ctx)
+	if assert.True(t, ok) {
+		assert.Equal(t, []string{"warning 1", "warning 2"}, c.Warnings())
+	}
+	assert.False(t, ConnectivityEnabled(ctx))
+	assert.False(t, c.Probed())
+
+	ctx = NewValidationContext(context.TODO(), ValidationOptions{Connectivity: true})
+	c, _ = ValidationContextFrom(ctx)
+	assert.False(t, c.Probed())
+	assert.True(t, ConnectivityEnabled(ctx))
+	assert.True(t, c.Probed())
+}
+
+func TestProviderConnectivityEnabled(t *testing.T) {
+	ctx := NewValidationContext(context.TODO(), ValidationOptions{
+		Connectivity:         true,
+		ProviderConnectivity: map[string]bool{"docker": false},
+	})
+	assert.False(t, ProviderConnectivityEnabled(ctx, "Docker"))
+	assert.True(t, ProviderConnectivityEnabled(ctx, "Kubernetes"))
+
+	ctx = NewValidationContext(context.TODO(), ValidationOptions{
+		Connectivity:         false,
+		ProviderConnectivity: map[string]bool{"kubernetes": true},
+	})
+	assert.True(t, ProviderConnectivityEnabled(ctx, "Kubernetes"))
+	assert.False(t, ProviderConnectivityEnabled(ctx, "docker"))
+}
@@ -0,0 +1,80 @@
+package account
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	certutil "k8s.io/client-go/util/cert"
+)
+
+// DefaultExpiryWarningWindow is how long before their expiry credentials raise a warning by default
+const DefaultExpiryWarningWindow = 7 * 24 * time.Hour
+
+var ErrCredentialExpired = errors.New("credential expired")
+
+// now returns the current time, overridden in tests
+var now = time.Now
+
+// CheckCertificateExpiry fails if a PEM encoded certificate is expired and warns if it expires soon.
+// Data that can't be parsed as certificates is ignored.
+func CheckCertificateExpiry(ctx context.Context, name string, pemData []byte) error {
+	certs, err := certutil.ParseCertsPEM(pemData)
+	if err != nil {
+		return nil
+	}
+	for _, c := range certs {
+		if err := checkExpiry(ctx, name, c.NotAfter); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckTokenExpiry fails if a JWT bearer token is expired and warns if it expires soon.
+// Tokens that are not JWTs or have no exp claim are ignored.
+func CheckTokenExpiry(ctx context.Context, name, token string) error {
+	exp, ok := tokenExpiry(token)
+	if !ok {
+		return nil
+	}
+	return checkExpiry(ctx, name, exp)
+}
+
+func checkExpiry(ctx context.Context, name string, notAfter time.Time) error {
+	t := now()
+	if !t.Before(notAfter) {
+		return fmt.Errorf("%w: %s expired on %s", ErrCredentialExpired, name, notAfter.UTC().Format(time.RFC3339))
+	}
+	if notAfter.Sub(t) < ExpiryWarningWindow(ctx) {
+		Warn(ctx, "%s expires on %s", name, notAfter.UTC().Format(time.RFC3339))
+	}
+	return nil
+}
+
+// tokenExpiry returns the exp claim of a JWT
+func tokenExpiry(token string) (time.Time, bool) {
+	parts := strings.Split(strings.TrimSpace(token), ".")
+	if len(parts) != 3 {
+		return time.Time{}, false
+	}
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}, false
+	}
+	claims := struct {
+		Exp *json.Number `json:"exp"`
+	}{}
+	if err := json.Unmarshal(b, &claims); err != nil || claims.Exp == nil {
+		return time.Time{}, false
+	}
+	exp, err := claims.Exp.Float64()
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(exp), 0), true
+}
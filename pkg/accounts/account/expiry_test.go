@@ -0,0 +1,109 @@
+package account
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testNow = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestCheckCertificateExpiry(t *testing.T) {
+	defer func(n func() time.Time) { now = n }(now)
+	now = func() time.Time { return testNow }
+
+	cases := []struct {
+		name        string
+		notAfter    time.Time
+		errExpected bool
+		warnings    []string
+	}{
+		{"expired", testNow.Add(-time.Hour), true, nil},
+		{"near expiry", testNow.Add(48 * time.Hour), false, []string{"client certificate expires on 2026-01-03T00:00:00Z"}},
+		{"valid", testNow.Add(365 * 24 * time.Hour), false, nil},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctx := NewValidationContext(context.TODO(), ValidationOptions{ExpiryWarningWindow: DefaultExpiryWarningWindow})
+			err := CheckCertificateExpiry(ctx, "client certificate", newTestCertificate(t, c.notAfter))
+			assert.Equal(t, c.errExpected, err != nil)
+			if c.errExpected {
+				assert.True(t, errors.Is(err, ErrCredentialExpired))
+				assert.Equal(t, "credential expired: client certificate expired on 2025-12-31T23:00:00Z", err.Error())
+			}
+			vc, _ := ValidationContextFrom(ctx)
+			assert.Equal(t, c.warnings, nilIfEmpty(vc.Warnings()))
+		})
+	}
+
+	assert.Nil(t, CheckCertificateExpiry(context.TODO(), "not a certificate", []byte("garbage")))
+}
+
+func TestCheckTokenExpiry(t *testing.T) {
+	defer func(n func() time.Time) { now = n }(now)
+	now = func() time.Time { return testNow }
+
+	cases := []struct {
+		name        string
+		token       string
+		errExpected bool
+		warnings    []string
+	}{
+		{"expired", newTestToken(fmt.Sprintf(`{"exp":%d}`, testNow.Add(-time.Minute).Unix())), true, nil},
+		{"near expiry", newTestToken(fmt.Sprintf(`{"exp":%d}`, testNow.Add(time.Hour).Unix())), false, []string{"token expires on 2026-01-01T01:00:00Z"}},
+		{"valid", newTestToken(fmt.Sprintf(`{"exp":%d}`, testNow.Add(30*24*time.Hour).Unix())), false, nil},
+		{"no exp claim", newTestToken(`{"sub":"spinnaker"}`), false, nil},
+		{"opaque token", "abcdef0123456789", false, nil},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctx := NewValidationContext(context.TODO(), ValidationOptions{ExpiryWarningWindow: DefaultExpiryWarningWindow})
+			err := CheckTokenExpiry(ctx, "token", c.token)
+			assert.Equal(t, c.errExpected, err != nil)
+			vc, _ := ValidationContextFrom(ctx)
+			assert.Equal(t, c.warnings, nilIfEmpty(vc.Warnings()))
+		})
+	}
+}
+
+func newTestCertificate(t *testing.T, notAfter time.Time) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "spinnaker"},
+		NotBefore:    notAfter.Add(-365 * 24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func newTestToken(claims string) string {
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString([]byte(`{"alg":"none"}`)) + "." + enc.EncodeToString([]byte(claims)) + ".sig"
+}
+
+func nilIfEmpty(s []string) []string {
+	if len(s) == 0 {
+		return nil
+	}
+	return s
+}
@@ -0,0 +1,44 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+)
+
+var (
+	ErrProxyUnreachable    = errors.New("proxy unreachable")
+	ErrEndpointUnreachable = errors.New("endpoint unreachable")
+)
+
+const proxyDialTimeout = 5 * time.Second
+
+// proxyPorts are the default ports of proxy schemes
+var proxyPorts = map[string]string{"http": "80", "https": "443", "socks5": "1080"}
+
+// CheckProxyReachable returns an error if no connection can be opened to the proxy
+func CheckProxyReachable(ctx context.Context, proxy *url.URL) error {
+	port := proxy.Port()
+	if port == "" {
+		port = proxyPorts[proxy.Scheme]
+	}
+	d := net.Dialer{Timeout: proxyDialTimeout}
+	conn, err := d.DialContext(ctx, "tcp", net.JoinHostPort(proxy.Hostname(), port))
+	if err != nil {
+		return fmt.Errorf("%w: unable to connect to proxy %s, check the proxy URL and that the proxy is running:\n  %v", ErrProxyUnreachable, proxy.Redacted(), err)
+	}
+	return conn.Close()
+}
+
+// ClassifyConnectionError returns an error telling the endpoint couldn't be reached for errors opening a connection.
+// Other errors are returned as is.
+func ClassifyConnectionError(name string, err error) error {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return fmt.Errorf("%w: unable to connect to %s:\n  %v", ErrEndpointUnreachable, name, err)
+	}
+	return err
+}
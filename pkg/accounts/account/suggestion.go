@@ -0,0 +1,58 @@
+package account
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// Suggestion is a JSON patch operation that would fix a validation error. Suggestions are advisory,
+// they are shown to users but never applied.
+type Suggestion struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// suggestionError carries the suggestions fixing an error
+type suggestionError struct {
+	err         error
+	suggestions []Suggestion
+}
+
+func (e *suggestionError) Error() string {
+	return e.err.Error()
+}
+
+func (e *suggestionError) Unwrap() error {
+	return e.err
+}
+
+// WithSuggestions attaches suggestions fixing the error
+func WithSuggestions(err error, s ...Suggestion) error {
+	if err == nil || len(s) == 0 {
+		return err
+	}
+	return &suggestionError{err: err, suggestions: s}
+}
+
+// SuggestionsFrom returns the suggestions attached to the error or any error it wraps
+func SuggestionsFrom(err error) []Suggestion {
+	var se *suggestionError
+	if errors.As(err, &se) {
+		return se.suggestions
+	}
+	return nil
+}
+
+// FormatSuggestions renders suggestions as a JSON patch
+func FormatSuggestions(s []Suggestion) string {
+	b := &bytes.Buffer{}
+	enc := json.NewEncoder(b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(s); err != nil {
+		return ""
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
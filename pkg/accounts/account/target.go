@@ -0,0 +1,28 @@
+package account
+
+import (
+	"net/url"
+	"strings"
+)
+
+// NormalizeURL returns the URL with a lower case scheme and host, an explicit default port and no trailing slash,
+// so that URLs of the same endpoint compare equal
+func NormalizeURL(raw string) string {
+	raw = strings.TrimSpace(raw)
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return raw
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	if u.Port() == "" {
+		switch u.Scheme {
+		case "https":
+			u.Host += ":443"
+		case "http":
+			u.Host += ":80"
+		}
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	return u.String()
+}
@@ -0,0 +1,53 @@
+package account
+
+import (
+	"bytes"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	ErrEndpointCertificateSelfSigned       = errors.New("endpoint certificate is self-signed")
+	ErrEndpointCertificateExpired          = errors.New("endpoint certificate expired")
+	ErrEndpointCertificateHostnameMismatch = errors.New("endpoint certificate hostname mismatch")
+	ErrEndpointCertificateUnknownAuthority = errors.New("endpoint certificate signed by unknown authority")
+)
+
+// ClassifyTLSError returns an error telling how to fix the certificate problem causing a connection error.
+// Errors not caused by the endpoint's certificate are returned as is.
+func ClassifyTLSError(name string, err error) error {
+	var hostErr x509.HostnameError
+	if errors.As(err, &hostErr) {
+		valid := append(append([]string{}, hostErr.Certificate.DNSNames...), ipStrings(hostErr.Certificate)...)
+		return fmt.Errorf("%w: %s connects to %s but the certificate is only valid for %s, connect to one of these names or fix the certificate:\n  %v",
+			ErrEndpointCertificateHostnameMismatch, name, hostErr.Host, strings.Join(valid, ", "), err)
+	}
+	var invalidErr x509.CertificateInvalidError
+	if errors.As(err, &invalidErr) && invalidErr.Reason == x509.Expired {
+		return fmt.Errorf("%w: the certificate of %s expired on %s, renew it:\n  %v",
+			ErrEndpointCertificateExpired, name, invalidErr.Cert.NotAfter.UTC().Format(time.RFC3339), err)
+	}
+	var authorityErr x509.UnknownAuthorityError
+	if errors.As(err, &authorityErr) {
+		if c := authorityErr.Cert; c != nil && isSelfSigned(c) {
+			return fmt.Errorf("%w: %s uses a self-signed certificate, add it to the account's CA bundle:\n  %v", ErrEndpointCertificateSelfSigned, name, err)
+		}
+		return fmt.Errorf("%w: the certificate of %s is signed by an untrusted CA, add the CA to the account's CA bundle:\n  %v", ErrEndpointCertificateUnknownAuthority, name, err)
+	}
+	return err
+}
+
+func isSelfSigned(c *x509.Certificate) bool {
+	return bytes.Equal(c.RawIssuer, c.RawSubject) && c.CheckSignature(c.SignatureAlgorithm, c.RawTBSCertificate, c.Signature) == nil
+}
+
+func ipStrings(c *x509.Certificate) []string {
+	ips := make([]string, 0, len(c.IPAddresses))
+	for _, ip := range c.IPAddresses {
+		ips = append(ips, ip.String())
+	}
+	return ips
+}
@@ -0,0 +1,133 @@
+package account
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/armory/spinnaker-operator/pkg/util"
+)
+
+const (
+	// ProbePoolingEnv shares transports, and so connections, between the connectivity probes of accounts reaching the
+	// same host with the same certificates. Defaults to true.
+	ProbePoolingEnv = "PROBE_CONNECTION_POOLING"
+	// ProbeMaxIdleConnsPerHostEnv is the number of idle connections kept per host
+	ProbeMaxIdleConnsPerHostEnv = "PROBE_MAX_IDLE_CONNS_PER_HOST"
+	// ProbeIdleConnTimeoutEnv is how long idle connections are kept
+	ProbeIdleConnTimeoutEnv = "PROBE_IDLE_CONN_TIMEOUT"
+)
+
+const (
+	defaultMaxIdleConnsPerHost = 2
+	defaultIdleConnTimeout     = 90 * time.Second
+	// maxPooledTransports bounds the transports kept, the oldest one is closed when a new one exceeds it
+	maxPooledTransports = 64
+)
+
+// TransportKey identifies the probes that can share a transport
+type TransportKey struct {
+	Host       string
+	ServerName string
+	Insecure   bool
+	// CAFingerprint is the fingerprint of the CA bundle trusted, empty when trusting the system roots
+	CAFingerprint string
+	// ClientFingerprint is the fingerprint of the client certificate and key, empty without client certificate
+	ClientFingerprint string
+}
+
+// Fingerprint returns the SHA-256 of the given data, or an empty string if there's none
+func Fingerprint(data ...[]byte) string {
+	h := sha256.New()
+	n := 0
+	for _, d := range data {
+		h.Write(d)
+		n += len(d)
+	}
+	if n == 0 {
+		return ""
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// TransportPool keeps transports with keep-alive connections to reuse across probes
+type TransportPool struct {
+	maxIdleConnsPerHost int
+	idleConnTimeout     time.Duration
+
+	mu         sync.Mutex
+	transports map[TransportKey]*http.Transport
+	order      []TransportKey
+}
+
+// NewTransportPool returns a pool keeping up to maxIdleConnsPerHost idle connections per host for idleConnTimeout
+func NewTransportPool(maxIdleConnsPerHost int, idleConnTimeout time.Duration) *TransportPool {
+	return &TransportPool{
+		maxIdleConnsPerHost: maxIdleConnsPerHost,
+		idleConnTimeout:     idleConnTimeout,
+		transports:          map[TransportKey]*http.Transport{},
+	}
+}
+
+// Get returns the transport of the key, creating it with the given TLS configuration if needed
+func (p *TransportPool) Get(key TransportKey, tlsConfig *tls.Config) *http.Transport {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if t, ok := p.transports[key]; ok {
+		return t
+	}
+	if len(p.order) >= maxPooledTransports {
+		oldest := p.order[0]
+		p.transports[oldest].CloseIdleConnections()
+		delete(p.transports, oldest)
+		p.order = p.order[1:]
+	}
+	t := &http.Transport{
+		Proxy:               http.ProxyFromEnvironment,
+		DialContext:         (&net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}).DialContext,
+		TLSClientConfig:     tlsConfig,
+		TLSHandshakeTimeout: 10 * time.Second,
+		MaxIdleConns:        p.maxIdleConnsPerHost,
+		MaxIdleConnsPerHost: p.maxIdleConnsPerHost,
+		IdleConnTimeout:     p.idleConnTimeout,
+		ForceAttemptHTTP2:   true,
+	}
+	p.transports[key] = t
+	p.order = append(p.order, key)
+	return t
+}
+
+var (
+	probeTransportsOnce sync.Once
+	probeTransports     *TransportPool
+	probeTransportsErr  error
+)
+
+// ProbeTransports returns the pool shared by connectivity probes, configured from the environment on first use.
+// It's nil when pooling is disabled.
+func ProbeTransports() (*TransportPool, error) {
+	probeTransportsOnce.Do(func() {
+		probeTransports, probeTransportsErr = transportPoolFromEnv()
+	})
+	return probeTransports, probeTransportsErr
+}
+
+func transportPoolFromEnv() (*TransportPool, error) {
+	enabled, err := util.BoolFromEnv(ProbePoolingEnv, true)
+	if err != nil || !enabled {
+		return nil, err
+	}
+	maxIdle, err := util.IntFromEnv(ProbeMaxIdleConnsPerHostEnv, defaultMaxIdleConnsPerHost)
+	if err != nil {
+		return nil, err
+	}
+	timeout, err := util.DurationFromEnv(ProbeIdleConnTimeoutEnv, defaultIdleConnTimeout)
+	if err != nil {
+		return nil, err
+	}
+	return NewTransportPool(maxIdle, timeout), nil
+}
@@ -49,7 +49,7 @@ func AllValidCRDAccounts(ctx context.Context, c client.Client, ns string) ([]acc
 
 	accounts := make([]account.Account, 0)
 	for _, a := range spinAccounts.GetItems() {
-		if !a.GetSpec().Enabled {
+		if !a.GetSpec().Enabled || failedValidation(a) {
 			continue
 		}
 		accountType, err := GetType(a.GetSpec().Type)
@@ -0,0 +1,117 @@
+package accounts
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/armory/spinnaker-operator/pkg/accounts/account"
+	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
+	"github.com/armory/spinnaker-operator/pkg/util"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+var ErrValueOutOfRange = errors.New("value out of range")
+
+// SettingBoundsEnv lists bounds of numeric account settings as name=min:max, e.g. "cacheThreads=1:16,timeoutSeconds=:600".
+// They replace the bounds declared by account types and also apply to settings of types not declaring them.
+const SettingBoundsEnv = "ACCOUNT_SETTING_BOUNDS"
+
+// BoundsFromEnv returns the bounds set in the environment
+func BoundsFromEnv() ([]account.NumericBound, error) {
+	bounds := make([]account.NumericBound, 0)
+	for _, e := range util.ListFromEnv(SettingBoundsEnv) {
+		b, err := parseBound(e)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s entry \"%s\": %w", SettingBoundsEnv, e, err)
+		}
+		bounds = append(bounds, b)
+	}
+	return bounds, nil
+}
+
+func parseBound(e string) (account.NumericBound, error) {
+	b := account.NumericBound{Min: math.Inf(-1), Max: math.Inf(1)}
+	kv := strings.SplitN(e, "=", 2)
+	if len(kv) != 2 || kv[0] == "" {
+		return b, errors.New("expected name=min:max")
+	}
+	b.Name = kv[0]
+	r := strings.SplitN(kv[1], ":", 2)
+	if len(r) != 2 || (r[0] == "" && r[1] == "") {
+		return b, errors.New("expected name=min:max, min or max can be omitted")
+	}
+	var err error
+	if r[0] != "" {
+		if b.Min, err = strconv.ParseFloat(r[0], 64); err != nil {
+			return b, fmt.Errorf("invalid min: %w", err)
+		}
+	}
+	if r[1] != "" {
+		if b.Max, err = strconv.ParseFloat(r[1], 64); err != nil {
+			return b, fmt.Errorf("invalid max: %w", err)
+		}
+	}
+	if b.Min > b.Max {
+		return b, errors.New("min is greater than max")
+	}
+	return b, nil
+}
+
+// CheckBounds returns an error listing the numeric settings of the account outside of their range. Overrides replace
+// the bounds declared by the account type.
+func CheckBounds(t account.SpinnakerAccountType, acc interfaces.SpinnakerAccount, overrides []account.NumericBound) error {
+	bounds := map[string]account.NumericBound{}
+	if p, ok := t.(account.NumericBoundsProvider); ok {
+		for _, b := range p.GetNumericBounds() {
+			bounds[b.Name] = b
+		}
+	}
+	for _, b := range overrides {
+		bounds[b.Name] = b
+	}
+	names := make([]string, 0, len(bounds))
+	for n := range bounds {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	errs := field.ErrorList{}
+	settings := acc.GetSpec().Settings
+	for _, n := range names {
+		v, ok := number(settings[n])
+		b := bounds[n]
+		if ok && (v < b.Min || v > b.Max) {
+			errs = append(errs, field.Invalid(field.NewPath("spec", "settings", n), settings[n], describeRange(b)))
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: account \"%s\": %s", ErrValueOutOfRange, acc.GetName(), errs.ToAggregate().Error())
+}
+
+func number(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int64:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+func describeRange(b account.NumericBound) string {
+	switch {
+	case math.IsInf(b.Min, -1):
+		return fmt.Sprintf("must be at most %v", b.Max)
+	case math.IsInf(b.Max, 1):
+		return fmt.Sprintf("must be at least %v", b.Min)
+	}
+	return fmt.Sprintf("must be between %v and %v", b.Min, b.Max)
+}
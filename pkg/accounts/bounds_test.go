@@ -0,0 +1,56 @@
+package accounts
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/armory/spinnaker-operator/pkg/accounts/kubernetes"
+	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
+	"github.com/armory/spinnaker-operator/pkg/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckBounds(t *testing.T) {
+	cases := []struct {
+		name     string
+		env      string
+		settings interfaces.FreeForm
+		expected string
+	}{
+		{"within range", "", interfaces.FreeForm{"cacheIntervalSeconds": float64(30), "cacheThreads": float64(4)}, ""},
+		{"out of range", "", interfaces.FreeForm{"cacheIntervalSeconds": float64(0)},
+			`value out of range: account "kube": spec.settings.cacheIntervalSeconds: Invalid value: 0: must be between 10 and 3600`},
+		{"overridden bounds", "cacheIntervalSeconds=60:", interfaces.FreeForm{"cacheIntervalSeconds": float64(30)},
+			`value out of range: account "kube": spec.settings.cacheIntervalSeconds: Invalid value: 30: must be at least 60`},
+		{"setting not declared by the type", "timeoutSeconds=:600", interfaces.FreeForm{"timeoutSeconds": float64(7200)},
+			`value out of range: account "kube": spec.settings.timeoutSeconds: Invalid value: 7200: must be at most 600`},
+		{"not a number", "", interfaces.FreeForm{"cacheThreads": "many"}, ""},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			t.Setenv(SettingBoundsEnv, c.env)
+			bounds, err := BoundsFromEnv()
+			if !assert.Nil(t, err) {
+				return
+			}
+			acc := test.TypesFactory.NewAccount()
+			acc.SetName("kube")
+			acc.GetSpec().Settings = c.settings
+			err = CheckBounds(&kubernetes.AccountType{}, acc, bounds)
+			if c.expected == "" {
+				assert.Nil(t, err)
+			} else if assert.NotNil(t, err) {
+				assert.True(t, errors.Is(err, ErrValueOutOfRange))
+				assert.Equal(t, c.expected, err.Error())
+			}
+		})
+	}
+}
+
+func TestBoundsFromEnvInvalid(t *testing.T) {
+	for _, v := range []string{"cacheThreads", "cacheThreads=:", "cacheThreads=10:1", "cacheThreads=a:1", "=1:2"} {
+		t.Setenv(SettingBoundsEnv, v)
+		_, err := BoundsFromEnv()
+		assert.NotNil(t, err, v)
+	}
+}
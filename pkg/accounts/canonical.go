@@ -0,0 +1,79 @@
+package accounts
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/armory/spinnaker-operator/pkg/accounts/account"
+	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+var ErrNonCanonicalValue = errors.New("non canonical value")
+
+// boolSpellings are the spellings of booleans YAML users commonly write as strings
+var boolSpellings = map[string]bool{"true": true, "yes": true, "on": true, "false": false, "no": false, "off": false}
+
+// CheckCanonicalValues returns an error listing the settings of the account that don't use one of their canonical
+// values. Account types not declaring canonical fields are not checked.
+func CheckCanonicalValues(t account.SpinnakerAccountType, acc interfaces.SpinnakerAccount) error {
+	p, ok := t.(account.CanonicalFieldsProvider)
+	if !ok {
+		return nil
+	}
+	errs := field.ErrorList{}
+	suggestions := make([]account.Suggestion, 0)
+	settings := acc.GetSpec().Settings
+	for _, f := range p.GetCanonicalFields() {
+		v, ok := settings[f.Name]
+		if !ok {
+			continue
+		}
+		path := field.NewPath("spec", "settings", f.Name)
+		fix, err := checkCanonicalValue(path, v, f.Allowed)
+		if err == nil {
+			continue
+		}
+		errs = append(errs, err)
+		if fix != nil {
+			suggestions = append(suggestions, account.Suggestion{Op: "replace", Path: "/spec/settings/" + f.Name, Value: fix})
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	err := fmt.Errorf("%w: account \"%s\": %s", ErrNonCanonicalValue, acc.GetName(), errs.ToAggregate().Error())
+	return account.WithSuggestions(err, suggestions...)
+}
+
+// checkCanonicalValue returns an error if v isn't one of the allowed values, or a boolean if none are, along with
+// the canonical value v was probably meant to be
+func checkCanonicalValue(path *field.Path, v interface{}, allowed []string) (interface{}, *field.Error) {
+	if len(allowed) == 0 {
+		if _, ok := v.(bool); ok {
+			return nil, nil
+		}
+		if s, ok := v.(string); ok {
+			if b, ok := boolSpellings[strings.ToLower(s)]; ok {
+				return b, field.Invalid(path, v, "expected true or false, without quotes")
+			}
+		}
+		return nil, field.Invalid(path, v, "expected true or false")
+	}
+	s, ok := v.(string)
+	if !ok {
+		return nil, field.NotSupported(path, v, allowed)
+	}
+	for _, a := range allowed {
+		if s == a {
+			return nil, nil
+		}
+	}
+	for _, a := range allowed {
+		if strings.EqualFold(s, a) {
+			return a, field.NotSupported(path, v, allowed)
+		}
+	}
+	return nil, field.NotSupported(path, v, allowed)
+}
@@ -0,0 +1,50 @@
+package accounts
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/armory/spinnaker-operator/pkg/accounts/account"
+	"github.com/armory/spinnaker-operator/pkg/accounts/kubernetes"
+	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
+	"github.com/armory/spinnaker-operator/pkg/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckCanonicalValues(t *testing.T) {
+	cases := []struct {
+		name        string
+		settings    interfaces.FreeForm
+		expected    string
+		suggestions []account.Suggestion
+	}{
+		{"canonical values", interfaces.FreeForm{"providerVersion": "V2", "onlySpinnakerManaged": true, "namespaces": []string{"dev"}}, "", nil},
+		{"unknown enum value", interfaces.FreeForm{"providerVersion": "V3"},
+			`non canonical value: account "kube": spec.settings.providerVersion: Unsupported value: "V3": supported values: "V1", "V2"`, nil},
+		{"enum value with the wrong case", interfaces.FreeForm{"providerVersion": "v2"},
+			`non canonical value: account "kube": spec.settings.providerVersion: Unsupported value: "v2": supported values: "V1", "V2"`,
+			[]account.Suggestion{{Op: "replace", Path: "/spec/settings/providerVersion", Value: "V2"}}},
+		{"quoted boolean", interfaces.FreeForm{"liveManifestCalls": "yes"},
+			`non canonical value: account "kube": spec.settings.liveManifestCalls: Invalid value: "yes": expected true or false, without quotes`,
+			[]account.Suggestion{{Op: "replace", Path: "/spec/settings/liveManifestCalls", Value: true}}},
+		{"not a boolean", interfaces.FreeForm{"serviceAccount": "enabled"},
+			`non canonical value: account "kube": spec.settings.serviceAccount: Invalid value: "enabled": expected true or false`, nil},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			acc := test.TypesFactory.NewAccount()
+			acc.SetName("kube")
+			acc.GetSpec().Settings = c.settings
+			err := CheckCanonicalValues(&kubernetes.AccountType{}, acc)
+			if c.expected == "" {
+				assert.Nil(t, err)
+				return
+			}
+			if assert.NotNil(t, err) {
+				assert.True(t, errors.Is(err, ErrNonCanonicalValue))
+				assert.Equal(t, c.expected, err.Error())
+				assert.Equal(t, c.suggestions, account.SuggestionsFrom(err))
+			}
+		})
+	}
+}
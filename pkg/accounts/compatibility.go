@@ -0,0 +1,79 @@
+package accounts
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/armory/spinnaker-operator/pkg/accounts/account"
+	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+var ErrIncompatibleVersion = errors.New("account setting not supported by Spinnaker version")
+
+// featureRequirement is an account setting only understood by Spinnaker from MinVersion onwards
+type featureRequirement struct {
+	Setting    string
+	MinVersion string
+}
+
+// compatibility lists, per account type, the settings that need a minimum Spinnaker version
+var compatibility = map[interfaces.AccountType][]featureRequirement{
+	interfaces.KubernetesAccountType: {
+		{Setting: "onlySpinnakerManaged", MinVersion: "1.17.0"},
+		{Setting: "checkPermissionsOnStartup", MinVersion: "1.21.0"},
+		{Setting: "rawResourcesEndpointConfig", MinVersion: "1.26.0"},
+		{Setting: "cacheAllApplicationRelationships", MinVersion: "1.27.0"},
+	},
+}
+
+// CheckCompatibility returns an error if the account uses settings not supported by the given Spinnaker version.
+// If the version can't be compared (e.g. a nightly build), the unverified settings are returned as warnings.
+func CheckCompatibility(a account.Account, spinnakerVersion string) ([]string, error) {
+	reqs := usedFeatures(a)
+	if len(reqs) == 0 {
+		return nil, nil
+	}
+	v, err := parseSpinnakerVersion(spinnakerVersion)
+	if err != nil {
+		warnings := make([]string, 0)
+		for _, r := range reqs {
+			warnings = append(warnings, fmt.Sprintf("unable to verify that Spinnaker version \"%s\" supports setting \"%s\" of account \"%s\", it requires %s or later", spinnakerVersion, r.Setting, a.GetName(), r.MinVersion))
+		}
+		return warnings, nil
+	}
+	for _, r := range reqs {
+		if v.LessThan(version.MustParseGeneric(r.MinVersion)) {
+			return nil, fmt.Errorf("%w: setting \"%s\" of account \"%s\" requires Spinnaker %s or later, but Spinnaker version is %s", ErrIncompatibleVersion, r.Setting, a.GetName(), r.MinVersion, spinnakerVersion)
+		}
+	}
+	return nil, nil
+}
+
+func usedFeatures(a account.Account) []featureRequirement {
+	s := a.GetSettings()
+	if s == nil {
+		return nil
+	}
+	used := make([]featureRequirement, 0)
+	for _, r := range compatibility[a.GetType()] {
+		if _, ok := (*s)[r.Setting]; ok {
+			used = append(used, r)
+		}
+	}
+	sort.Slice(used, func(i, j int) bool { return used[i].Setting < used[j].Setting })
+	return used
+}
+
+// parseSpinnakerVersion parses OSS (1.x) and Armory (2.x) versions, Armory versions mapping to OSS by minor version
+func parseSpinnakerVersion(v string) (*version.Version, error) {
+	pv, err := version.ParseGeneric(v)
+	if err != nil {
+		return nil, err
+	}
+	if pv.Major() == 2 {
+		return version.MustParseGeneric(fmt.Sprintf("1.%d.%d", pv.Minor(), pv.Patch())), nil
+	}
+	return pv, nil
+}
@@ -0,0 +1,47 @@
+package accounts
+
+import (
+	"testing"
+
+	"github.com/armory/spinnaker-operator/pkg/accounts/kubernetes"
+	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckCompatibility(t *testing.T) {
+	a := &kubernetes.Account{
+		Name: "kube",
+		Settings: interfaces.FreeForm{
+			"cacheAllApplicationRelationships": true,
+		},
+	}
+	cases := []struct {
+		name            string
+		version         string
+		errExpected     bool
+		warningExpected bool
+	}{
+		{"too old OSS version", "1.26.3", true, false},
+		{"recent OSS version", "1.27.0", false, false},
+		{"too old Armory version", "2.26.1", true, false},
+		{"recent Armory version", "2.28.0", false, false},
+		{"unparseable version", "master-latest-unvalidated", false, true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			w, err := CheckCompatibility(a, c.version)
+			assert.Equal(t, c.errExpected, err != nil)
+			assert.Equal(t, c.warningExpected, len(w) > 0)
+			if err != nil {
+				assert.Contains(t, err.Error(), "requires Spinnaker 1.27.0 or later")
+			}
+		})
+	}
+}
+
+func TestCheckCompatibilityNoVersionedSettings(t *testing.T) {
+	a := &kubernetes.Account{Name: "kube", Settings: interfaces.FreeForm{"namespaces": []string{"ns1"}}}
+	w, err := CheckCompatibility(a, "1.10.0")
+	assert.Nil(t, err)
+	assert.Empty(t, w)
+}
@@ -0,0 +1,76 @@
+package accounts
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/armory/spinnaker-operator/pkg/accounts/account"
+	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
+	"github.com/armory/spinnaker-operator/pkg/util"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+var ErrConflictingSettings = errors.New("conflicting settings")
+
+// SettingConflictsEnv lists settings that can't be enabled together as type=first+second, e.g.
+// "Kubernetes=liveManifestCalls+cacheAllApplicationRelationships". They add to the conflicts declared by account types.
+const SettingConflictsEnv = "ACCOUNT_SETTING_CONFLICTS"
+
+// SettingConflicts are the conflicting settings of account types, keyed by lower case account type
+type SettingConflicts map[string][]account.SettingConflict
+
+// SettingConflictsFromEnv returns the conflicts set in the environment
+func SettingConflictsFromEnv() (SettingConflicts, error) {
+	conflicts := SettingConflicts{}
+	for _, e := range util.ListFromEnv(SettingConflictsEnv) {
+		kv := strings.SplitN(e, "=", 2)
+		var names []string
+		if len(kv) == 2 {
+			names = strings.SplitN(kv[1], "+", 2)
+		}
+		if len(names) != 2 || kv[0] == "" || names[0] == "" || names[1] == "" || names[0] == names[1] {
+			return nil, fmt.Errorf("invalid %s entry \"%s\": expected type=first+second", SettingConflictsEnv, e)
+		}
+		tp := strings.ToLower(kv[0])
+		conflicts[tp] = append(conflicts[tp], account.SettingConflict{First: names[0], Second: names[1]})
+	}
+	return conflicts, nil
+}
+
+// CheckSettingConflicts returns an error naming the pairs of conflicting settings both enabled in the account, from
+// the conflicts declared by its type and the given ones
+func CheckSettingConflicts(t account.SpinnakerAccountType, acc interfaces.SpinnakerAccount, conflicts SettingConflicts) error {
+	pairs := conflicts[strings.ToLower(string(acc.GetSpec().Type))]
+	if p, ok := t.(account.SettingConflictsProvider); ok {
+		pairs = append(p.GetSettingConflicts(), pairs...)
+	}
+	errs := field.ErrorList{}
+	settings := acc.GetSpec().Settings
+	for _, c := range pairs {
+		if enabled(settings[c.First]) && enabled(settings[c.Second]) {
+			errs = append(errs, field.Forbidden(field.NewPath("spec", "settings", c.Second), fmt.Sprintf("can't be enabled together with spec.settings.%s", c.First)))
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: account \"%s\": %s", ErrConflictingSettings, acc.GetName(), errs.ToAggregate().Error())
+}
+
+// enabled returns true for settings that are true, or set to a non-empty value
+func enabled(v interface{}) bool {
+	switch s := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return s
+	case string:
+		return s != "" && !strings.EqualFold(s, "false")
+	case []interface{}:
+		return len(s) > 0
+	case map[string]interface{}:
+		return len(s) > 0
+	}
+	return true
+}
@@ -0,0 +1,60 @@
+package accounts
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/armory/spinnaker-operator/pkg/accounts/kubernetes"
+	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
+	"github.com/armory/spinnaker-operator/pkg/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckSettingConflicts(t *testing.T) {
+	cases := []struct {
+		name     string
+		env      string
+		settings interfaces.FreeForm
+		expected string
+	}{
+		{"no conflict", "", interfaces.FreeForm{"namespaces": []interface{}{"dev"}, "omitKinds": []interface{}{"secret"}}, ""},
+		{"conflict declared by the type", "", interfaces.FreeForm{"kinds": []interface{}{"deployment"}, "omitKinds": []interface{}{"secret"}},
+			`conflicting settings: account "kube": spec.settings.omitKinds: Forbidden: can't be enabled together with spec.settings.kinds`},
+		{"empty list", "", interfaces.FreeForm{"kinds": []interface{}{"deployment"}, "omitKinds": []interface{}{}}, ""},
+		{"configured conflict", "kubernetes=liveManifestCalls+cacheAllApplicationRelationships",
+			interfaces.FreeForm{"liveManifestCalls": true, "cacheAllApplicationRelationships": true},
+			`conflicting settings: account "kube": spec.settings.cacheAllApplicationRelationships: Forbidden: can't be enabled together with spec.settings.liveManifestCalls`},
+		{"configured conflict disabled", "Kubernetes=liveManifestCalls+cacheAllApplicationRelationships",
+			interfaces.FreeForm{"liveManifestCalls": true, "cacheAllApplicationRelationships": false}, ""},
+		{"conflict of another type", "AWS=liveManifestCalls+cacheAllApplicationRelationships",
+			interfaces.FreeForm{"liveManifestCalls": true, "cacheAllApplicationRelationships": true}, ""},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			t.Setenv(SettingConflictsEnv, c.env)
+			conflicts, err := SettingConflictsFromEnv()
+			if !assert.Nil(t, err) {
+				return
+			}
+			acc := test.TypesFactory.NewAccount()
+			acc.SetName("kube")
+			acc.GetSpec().Type = interfaces.KubernetesAccountType
+			acc.GetSpec().Settings = c.settings
+			err = CheckSettingConflicts(&kubernetes.AccountType{}, acc, conflicts)
+			if c.expected == "" {
+				assert.Nil(t, err)
+			} else if assert.NotNil(t, err) {
+				assert.True(t, errors.Is(err, ErrConflictingSettings))
+				assert.Equal(t, c.expected, err.Error())
+			}
+		})
+	}
+}
+
+func TestSettingConflictsFromEnvInvalid(t *testing.T) {
+	for _, e := range []string{"Kubernetes", "Kubernetes=a", "=a+b", "Kubernetes=a+a"} {
+		t.Setenv(SettingConflictsEnv, e)
+		_, err := SettingConflictsFromEnv()
+		assert.NotNil(t, err, e)
+	}
+}
@@ -0,0 +1,98 @@
+package accounts
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
+	"sigs.k8s.io/yaml"
+)
+
+var ErrFeatureSunset = errors.New("feature removed")
+
+// Deprecation is a setting of spec.settings scheduled for removal
+type Deprecation struct {
+	// Type is the account type using the setting, any type if empty
+	Type    string
+	Setting string
+	// Sunset is when accounts can no longer enable the setting
+	Sunset  time.Time
+	Message string
+}
+
+// ParseDeprecations reads a deprecation schedule, keyed by setting or type.setting, each entry holding a sunset date
+// (YYYY-MM-DD or RFC3339) and an optional message, e.g.
+//
+//	Kubernetes.cacheAllApplicationRelationships: |
+//	  sunset: 2023-06-30
+//	  message: relationships are now cached on demand
+func ParseDeprecations(data map[string]string) ([]Deprecation, error) {
+	deps := make([]Deprecation, 0, len(data))
+	for k, v := range data {
+		var e struct {
+			Sunset  string `json:"sunset"`
+			Message string `json:"message"`
+		}
+		if err := yaml.Unmarshal([]byte(v), &e); err != nil {
+			return nil, fmt.Errorf("invalid deprecation %s: %w", k, err)
+		}
+		sunset, err := parseSunset(e.Sunset)
+		if err != nil {
+			return nil, fmt.Errorf("invalid deprecation %s: %w", k, err)
+		}
+		d := Deprecation{Setting: k, Sunset: sunset, Message: e.Message}
+		if i := strings.Index(k, "."); i >= 0 {
+			d.Type, d.Setting = k[:i], k[i+1:]
+		}
+		deps = append(deps, d)
+	}
+	sort.Slice(deps, func(i, j int) bool { return deps[i].Setting < deps[j].Setting })
+	return deps, nil
+}
+
+func parseSunset(s string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return t, fmt.Errorf("expected a sunset date as YYYY-MM-DD or RFC3339, got \"%s\"", s)
+	}
+	return t, nil
+}
+
+// CheckDeprecations returns a warning for each deprecated setting the account enables before its sunset, and an
+// error listing the ones enabled from their sunset on
+func CheckDeprecations(acc interfaces.SpinnakerAccount, deps []Deprecation, now time.Time) ([]string, error) {
+	warnings := make([]string, 0)
+	removed := make([]string, 0)
+	for _, d := range deps {
+		if d.Type != "" && !strings.EqualFold(d.Type, string(acc.GetSpec().Type)) {
+			continue
+		}
+		if !enabled(acc.GetSpec().Settings[d.Setting]) {
+			continue
+		}
+		msg := d.Setting
+		if !now.Before(d.Sunset) {
+			msg += fmt.Sprintf(" was removed on %s", d.Sunset.Format("2006-01-02"))
+		} else {
+			msg += fmt.Sprintf(" is deprecated and will be removed on %s", d.Sunset.Format("2006-01-02"))
+		}
+		if d.Message != "" {
+			msg += ": " + d.Message
+		}
+		if now.Before(d.Sunset) {
+			warnings = append(warnings, fmt.Sprintf("account %s uses setting %s", acc.GetName(), msg))
+		} else {
+			removed = append(removed, msg)
+		}
+	}
+	if len(removed) > 0 {
+		return warnings, fmt.Errorf("%w: account %s uses settings past their sunset: %s", ErrFeatureSunset, acc.GetName(), strings.Join(removed, "; "))
+	}
+	return warnings, nil
+}
@@ -0,0 +1,58 @@
+package accounts
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
+	"github.com/armory/spinnaker-operator/pkg/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckDeprecations(t *testing.T) {
+	deps, err := ParseDeprecations(map[string]string{
+		"Kubernetes.cacheAllApplicationRelationships": "sunset: 2023-06-30\nmessage: relationships are cached on demand",
+		"AWS.liveManifestCalls":                       "sunset: 2020-01-01",
+	})
+	if !assert.Nil(t, err) {
+		return
+	}
+	acc := test.TypesFactory.NewAccount()
+	acc.SetName("kube")
+	acc.GetSpec().Type = interfaces.KubernetesAccountType
+	acc.GetSpec().Settings = interfaces.FreeForm{"cacheAllApplicationRelationships": true, "liveManifestCalls": true}
+	sunset := time.Date(2023, 6, 30, 0, 0, 0, 0, time.UTC)
+
+	t.Run("before sunset", func(t *testing.T) {
+		w, err := CheckDeprecations(acc, deps, sunset.Add(-time.Hour))
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"account kube uses setting cacheAllApplicationRelationships is deprecated and will be removed on 2023-06-30: relationships are cached on demand"}, w)
+	})
+
+	t.Run("at sunset", func(t *testing.T) {
+		_, err := CheckDeprecations(acc, deps, sunset)
+		if assert.NotNil(t, err) {
+			assert.True(t, errors.Is(err, ErrFeatureSunset))
+			assert.Equal(t, "feature removed: account kube uses settings past their sunset: cacheAllApplicationRelationships was removed on 2023-06-30: relationships are cached on demand", err.Error())
+		}
+	})
+
+	t.Run("after sunset", func(t *testing.T) {
+		_, err := CheckDeprecations(acc, deps, sunset.AddDate(1, 0, 0))
+		assert.True(t, errors.Is(err, ErrFeatureSunset))
+	})
+
+	t.Run("setting disabled", func(t *testing.T) {
+		disabled := acc.DeepCopyObject().(interfaces.SpinnakerAccount)
+		disabled.GetSpec().Settings = interfaces.FreeForm{"cacheAllApplicationRelationships": false}
+		w, err := CheckDeprecations(disabled, deps, sunset)
+		assert.Nil(t, err)
+		assert.Empty(t, w)
+	})
+}
+
+func TestParseDeprecationsInvalid(t *testing.T) {
+	_, err := ParseDeprecations(map[string]string{"cacheThreads": "sunset: soon"})
+	assert.EqualError(t, err, `invalid deprecation cacheThreads: expected a sunset date as YYYY-MM-DD or RFC3339, got "soon"`)
+}
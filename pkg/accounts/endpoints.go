@@ -0,0 +1,67 @@
+package accounts
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/armory/spinnaker-operator/pkg/accounts/account"
+)
+
+var ErrInvalidEndpoint = errors.New("invalid endpoint")
+
+// ValidateEndpoints checks that the endpoints declared by the account are absolute URLs with a scheme the
+// provider accepts. Accounts not declaring endpoints are not checked.
+func ValidateEndpoints(a account.Account) error {
+	ep, ok := a.(account.EndpointProvider)
+	if !ok {
+		return nil
+	}
+	for _, e := range ep.GetEndpoints() {
+		if err := ValidateURL(e.Field, e.URL, e.Schemes); err != nil {
+			err = fmt.Errorf("account \"%s\": %w", a.GetName(), err)
+			if fixed, ok := suggestURL(e.URL, e.Schemes); ok && e.Path != "" {
+				err = account.WithSuggestions(err, account.Suggestion{Op: "replace", Path: e.Path, Value: fixed})
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateURL checks that the value of the given field is an absolute URL using one of the given schemes
+func ValidateURL(field, raw string, schemes []string) error {
+	if strings.TrimSpace(raw) != raw || strings.ContainsAny(raw, " \t\n") {
+		return fmt.Errorf("%w %s \"%s\": URL must not contain whitespace", ErrInvalidEndpoint, field, raw)
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("%w %s \"%s\": %v", ErrInvalidEndpoint, field, raw, err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("%w %s \"%s\": expected an absolute URL such as %s://host:port", ErrInvalidEndpoint, field, raw, schemes[0])
+	}
+	for _, s := range schemes {
+		if strings.EqualFold(u.Scheme, s) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w %s \"%s\": scheme must be one of %s", ErrInvalidEndpoint, field, raw, strings.Join(schemes, ", "))
+}
+
+// suggestURL returns the URL with the first accepted scheme when it has none or an unaccepted one
+func suggestURL(raw string, schemes []string) (string, bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" || strings.ContainsAny(raw, " \t\n") {
+		return "", false
+	}
+	if i := strings.Index(raw, "://"); i >= 0 {
+		raw = raw[i+3:]
+	}
+	fixed := schemes[0] + "://" + raw
+	if ValidateURL("", fixed, schemes) != nil {
+		return "", false
+	}
+	return fixed, true
+}
@@ -0,0 +1,63 @@
+package accounts
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/armory/spinnaker-operator/pkg/accounts/kubernetes"
+	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
+	"github.com/stretchr/testify/assert"
+	clientcmdv1 "k8s.io/client-go/tools/clientcmd/api/v1"
+)
+
+func TestValidateURL(t *testing.T) {
+	schemes := []string{"https", "http"}
+	cases := []struct {
+		url         string
+		errExpected string
+	}{
+		{"https://mycluster.com", ""},
+		{"https://mycluster.com:6443/", ""},
+		{"HTTP://MyCluster.com/path", ""},
+		{"https://10.0.0.1:6443", ""},
+		{"mycluster.com", "expected an absolute URL"},
+		{"mycluster.com:6443", "expected an absolute URL"},
+		{"ftp://mycluster.com", "scheme must be one of https, http"},
+		{"https://", "expected an absolute URL"},
+		{"https://my cluster.com", "must not contain whitespace"},
+		{" https://mycluster.com", "must not contain whitespace"},
+		{"https://mycluster.com:port", "invalid port"},
+		{"https://[::1", "missing ']'"},
+	}
+	for _, c := range cases {
+		t.Run(c.url, func(t *testing.T) {
+			err := ValidateURL("server", c.url, schemes)
+			if c.errExpected == "" {
+				assert.Nil(t, err)
+				return
+			}
+			if assert.NotNil(t, err) {
+				assert.True(t, errors.Is(err, ErrInvalidEndpoint))
+				assert.Contains(t, err.Error(), c.errExpected)
+			}
+		})
+	}
+}
+
+func TestValidateEndpoints(t *testing.T) {
+	a := &kubernetes.Account{
+		Name: "kube",
+		Auth: &interfaces.KubernetesAuth{Kubeconfig: &clientcmdv1.Config{
+			Clusters: []clientcmdv1.NamedCluster{
+				{Name: "good", Cluster: clientcmdv1.Cluster{Server: "https://mycluster.com"}},
+				{Name: "bad", Cluster: clientcmdv1.Cluster{Server: "mycluster.com"}},
+			},
+		}},
+	}
+	err := ValidateEndpoints(a)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, `account "kube": invalid endpoint spec.kubernetes.kubeconfig.clusters[bad].cluster.server "mycluster.com": expected an absolute URL such as https://host:port`, err.Error())
+	}
+	a.Auth.Kubeconfig.Clusters = a.Auth.Kubeconfig.Clusters[:1]
+	assert.Nil(t, ValidateEndpoints(a))
+}
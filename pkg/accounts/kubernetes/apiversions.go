@@ -0,0 +1,44 @@
+package kubernetes
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/armory/spinnaker-operator/pkg/accounts/account"
+	"github.com/armory/spinnaker-operator/pkg/inspect"
+	"k8s.io/client-go/kubernetes"
+)
+
+// apiVersionsSetting lists the API versions, as group/version or v1 for the core group, the account manages
+// resources with instead of the versions preferred by the cluster
+const apiVersionsSetting = "apiVersions"
+
+// validateAPIVersions warns about the API versions pinned by the account that the cluster doesn't serve
+func (k *kubernetesAccountValidator) validateAPIVersions(ctx context.Context, clientset kubernetes.Interface) {
+	pinned, err := inspect.GetStringArray(k.account.Settings, apiVersionsSetting)
+	if err != nil || len(pinned) == 0 {
+		return
+	}
+	groups, err := clientset.Discovery().ServerGroups()
+	if err != nil {
+		account.Warn(ctx, "unable to check the API versions of account \"%s\" are served: %v", k.account.Name, err)
+		return
+	}
+	served := map[string]bool{}
+	for _, g := range groups.Groups {
+		for _, v := range g.Versions {
+			served[v.GroupVersion] = true
+		}
+	}
+	unsupported := make([]string, 0)
+	for _, v := range pinned {
+		if !served[v] {
+			unsupported = append(unsupported, v)
+		}
+	}
+	if len(unsupported) > 0 {
+		sort.Strings(unsupported)
+		account.Warn(ctx, "account \"%s\" pins API versions the cluster doesn't serve: %s", k.account.Name, strings.Join(unsupported, ", "))
+	}
+}
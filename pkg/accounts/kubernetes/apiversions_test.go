@@ -0,0 +1,40 @@
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/armory/spinnaker-operator/pkg/accounts/account"
+	"github.com/stretchr/testify/assert"
+	v13 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestValidateAPIVersions(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	clientset.Resources = []*v13.APIResourceList{
+		{GroupVersion: "v1"},
+		{GroupVersion: "apps/v1"},
+		{GroupVersion: "networking.k8s.io/v1"},
+	}
+
+	cases := []struct {
+		name     string
+		settings map[string]interface{}
+		expected []string
+	}{
+		{"no pinned version", map[string]interface{}{}, []string{}},
+		{"served versions", map[string]interface{}{"apiVersions": []string{"v1", "apps/v1"}}, []string{}},
+		{"unserved version", map[string]interface{}{"apiVersions": []string{"networking.k8s.io/v1beta1", "apps/v1", "batch/v1beta1"}},
+			[]string{`account "test" pins API versions the cluster doesn't serve: batch/v1beta1, networking.k8s.io/v1beta1`}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			v := &kubernetesAccountValidator{account: &Account{Name: "test", Settings: c.settings}}
+			ctx := account.NewValidationContext(context.TODO(), account.ValidationOptions{})
+			v.validateAPIVersions(ctx, clientset)
+			vc, _ := account.ValidationContextFrom(ctx)
+			assert.Equal(t, c.expected, append([]string{}, vc.Warnings()...))
+		})
+	}
+}
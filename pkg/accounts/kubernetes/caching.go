@@ -0,0 +1,104 @@
+package kubernetes
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/armory/spinnaker-operator/pkg/accounts/account"
+	"github.com/armory/spinnaker-operator/pkg/inspect"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	v13 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// CachingAgentPermissionsEnv enables reviewing the permissions of the caching agents of each kind the account caches.
+// It issues a few access reviews per kind, so it is disabled by default.
+const CachingAgentPermissionsEnv = "KUBERNETES_CACHING_AGENT_PERMISSION_CHECK"
+
+// cachingVerbs are the verbs Clouddriver's caching agents use
+var cachingVerbs = []string{"list", "get"}
+
+// cachingAgentResources maps the kinds cached by Clouddriver, in lowercase, to their resource
+var cachingAgentResources = map[string]authorizationv1.ResourceAttributes{
+	"configmap":               {Resource: "configmaps"},
+	"cronjob":                 {Group: "batch", Resource: "cronjobs"},
+	"daemonset":               {Group: "apps", Resource: "daemonsets"},
+	"deployment":              {Group: "apps", Resource: "deployments"},
+	"horizontalpodautoscaler": {Group: "autoscaling", Resource: "horizontalpodautoscalers"},
+	"ingress":                 {Group: "networking.k8s.io", Resource: "ingresses"},
+	"job":                     {Group: "batch", Resource: "jobs"},
+	"namespace":               {Resource: "namespaces"},
+	"networkpolicy":           {Group: "networking.k8s.io", Resource: "networkpolicies"},
+	"persistentvolumeclaim":   {Resource: "persistentvolumeclaims"},
+	"pod":                     {Resource: "pods"},
+	"replicaset":              {Group: "apps", Resource: "replicasets"},
+	"secret":                  {Resource: "secrets"},
+	"service":                 {Resource: "services"},
+	"statefulset":             {Group: "apps", Resource: "statefulsets"},
+}
+
+// clusterScopedKinds are always reviewed cluster wide
+var clusterScopedKinds = map[string]bool{"namespace": true}
+
+// cachedKinds returns the sorted kinds cached for the account: the configured kinds or all kinds not omitted
+func (k *kubernetesAccountValidator) cachedKinds() []string {
+	kinds := make([]string, 0)
+	if len(k.account.Env.Kinds) > 0 {
+		for _, kind := range k.account.Env.Kinds {
+			if _, ok := cachingAgentResources[strings.ToLower(kind)]; ok {
+				kinds = append(kinds, strings.ToLower(kind))
+			}
+		}
+	} else {
+		omitted := map[string]bool{}
+		for _, kind := range k.account.Env.OmitKinds {
+			omitted[strings.ToLower(kind)] = true
+		}
+		for kind := range cachingAgentResources {
+			if !omitted[kind] {
+				kinds = append(kinds, kind)
+			}
+		}
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// validateCachingPermissions reviews the permissions the caching agents need for each cached kind and warns about
+// the kinds that can't be cached. Permissions are checked in the first configured namespace, or cluster wide.
+func (k *kubernetesAccountValidator) validateCachingPermissions(ctx context.Context, clientset kubernetes.Interface) {
+	ns := k.permissionNamespace()
+	for _, kind := range k.cachedKinds() {
+		missing := make([]string, 0)
+		for _, verb := range cachingVerbs {
+			attrs := cachingAgentResources[kind]
+			attrs.Verb = verb
+			if !clusterScopedKinds[kind] {
+				attrs.Namespace = ns
+			}
+			review := &authorizationv1.SelfSubjectAccessReview{
+				Spec: authorizationv1.SelfSubjectAccessReviewSpec{ResourceAttributes: &attrs},
+			}
+			res, err := clientset.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review, v13.CreateOptions{})
+			if err != nil {
+				account.Warn(ctx, "unable to verify caching permissions of account \"%s\": %v", k.account.Name, err)
+				return
+			}
+			if !res.Status.Allowed {
+				missing = append(missing, describePermission(attrs))
+			}
+		}
+		if len(missing) > 0 {
+			account.Warn(ctx, "account \"%s\" cannot cache kind %s, it is not allowed to %s", k.account.Name, kind, strings.Join(missing, ", "))
+		}
+	}
+}
+
+// permissionNamespace returns the first namespace configured for the account, or "" for cluster wide reviews
+func (k *kubernetesAccountValidator) permissionNamespace() string {
+	if nss, err := inspect.GetStringArray(k.account.Settings, "namespaces"); err == nil && len(nss) > 0 {
+		return nss[0]
+	}
+	return ""
+}
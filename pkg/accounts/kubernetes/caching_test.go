@@ -0,0 +1,44 @@
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/armory/spinnaker-operator/pkg/accounts/account"
+	"github.com/stretchr/testify/assert"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestCachedKinds(t *testing.T) {
+	v := &kubernetesAccountValidator{account: &Account{Env: Env{Kinds: []string{"deployment", "replicaSet", "unknownKind"}}}}
+	assert.Equal(t, []string{"deployment", "replicaset"}, v.cachedKinds())
+
+	v = &kubernetesAccountValidator{account: &Account{Env: Env{OmitKinds: []string{"secret"}}}}
+	assert.NotContains(t, v.cachedKinds(), "secret")
+	assert.Len(t, v.cachedKinds(), len(cachingAgentResources)-1)
+}
+
+func TestValidateCachingPermissions(t *testing.T) {
+	reviews := 0
+	clientset := fake.NewSimpleClientset()
+	clientset.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		reviews++
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		attrs := review.Spec.ResourceAttributes
+		review.Status.Allowed = !(attrs.Verb == "list" && attrs.Resource == "replicasets")
+		return true, review, nil
+	})
+	v := &kubernetesAccountValidator{account: &Account{
+		Name:     "test",
+		Env:      Env{Kinds: []string{"deployment", "replicaSet", "namespace"}},
+		Settings: map[string]interface{}{"namespaces": []string{"ns1"}},
+	}}
+	ctx := account.NewValidationContext(context.TODO(), account.ValidationOptions{Connectivity: true})
+	v.validateCachingPermissions(ctx, clientset)
+	vc, _ := account.ValidationContextFrom(ctx)
+	assert.Equal(t, []string{`account "test" cannot cache kind replicaset, it is not allowed to list replicasets.apps in namespace "ns1"`}, vc.Warnings())
+	assert.Equal(t, 6, reviews)
+}
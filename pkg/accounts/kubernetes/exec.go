@@ -0,0 +1,47 @@
+package kubernetes
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/armory/spinnaker-operator/pkg/util"
+	"k8s.io/client-go/rest"
+)
+
+// ExecPluginAllowlistEnv lists the commands kubeconfigs may run as exec credential plugins
+const ExecPluginAllowlistEnv = "KUBERNETES_EXEC_PLUGIN_ALLOWLIST"
+
+// ErrExecPluginNotAllowed is returned when a kubeconfig runs a command that isn't on the allowlist
+var ErrExecPluginNotAllowed = errors.New("credential plugin not allowed")
+
+// validateExecPlugins checks the commands run by exec credential plugins and auth providers (cmd-path) are on
+// the allowlist. They would run in clouddriver and in the operator when validating the account, no command is
+// allowed by default.
+func (k *kubernetesAccountValidator) validateExecPlugins(config *rest.Config) error {
+	commands := make([]string, 0)
+	if config.ExecProvider != nil {
+		commands = append(commands, config.ExecProvider.Command)
+	}
+	if config.AuthProvider != nil && config.AuthProvider.Config["cmd-path"] != "" {
+		commands = append(commands, config.AuthProvider.Config["cmd-path"])
+	}
+	if len(commands) == 0 {
+		return nil
+	}
+	allowed := util.ListFromEnv(ExecPluginAllowlistEnv)
+	for _, c := range commands {
+		if !contains(allowed, c) {
+			return fmt.Errorf("%w: account %s runs command \"%s\", add it to %s to allow it", ErrExecPluginNotAllowed, k.account.Name, c, ExecPluginAllowlistEnv)
+		}
+	}
+	return nil
+}
+
+func contains(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
@@ -2,6 +2,7 @@ package kubernetes
 
 import (
 	"errors"
+	"fmt"
 	"github.com/armory/spinnaker-operator/pkg/accounts/account"
 	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
 	"strings"
@@ -41,6 +42,40 @@ func (k *AccountType) GetPrimaryAccountsKey() string {
 	return "providers.kubernetes.primaryAccount"
 }
 
+// GetCanonicalFields returns the Clouddriver settings of Kubernetes accounts restricted to canonical values
+func (k *AccountType) GetCanonicalFields() []account.CanonicalField {
+	return []account.CanonicalField{
+		{Name: "providerVersion", Allowed: []string{"V1", "V2"}},
+		{Name: "serviceAccount"},
+		{Name: "onlySpinnakerManaged"},
+		{Name: "checkPermissionsOnStartup"},
+		{Name: "liveManifestCalls"},
+		{Name: "cacheAllApplicationRelationships"},
+	}
+}
+
+// GetNumericBounds returns the range of the Clouddriver settings of Kubernetes accounts degrading caching when
+// set to extreme values
+func (k *AccountType) GetNumericBounds() []account.NumericBound {
+	return []account.NumericBound{
+		{Name: "cacheThreads", Min: 1, Max: 100},
+		{Name: "cacheIntervalSeconds", Min: 10, Max: 3600},
+	}
+}
+
+// GetSettingConflicts returns the settings Clouddriver refuses to load together, as they select the same resources
+// in opposite ways. Namespaces and omitNamespaces are checked by the validator.
+func (k *AccountType) GetSettingConflicts() []account.SettingConflict {
+	return []account.SettingConflict{
+		{First: "kinds", Second: "omitKinds"},
+	}
+}
+
+// GetLabelSelectorSettings returns the settings filtering the namespaces and resources cached by label
+func (k *AccountType) GetLabelSelectorSettings() []string {
+	return []string{"namespaceLabelSelector", "labelSelector"}
+}
+
 func (k *AccountType) newAccount() *Account {
 	return &Account{
 		Env: Env{},
@@ -101,3 +136,20 @@ func (k *Account) validateFormat() error {
 func (k *Account) NewValidator() account.AccountValidator {
 	return &kubernetesAccountValidator{account: k}
 }
+
+// GetEndpoints returns the servers of the inlined kubeconfig
+func (k *Account) GetEndpoints() []account.Endpoint {
+	eps := make([]account.Endpoint, 0)
+	if k.Auth == nil || k.Auth.Kubeconfig == nil {
+		return eps
+	}
+	for i, c := range k.Auth.Kubeconfig.Clusters {
+		eps = append(eps, account.Endpoint{
+			Field:   fmt.Sprintf("spec.kubernetes.kubeconfig.clusters[%s].cluster.server", c.Name),
+			Path:    fmt.Sprintf("/spec/kubernetes/kubeconfig/clusters/%d/cluster/server", i),
+			URL:     c.Cluster.Server,
+			Schemes: []string{"https", "http"},
+		})
+	}
+	return eps
+}
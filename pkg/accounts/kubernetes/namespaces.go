@@ -0,0 +1,134 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/armory/spinnaker-operator/pkg/accounts/account"
+	"github.com/armory/spinnaker-operator/pkg/inspect"
+	v13 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// managedBySelector selects the resources deployed by Spinnaker
+const managedBySelector = "app.kubernetes.io/managed-by=spinnaker"
+
+// ErrNamespaceInUse is returned in strict mode when an update removes namespaces hosting resources deployed by Spinnaker
+var ErrNamespaceInUse = errors.New("namespace in use")
+
+// removedNamespaces returns the sorted namespaces the previous account could deploy to that the account can't anymore.
+// Namespaces no longer reachable because the account switched from all namespaces to a list aren't reported.
+func removedNamespaces(prev, cur *Account) []string {
+	removed := map[string]bool{}
+	prevNss, _ := inspect.GetStringArray(prev.Settings, "namespaces")
+	curNss, _ := inspect.GetStringArray(cur.Settings, "namespaces")
+	if len(curNss) > 0 {
+		for _, ns := range prevNss {
+			if !contains(curNss, ns) {
+				removed[ns] = true
+			}
+		}
+	}
+	prevOmit, _ := inspect.GetStringArray(prev.Settings, "omitNamespaces")
+	curOmit, _ := inspect.GetStringArray(cur.Settings, "omitNamespaces")
+	for _, ns := range curOmit {
+		if !contains(prevOmit, ns) {
+			removed[ns] = true
+		}
+	}
+	l := make([]string, 0, len(removed))
+	for ns := range removed {
+		l = append(l, ns)
+	}
+	sort.Strings(l)
+	return l
+}
+
+// validateRemovedNamespaces warns about namespaces removed by an update that still host resources deployed by
+// Spinnaker, which would be orphaned. The update is denied in strict mode.
+func (k *kubernetesAccountValidator) validateRemovedNamespaces(ctx context.Context, clientset kubernetes.Interface) error {
+	a, ok := account.PreviousFrom(ctx)
+	if !ok {
+		return nil
+	}
+	prev, ok := a.(*Account)
+	if !ok {
+		return nil
+	}
+	inUse := make([]string, 0)
+	for _, ns := range removedNamespaces(prev, k.account) {
+		used, err := namespaceInUse(ctx, clientset, ns)
+		if err != nil {
+			account.Warn(ctx, "unable to check if namespace \"%s\" removed from account \"%s\" is in use: %v", ns, k.account.Name, err)
+			continue
+		}
+		if used {
+			inUse = append(inUse, ns)
+		}
+	}
+	if len(inUse) == 0 {
+		return nil
+	}
+	msg := fmt.Sprintf("account \"%s\" no longer deploys to namespaces %s which host resources deployed by Spinnaker, they would be orphaned",
+		k.account.Name, strings.Join(inUse, ", "))
+	if account.Strict(ctx) {
+		return fmt.Errorf("%w: %s", ErrNamespaceInUse, msg)
+	}
+	account.Warn(ctx, msg)
+	return nil
+}
+
+// namespaceInUse returns true if the namespace has workloads, services or config maps deployed by Spinnaker
+func namespaceInUse(ctx context.Context, clientset kubernetes.Interface, ns string) (bool, error) {
+	opts := v13.ListOptions{LabelSelector: managedBySelector, Limit: 1}
+	lists := []func() (int, error){
+		func() (int, error) {
+			l, err := clientset.AppsV1().Deployments(ns).List(ctx, opts)
+			if err != nil {
+				return 0, err
+			}
+			return len(l.Items), nil
+		},
+		func() (int, error) {
+			l, err := clientset.AppsV1().StatefulSets(ns).List(ctx, opts)
+			if err != nil {
+				return 0, err
+			}
+			return len(l.Items), nil
+		},
+		func() (int, error) {
+			l, err := clientset.AppsV1().DaemonSets(ns).List(ctx, opts)
+			if err != nil {
+				return 0, err
+			}
+			return len(l.Items), nil
+		},
+		func() (int, error) {
+			l, err := clientset.CoreV1().Services(ns).List(ctx, opts)
+			if err != nil {
+				return 0, err
+			}
+			return len(l.Items), nil
+		},
+		func() (int, error) {
+			l, err := clientset.CoreV1().ConfigMaps(ns).List(ctx, opts)
+			if err != nil {
+				return 0, err
+			}
+			return len(l.Items), nil
+		},
+	}
+	for _, list := range lists {
+		n, err := list()
+		if err != nil {
+			return false, err
+		}
+		if n > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
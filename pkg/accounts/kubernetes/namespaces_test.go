@@ -0,0 +1,55 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/armory/spinnaker-operator/pkg/accounts/account"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	v13 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRemovedNamespaces(t *testing.T) {
+	prev := &Account{Settings: map[string]interface{}{"namespaces": []string{"ns1", "ns2", "ns3"}, "omitNamespaces": []string{"kube-system"}}}
+	cur := &Account{Settings: map[string]interface{}{"namespaces": []string{"ns2"}, "omitNamespaces": []string{"kube-system", "ns4"}}}
+	assert.Equal(t, []string{"ns1", "ns3", "ns4"}, removedNamespaces(prev, cur))
+
+	// All namespaces can be deployed to when none are listed
+	assert.Empty(t, removedNamespaces(prev, &Account{}))
+}
+
+func TestValidateRemovedNamespaces(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&appsv1.Deployment{
+		ObjectMeta: v13.ObjectMeta{
+			Name:      "app",
+			Namespace: "used",
+			Labels:    map[string]string{"app.kubernetes.io/managed-by": "spinnaker"},
+		},
+	}, &appsv1.Deployment{
+		ObjectMeta: v13.ObjectMeta{Name: "unmanaged", Namespace: "empty"},
+	})
+	prev := &Account{Name: "test", Settings: map[string]interface{}{"namespaces": []string{"used", "empty", "kept"}}}
+
+	t.Run("removed namespace in use", func(t *testing.T) {
+		v := &kubernetesAccountValidator{account: &Account{Name: "test", Settings: map[string]interface{}{"namespaces": []string{"kept"}}}}
+		ctx := account.NewValidationContext(context.TODO(), account.ValidationOptions{Previous: prev})
+		assert.Nil(t, v.validateRemovedNamespaces(ctx, clientset))
+		vc, _ := account.ValidationContextFrom(ctx)
+		assert.Equal(t, []string{`account "test" no longer deploys to namespaces used which host resources deployed by Spinnaker, they would be orphaned`}, vc.Warnings())
+
+		ctx = account.NewValidationContext(context.TODO(), account.ValidationOptions{Strict: true, Previous: prev})
+		err := v.validateRemovedNamespaces(ctx, clientset)
+		assert.True(t, errors.Is(err, ErrNamespaceInUse))
+	})
+
+	t.Run("removed namespace empty", func(t *testing.T) {
+		v := &kubernetesAccountValidator{account: &Account{Name: "test", Settings: map[string]interface{}{"namespaces": []string{"used", "kept"}}}}
+		ctx := account.NewValidationContext(context.TODO(), account.ValidationOptions{Strict: true, Previous: prev})
+		assert.Nil(t, v.validateRemovedNamespaces(ctx, clientset))
+		vc, _ := account.ValidationContextFrom(ctx)
+		assert.Empty(t, vc.Warnings())
+	})
+}
@@ -9,7 +9,6 @@ import (
 	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
 	"github.com/armory/spinnaker-operator/pkg/inspect"
 	"github.com/armory/spinnaker-operator/pkg/secrets"
-	"github.com/armory/spinnaker-operator/pkg/util"
 	"github.com/ghodss/yaml"
 	v1 "k8s.io/client-go/tools/clientcmd/api/v1"
 	yamlk8s "sigs.k8s.io/yaml"
@@ -125,11 +124,7 @@ func (k *Account) kubeAuthToSpinnakerSettings(ctx context.Context, settings map[
 		return nil
 	}
 	if k.Auth.KubeconfigSecret != nil {
-		sc, err := secrets.FromContextWithError(ctx)
-		if err != nil {
-			return err
-		}
-		config, err := util.GetSecretContent(sc.RestConfig, sc.Namespace, k.Auth.KubeconfigSecret.Name, k.Auth.KubeconfigSecret.Key)
+		config, err := secrets.GetKubernetesSecret(ctx, k.Auth.KubeconfigSecret.Name, k.Auth.KubeconfigSecret.Key)
 		if err != nil {
 			return err
 		}
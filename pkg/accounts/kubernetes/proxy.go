@@ -0,0 +1,33 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/armory/spinnaker-operator/pkg/accounts/account"
+	"k8s.io/client-go/rest"
+)
+
+// validateProxy checks the proxy of the account's kubeconfig is reachable, so that a failing proxy isn't reported
+// as an unreachable cluster
+func (k *kubernetesAccountValidator) validateProxy(ctx context.Context, cc *rest.Config) error {
+	if cc.Proxy == nil {
+		return nil
+	}
+	req, err := http.NewRequest(http.MethodGet, serverURL(cc.Host), nil)
+	if err != nil {
+		return fmt.Errorf("error parsing server url \"%s\" in account \"%s\":\n  %w", cc.Host, k.account.Name, err)
+	}
+	u, err := cc.Proxy(req)
+	if err != nil {
+		return fmt.Errorf("error determining the proxy of account \"%s\":\n  %w", k.account.Name, err)
+	}
+	if u == nil {
+		return nil
+	}
+	if err := account.CheckProxyReachable(ctx, u); err != nil {
+		return fmt.Errorf("error connecting to account \"%s\":\n  %w", k.account.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/armory/spinnaker-operator/pkg/accounts/account"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// closedAddress returns an address nothing listens on
+func closedAddress(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+	return addr
+}
+
+func TestValidateProxy(t *testing.T) {
+	// proxy answers requests for any host as the cluster would
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"kind":"NamespaceList","apiVersion":"v1","metadata":{},"items":[]}`)
+	}))
+	defer proxy.Close()
+
+	validate := func(cc *rest.Config) error {
+		v := &kubernetesAccountValidator{account: &Account{Name: "test"}}
+		if err := v.validateProxy(context.TODO(), cc); err != nil {
+			return err
+		}
+		clientset, err := kubernetes.NewForConfig(cc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v.validateAccess(context.TODO(), clientset)
+	}
+	proxyURL, _ := url.Parse(proxy.URL)
+	unreachableURL, _ := url.Parse("http://" + closedAddress(t))
+
+	cases := []struct {
+		name     string
+		config   *rest.Config
+		expected error
+	}{
+		{"reachable proxy", &rest.Config{Host: "http://cluster.internal", Proxy: http.ProxyURL(proxyURL)}, nil},
+		{"unreachable proxy", &rest.Config{Host: "http://cluster.internal", Proxy: http.ProxyURL(unreachableURL)}, account.ErrProxyUnreachable},
+		{"unreachable endpoint", &rest.Config{Host: "http://" + closedAddress(t)}, account.ErrEndpointUnreachable},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := validate(c.config)
+			if c.expected == nil {
+				assert.Nil(t, err)
+			} else if assert.NotNil(t, err) {
+				assert.True(t, errors.Is(err, c.expected), err.Error())
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package kubernetes
+
+import (
+	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// CheckRequiredFields checks the account sets one way to authenticate with the cluster
+func (k *AccountType) CheckRequiredFields(account interfaces.SpinnakerAccount) field.ErrorList {
+	errs := field.ErrorList{}
+	p := field.NewPath("spec", "kubernetes")
+	auth := account.GetSpec().Kubernetes
+	if auth == nil {
+		return append(errs, field.Required(p, "Kubernetes accounts need a kubeconfigFile, kubeconfigSecret, kubeconfig or useServiceAccount"))
+	}
+	switch {
+	case auth.KubeconfigFile != "":
+	case auth.KubeconfigSecret != nil:
+		if auth.KubeconfigSecret.Name == "" {
+			errs = append(errs, field.Required(p.Child("kubeconfigSecret", "name"), ""))
+		}
+		if auth.KubeconfigSecret.Key == "" {
+			errs = append(errs, field.Required(p.Child("kubeconfigSecret", "key"), ""))
+		}
+	case auth.Kubeconfig != nil:
+		if len(auth.Kubeconfig.Clusters) == 0 {
+			errs = append(errs, field.Required(p.Child("kubeconfig", "clusters"), "the kubeconfig must define at least one cluster"))
+		}
+	case auth.UseServiceAccount:
+	default:
+		errs = append(errs, field.Required(p, "one of kubeconfigFile, kubeconfigSecret, kubeconfig or useServiceAccount must be set"))
+	}
+	return errs
+}
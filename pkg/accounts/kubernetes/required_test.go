@@ -0,0 +1,39 @@
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
+	"github.com/armory/spinnaker-operator/pkg/test"
+	"github.com/stretchr/testify/assert"
+	clientcmdv1 "k8s.io/client-go/tools/clientcmd/api/v1"
+)
+
+func TestCheckRequiredFields(t *testing.T) {
+	cases := []struct {
+		name     string
+		auth     *interfaces.KubernetesAuth
+		expected string
+	}{
+		{"kubeconfig file", &interfaces.KubernetesAuth{KubeconfigFile: "encryptedFile:s3!b:bucket!f:kubeconfig"}, ""},
+		{"kubeconfig secret", &interfaces.KubernetesAuth{KubeconfigSecret: &interfaces.SecretInNamespaceReference{Name: "kubeconfig", Key: "config"}}, ""},
+		{"inline kubeconfig", &interfaces.KubernetesAuth{Kubeconfig: &clientcmdv1.Config{Clusters: []clientcmdv1.NamedCluster{{Name: "cluster"}}}}, ""},
+		{"service account", &interfaces.KubernetesAuth{UseServiceAccount: true}, ""},
+		{"missing auth", nil, "spec.kubernetes: Required value: Kubernetes accounts need a kubeconfigFile, kubeconfigSecret, kubeconfig or useServiceAccount"},
+		{"empty auth", &interfaces.KubernetesAuth{}, "spec.kubernetes: Required value: one of kubeconfigFile, kubeconfigSecret, kubeconfig or useServiceAccount must be set"},
+		{"incomplete secret", &interfaces.KubernetesAuth{KubeconfigSecret: &interfaces.SecretInNamespaceReference{Name: "kubeconfig"}}, "spec.kubernetes.kubeconfigSecret.key: Required value"},
+		{"kubeconfig without cluster", &interfaces.KubernetesAuth{Kubeconfig: &clientcmdv1.Config{}}, "spec.kubernetes.kubeconfig.clusters: Required value: the kubeconfig must define at least one cluster"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			acc := test.TypesFactory.NewAccount()
+			acc.GetSpec().Kubernetes = c.auth
+			errs := (&AccountType{}).CheckRequiredFields(acc)
+			if c.expected == "" {
+				assert.Empty(t, errs)
+			} else {
+				assert.Equal(t, c.expected, errs.ToAggregate().Error())
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package kubernetes
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/armory/spinnaker-operator/pkg/accounts/account"
+)
+
+// GetTarget returns the server of the inlined kubeconfig's current context, or the reference to the kubeconfig,
+// along with the namespaces the account is restricted to
+func (k *Account) GetTarget() string {
+	if k.Auth == nil {
+		return ""
+	}
+	var t string
+	switch {
+	case k.Auth.Kubeconfig != nil:
+		if t = k.server(); t == "" {
+			return ""
+		}
+	case k.Auth.KubeconfigSecret != nil:
+		t = fmt.Sprintf("secret %s key %s", k.Auth.KubeconfigSecret.Name, k.Auth.KubeconfigSecret.Key)
+	case k.Auth.KubeconfigFile != "":
+		t = k.Auth.KubeconfigFile
+	case k.Auth.UseServiceAccount:
+		t = "in-cluster"
+	default:
+		return ""
+	}
+	if nss := sortedCopy(k.Env.Namespaces); len(nss) > 0 {
+		t += " namespaces " + strings.Join(nss, ",")
+	}
+	if nss := sortedCopy(k.Env.OmitNamespaces); len(nss) > 0 {
+		t += " omitting namespaces " + strings.Join(nss, ",")
+	}
+	return t
+}
+
+// server returns the normalized server of the cluster of the kubeconfig's current context, or of its only cluster
+func (k *Account) server() string {
+	cfg := k.Auth.Kubeconfig
+	cluster := ""
+	for _, c := range cfg.Contexts {
+		if c.Name == cfg.CurrentContext {
+			cluster = c.Context.Cluster
+		}
+	}
+	for _, c := range cfg.Clusters {
+		if c.Name == cluster || (cluster == "" && len(cfg.Clusters) == 1) {
+			return account.NormalizeURL(c.Cluster.Server)
+		}
+	}
+	return ""
+}
+
+func sortedCopy(l []string) []string {
+	c := append([]string{}, l...)
+	sort.Strings(c)
+	return c
+}
@@ -0,0 +1,34 @@
+package kubernetes
+
+import (
+	"github.com/armory/spinnaker-operator/pkg/accounts/account"
+	"k8s.io/client-go/rest"
+)
+
+// pooledConfig returns a copy of the config using a transport of the pool, shared with the configs reaching the same
+// host with the same CA and client certificate. Configs with exec or auth plugins, a proxy or a custom transport
+// are returned as is, their transport can't be shared.
+func pooledConfig(config *rest.Config, pool *account.TransportPool) (*rest.Config, error) {
+	if pool == nil || config.ExecProvider != nil || config.AuthProvider != nil || config.Proxy != nil ||
+		config.Dial != nil || config.Transport != nil || config.WrapTransport != nil {
+		return config, nil
+	}
+	c := rest.CopyConfig(config)
+	if err := rest.LoadTLSFiles(c); err != nil {
+		return nil, err
+	}
+	tlsConfig, err := rest.TLSConfigFor(c)
+	if err != nil {
+		return nil, err
+	}
+	key := account.TransportKey{
+		Host:              c.Host,
+		ServerName:        c.ServerName,
+		Insecure:          c.Insecure,
+		CAFingerprint:     account.Fingerprint(c.CAData),
+		ClientFingerprint: account.Fingerprint(c.CertData, c.KeyData),
+	}
+	c.Transport = pool.Get(key, tlsConfig)
+	c.TLSClientConfig = rest.TLSClientConfig{}
+	return c, nil
+}
@@ -0,0 +1,84 @@
+package kubernetes
+
+import (
+	"encoding/pem"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/armory/spinnaker-operator/pkg/accounts/account"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	certutil "k8s.io/client-go/util/cert"
+)
+
+func TestPooledConfigReusesConnections(t *testing.T) {
+	var mu sync.Mutex
+	conns := 0
+	s := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"major":"1","minor":"22","gitVersion":"v1.22.8"}`)
+	}))
+	s.Config.ConnState = func(c net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			mu.Lock()
+			conns++
+			mu.Unlock()
+		}
+	}
+	s.StartTLS()
+	defer s.Close()
+	caData := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: s.Certificate().Raw})
+
+	pool := account.NewTransportPool(2, time.Minute)
+	for i := 0; i < 3; i++ {
+		// each probe builds its config from the account, as the validator does
+		config := &rest.Config{Host: s.URL, BearerToken: "token", TLSClientConfig: rest.TLSClientConfig{CAData: caData}}
+		pooled, err := pooledConfig(config, pool)
+		if !assert.Nil(t, err) {
+			return
+		}
+		clientset, err := kubernetes.NewForConfig(pooled)
+		if !assert.Nil(t, err) {
+			return
+		}
+		_, err = clientset.Discovery().ServerVersion()
+		assert.Nil(t, err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 1, conns)
+}
+
+func TestPooledConfigKeys(t *testing.T) {
+	ca1, _, err := certutil.GenerateSelfSignedCertKey("ca1", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca2, _, err := certutil.GenerateSelfSignedCertKey("ca2", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := account.NewTransportPool(2, time.Minute)
+	pooled := func(host string, caData []byte) *rest.Config {
+		c, err := pooledConfig(&rest.Config{Host: host, TLSClientConfig: rest.TLSClientConfig{CAData: caData}}, pool)
+		assert.Nil(t, err)
+		return c
+	}
+	a := pooled("https://a.example.com", ca1)
+	assert.Nil(t, a.TLSClientConfig.CAData)
+	assert.Same(t, a.Transport, pooled("https://a.example.com", ca1).Transport)
+	assert.NotSame(t, a.Transport, pooled("https://a.example.com", ca2).Transport)
+	assert.NotSame(t, a.Transport, pooled("https://b.example.com", ca1).Transport)
+
+	exec := &rest.Config{Host: "https://a.example.com", ExecProvider: &clientcmdapi.ExecConfig{Command: "aws-iam-authenticator"}}
+	c, err := pooledConfig(exec, pool)
+	assert.Nil(t, err)
+	assert.Same(t, exec, c)
+}
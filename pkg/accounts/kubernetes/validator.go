@@ -5,17 +5,23 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	tools "github.com/armory/go-yaml-tools/pkg/secrets"
+	"github.com/armory/spinnaker-operator/pkg/accounts/account"
 	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
 	"github.com/armory/spinnaker-operator/pkg/inspect"
 	"github.com/armory/spinnaker-operator/pkg/secrets"
 	"github.com/armory/spinnaker-operator/pkg/util"
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	v13 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
@@ -33,8 +39,15 @@ var (
 	noKubernetesDefinedError = fmt.Errorf("kubernetes needs to be defined")
 	noValidKubeconfigError   = fmt.Errorf("no valid kubeconfig file, kubeconfig content or service account information found")
 	noServiceAccountName     = fmt.Errorf("no service account name configured in SpinnakerService for clouddriver")
+	// ErrServiceAccountNotFound is returned when the service account used by an account doesn't exist
+	ErrServiceAccountNotFound = fmt.Errorf("service account not found")
 )
 
+const dnsLookupTimeout = 2 * time.Second
+
+// lookupHost resolves a hostname, overridden in tests
+var lookupHost = net.DefaultResolver.LookupHost
+
 type kubernetesAccountValidator struct {
 	account *Account
 }
@@ -50,7 +63,52 @@ func (k *kubernetesAccountValidator) Validate(spinSvc interfaces.SpinnakerServic
 	if config == nil {
 		return nil
 	}
-	return k.validateAccess(ctx, config)
+	if err := k.validateExecPlugins(config); err != nil {
+		return err
+	}
+	if err := k.validateCredentialsFormat(config); err != nil {
+		return err
+	}
+	if err := k.validateCredentialsExpiry(ctx, config); err != nil {
+		return err
+	}
+	// Resolving the hostname is cheap and catches typos before attempting the full connectivity check
+	if err := k.validateServerResolves(ctx, config); err != nil {
+		return err
+	}
+	if !account.ProviderConnectivityEnabled(ctx, string(interfaces.KubernetesAccountType)) {
+		return nil
+	}
+	if err := k.validateProxy(ctx, config); err != nil {
+		return err
+	}
+	pool, err := account.ProbeTransports()
+	if err != nil {
+		return err
+	}
+	if config, err = pooledConfig(config, pool); err != nil {
+		return fmt.Errorf("unable to build transport from rest config: %w", err)
+	}
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return fmt.Errorf("unable to build kubernetes clientset from rest config: %w", err)
+	}
+	if err := k.validateAccess(ctx, clientset); err != nil {
+		return err
+	}
+	k.validatePermissions(ctx, clientset)
+	k.validateAPIVersions(ctx, clientset)
+	if err := k.validateRemovedNamespaces(ctx, clientset); err != nil {
+		return err
+	}
+	cachingPermissions, err := util.BoolFromEnv(CachingAgentPermissionsEnv, false)
+	if err != nil {
+		return err
+	}
+	if cachingPermissions {
+		k.validateCachingPermissions(ctx, clientset)
+	}
+	return nil
 }
 
 func (k *kubernetesAccountValidator) makeClient(ctx context.Context, spinSvc interfaces.SpinnakerService, c client.Client) (*rest.Config, error) {
@@ -90,16 +148,22 @@ func makeClientFromFile(ctx context.Context, file string, settings authSettings,
 		if err != nil {
 			return nil, fmt.Errorf("error decoding kubeconfigFile from secret reference \"%s\":\n  %w", file, err)
 		}
-		kubeconfigBytes, err = ioutil.ReadFile(f)
+		kubeconfigBytes, err = secrets.ReadFile(f)
 		if err != nil {
 			return nil, fmt.Errorf("error loading kubeconfigFile \"%s\":\n  %w", f, err)
 		}
+		if err := secrets.CheckFormat(file, kubeconfigBytes, secrets.KubeconfigFormat); err != nil {
+			return nil, err
+		}
 	} else if filepath.IsAbs(file) {
 		// if file path is absolute, it may already be a path decoded by secret engines
-		kubeconfigBytes, err = ioutil.ReadFile(file)
+		kubeconfigBytes, err = secrets.ReadFile(file)
 		if err != nil {
 			return nil, fmt.Errorf("error loading kubeconfigFile \"%s\":\n  %w", file, err)
 		}
+		if err := secrets.CheckFormat(file, kubeconfigBytes, secrets.KubeconfigFormat); err != nil {
+			return nil, err
+		}
 	} else {
 		// we're taking relative file paths as files defined inside spec.spinnakerConfig.files
 		kubeconfigBytes = spinCfg.GetFileContent(file)
@@ -117,14 +181,16 @@ func makeClientFromFile(ctx context.Context, file string, settings authSettings,
 
 // makeClientFromSecretRef reads the client config from a Kubernetes secret in the current context's namespace
 func makeClientFromSecretRef(ctx context.Context, ref *interfaces.SecretInNamespaceReference, settings authSettings) (*rest.Config, error) {
-	sc, err := secrets.FromContextWithError(ctx)
-	if err != nil {
+	if _, err := secrets.FromContextWithError(ctx); err != nil {
 		return nil, errors.Wrap(err, "unable to make kubeconfig file")
 	}
-	str, err := util.GetSecretContent(sc.RestConfig, sc.Namespace, ref.Name, ref.Key)
+	str, err := secrets.GetKubernetesSecret(ctx, ref.Name, ref.Key)
 	if err != nil {
 		return nil, err
 	}
+	if err := secrets.CheckFormat(fmt.Sprintf("%s/%s", ref.Name, ref.Key), []byte(str), secrets.KubeconfigFormat); err != nil {
+		return nil, err
+	}
 	config, err := clientcmd.NewClientConfigFromBytes([]byte(str))
 	if err != nil {
 		return nil, err
@@ -173,7 +239,11 @@ func makeClientFromServiceAccount(ctx context.Context, spinSvc interfaces.Spinna
 	if err != nil {
 		return nil, noServiceAccountName
 	}
-	token, caPath, err := util.GetServiceAccountData(ctx, an, spinSvc.GetNamespace(), c)
+	ns := spinSvc.GetNamespace()
+	if err := validateServiceAccountExists(ctx, an, ns, account.APIReader(ctx, c)); err != nil {
+		return nil, err
+	}
+	token, caPath, err := util.GetServiceAccountData(ctx, an, ns, c)
 	if err != nil {
 		return nil, err
 	}
@@ -194,6 +264,21 @@ func makeClientFromServiceAccount(ctx context.Context, spinSvc interfaces.Spinna
 	}, nil
 }
 
+// validateServiceAccountExists checks the service account exists before looking up its token, a missing
+// service account otherwise surfaces as an authentication error. c should bypass the cache so that only the
+// get permission is needed on service accounts.
+func validateServiceAccountExists(ctx context.Context, name, ns string, c client.Reader) error {
+	sa := &corev1.ServiceAccount{}
+	err := c.Get(ctx, client.ObjectKey{Namespace: ns, Name: name}, sa)
+	if apierrors.IsNotFound(err) {
+		return fmt.Errorf("%w: service account \"%s\" doesn't exist in namespace \"%s\"", ErrServiceAccountNotFound, name, ns)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to get service account \"%s\" in namespace \"%s\": %w", name, ns, err)
+	}
+	return nil
+}
+
 func ensureSpinSvc(spinSvc interfaces.SpinnakerService, c client.Client, ctx context.Context) (interfaces.SpinnakerService, error) {
 	if spinSvc != nil {
 		return spinSvc, nil
@@ -277,11 +362,7 @@ type authSettings struct {
 	OAuthScopes         []string `json:"oAuthScopes,omitempty"`
 }
 
-func (k *kubernetesAccountValidator) validateAccess(ctx context.Context, cc *rest.Config) error {
-	clientset, err := kubernetes.NewForConfig(cc)
-	if err != nil {
-		return fmt.Errorf("unable to build kubernetes clientset from rest config: %w", err)
-	}
+func (k *kubernetesAccountValidator) validateAccess(ctx context.Context, clientset kubernetes.Interface) error {
 	// We want to keep the validation short (ideally just one request), so any improvement should remain short (e.g. not a request per namespace)
 	ns, err := inspect.GetStringArray(k.account.Settings, "namespaces")
 	if err != nil || len(ns) == 0 {
@@ -289,15 +370,142 @@ func (k *kubernetesAccountValidator) validateAccess(ctx context.Context, cc *res
 		// The test is analogous to what is done in Halyard
 		_, err = clientset.CoreV1().Namespaces().List(ctx, v13.ListOptions{})
 		if err != nil {
-			return fmt.Errorf("error listing namespaces in account \"%s\":\n  %w", k.account.Name, err)
+			return fmt.Errorf("error listing namespaces in account \"%s\":\n  %w", k.account.Name, classifyAccessError(err))
 		}
 	} else {
 		// Otherwise read resources just for the first namespace configured
 		_, err = clientset.CoreV1().Pods(ns[0]).List(ctx, v13.ListOptions{})
 		if err != nil {
-			return fmt.Errorf("error listing pods in account \"%s\", namespace \"%s\":\n  %w", k.account.Name, ns[0], err)
+			return fmt.Errorf("error listing pods in account \"%s\", namespace \"%s\":\n  %w", k.account.Name, ns[0], classifyAccessError(err))
+		}
+	}
+	return nil
+}
+
+// requiredPermissions is a representative set of the permissions Clouddriver needs to deploy and cache resources
+var requiredPermissions = []authorizationv1.ResourceAttributes{
+	{Verb: "list", Resource: "pods"},
+	{Verb: "watch", Resource: "pods"},
+	{Verb: "list", Resource: "services"},
+	{Verb: "create", Resource: "services"},
+	{Verb: "list", Resource: "events"},
+	{Verb: "list", Group: "apps", Resource: "replicasets"},
+	{Verb: "list", Group: "apps", Resource: "deployments"},
+	{Verb: "create", Group: "apps", Resource: "deployments"},
+	{Verb: "patch", Group: "apps", Resource: "deployments"},
+	{Verb: "delete", Group: "apps", Resource: "deployments"},
+}
+
+// validatePermissions reviews the account's credentials against the permissions Clouddriver needs and warns
+// about missing ones. Permissions are checked in the first configured namespace, or cluster wide.
+func (k *kubernetesAccountValidator) validatePermissions(ctx context.Context, clientset kubernetes.Interface) {
+	ns := k.permissionNamespace()
+	for _, p := range requiredPermissions {
+		attrs := p
+		attrs.Namespace = ns
+		review := &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{ResourceAttributes: &attrs},
+		}
+		res, err := clientset.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review, v13.CreateOptions{})
+		if err != nil {
+			account.Warn(ctx, "unable to verify permissions of account \"%s\": %v", k.account.Name, err)
+			return
+		}
+		if !res.Status.Allowed {
+			account.Warn(ctx, "account \"%s\" is not allowed to %s", k.account.Name, describePermission(attrs))
 		}
 	}
+}
+
+func describePermission(attrs authorizationv1.ResourceAttributes) string {
+	r := attrs.Resource
+	if attrs.Group != "" {
+		r = fmt.Sprintf("%s.%s", attrs.Resource, attrs.Group)
+	}
+	if attrs.Namespace == "" {
+		return fmt.Sprintf("%s %s", attrs.Verb, r)
+	}
+	return fmt.Sprintf("%s %s in namespace \"%s\"", attrs.Verb, r, attrs.Namespace)
+}
+
+// validateCredentialsFormat checks the certificates and key inlined in the kubeconfig are PEM encoded and the bearer
+// token is a token, not another credential
+func (k *kubernetesAccountValidator) validateCredentialsFormat(config *rest.Config) error {
+	for name, data := range map[string][]byte{
+		"certificate-authority-data": config.CAData,
+		"client-certificate-data":    config.CertData,
+		"client-key-data":            config.KeyData,
+	} {
+		if len(data) == 0 {
+			continue
+		}
+		if err := secrets.CheckFormat(fmt.Sprintf("%s of account %s", name, k.account.Name), data, secrets.PEMFormat); err != nil {
+			return err
+		}
+	}
+	if config.BearerToken != "" {
+		return secrets.CheckFormat(fmt.Sprintf("token of account %s", k.account.Name), []byte(config.BearerToken), secrets.TokenFormat)
+	}
+	return nil
+}
+
+// validateCredentialsExpiry checks the client certificate and bearer token are not expired
+func (k *kubernetesAccountValidator) validateCredentialsExpiry(ctx context.Context, config *rest.Config) error {
+	certData := config.CertData
+	if len(certData) == 0 && config.CertFile != "" {
+		b, err := ioutil.ReadFile(config.CertFile)
+		if err != nil {
+			return fmt.Errorf("unable to read client certificate of account %s: %w", k.account.Name, err)
+		}
+		certData = b
+	}
+	if err := account.CheckCertificateExpiry(ctx, fmt.Sprintf("client certificate of account %s", k.account.Name), certData); err != nil {
+		return err
+	}
+	token := config.BearerToken
+	if token == "" && config.BearerTokenFile != "" {
+		b, err := ioutil.ReadFile(config.BearerTokenFile)
+		if err != nil {
+			return fmt.Errorf("unable to read bearer token of account %s: %w", k.account.Name, err)
+		}
+		token = string(b)
+	}
+	return account.CheckTokenExpiry(ctx, fmt.Sprintf("bearer token of account %s", k.account.Name), token)
+}
+
+// serverURL returns the URL of the server, which kubeconfigs may set without a scheme
+func serverURL(host string) string {
+	if !strings.Contains(host, "://") {
+		return "https://" + host
+	}
+	return host
+}
+
+// classifyAccessError tells why the cluster couldn't be reached
+func classifyAccessError(err error) error {
+	return account.ClassifyConnectionError("the cluster", account.ClassifyTLSError("the cluster", err))
+}
+
+// validateServerResolves checks that the hostname of the kubeconfig server resolves, without connecting to it.
+// IP literals and servers reached through a proxy are not checked.
+func (k *kubernetesAccountValidator) validateServerResolves(ctx context.Context, cc *rest.Config) error {
+	if cc.Proxy != nil {
+		// the proxy resolves the server
+		return nil
+	}
+	u, err := url.Parse(serverURL(cc.Host))
+	if err != nil {
+		return fmt.Errorf("error parsing server url \"%s\" in account \"%s\":\n  %w", cc.Host, k.account.Name, err)
+	}
+	hostname := u.Hostname()
+	if hostname == "" || net.ParseIP(hostname) != nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, dnsLookupTimeout)
+	defer cancel()
+	if _, err := lookupHost(ctx, hostname); err != nil {
+		return fmt.Errorf("error validating server \"%s\" in account \"%s\": hostname does not resolve:\n  %w", hostname, k.account.Name, err)
+	}
 	return nil
 }
 
@@ -2,13 +2,40 @@ package kubernetes
 
 import (
 	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
 	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/armory/spinnaker-operator/pkg/accounts/account"
 	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
 	"github.com/armory/spinnaker-operator/pkg/secrets"
 	"github.com/armory/spinnaker-operator/pkg/test"
 	"github.com/stretchr/testify/assert"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+	clientcmdv1 "k8s.io/client-go/tools/clientcmd/api/v1"
+	crfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
 	logr "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/yaml"
 )
@@ -270,3 +297,355 @@ func TestSettingsTest(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateServerResolves(t *testing.T) {
+	defer func(l func(context.Context, string) ([]string, error)) { lookupHost = l }(lookupHost)
+	lookupHost = func(ctx context.Context, host string) ([]string, error) {
+		switch host {
+		case "mycluster.com":
+			return []string{"10.0.0.1"}, nil
+		case "10.0.0.1", "::1":
+			t.Errorf("IP literal %s should not be resolved", host)
+		}
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+
+	cases := []struct {
+		name        string
+		host        string
+		errExpected bool
+	}{
+		{"resolvable host", "https://mycluster.com:6443", false},
+		{"resolvable host without scheme", "mycluster.com:6443", false},
+		{"unresolvable host", "https://myclustr.com", true},
+		{"IPv4 literal", "https://10.0.0.1:6443", false},
+		{"IPv6 literal", "https://[::1]:6443", false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			v := &kubernetesAccountValidator{account: &Account{Name: "test"}}
+			err := v.validateServerResolves(context.TODO(), &rest.Config{Host: c.host})
+			if c.errExpected {
+				if assert.NotNil(t, err) {
+					assert.Contains(t, err.Error(), "hostname does not resolve")
+				}
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
+func TestValidatePermissions(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	clientset.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		attrs := review.Spec.ResourceAttributes
+		review.Status.Allowed = !(attrs.Verb == "delete" && attrs.Resource == "deployments")
+		return true, review, nil
+	})
+	v := &kubernetesAccountValidator{account: &Account{
+		Name:     "test",
+		Settings: map[string]interface{}{"namespaces": []string{"ns1"}},
+	}}
+	ctx := account.NewValidationContext(context.TODO(), account.ValidationOptions{Connectivity: true})
+	v.validatePermissions(ctx, clientset)
+	vc, _ := account.ValidationContextFrom(ctx)
+	assert.Equal(t, []string{`account "test" is not allowed to delete deployments.apps in namespace "ns1"`}, vc.Warnings())
+}
+
+func TestValidateCredentialsExpiry(t *testing.T) {
+	token := func(exp time.Time) string {
+		enc := base64.RawURLEncoding
+		return enc.EncodeToString([]byte(`{"alg":"none"}`)) + "." + enc.EncodeToString([]byte(fmt.Sprintf(`{"exp":%d}`, exp.Unix()))) + ".sig"
+	}
+	v := &kubernetesAccountValidator{account: &Account{Name: "test"}}
+
+	err := v.validateCredentialsExpiry(context.TODO(), &rest.Config{BearerToken: token(time.Now().Add(-time.Hour))})
+	if assert.NotNil(t, err) {
+		assert.True(t, errors.Is(err, account.ErrCredentialExpired))
+		assert.Contains(t, err.Error(), "bearer token of account test expired")
+	}
+
+	ctx := account.NewValidationContext(context.TODO(), account.ValidationOptions{ExpiryWarningWindow: 24 * time.Hour})
+	assert.Nil(t, v.validateCredentialsExpiry(ctx, &rest.Config{BearerToken: token(time.Now().Add(time.Hour))}))
+	vc, _ := account.ValidationContextFrom(ctx)
+	assert.Len(t, vc.Warnings(), 1)
+
+	assert.Nil(t, v.validateCredentialsExpiry(context.TODO(), &rest.Config{BearerToken: "test-token"}))
+
+	missing := filepath.Join(t.TempDir(), "missing")
+	err = v.validateCredentialsExpiry(context.TODO(), &rest.Config{BearerTokenFile: missing})
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "unable to read bearer token of account test")
+	}
+	err = v.validateCredentialsExpiry(context.TODO(), &rest.Config{TLSClientConfig: rest.TLSClientConfig{CertFile: missing}})
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "unable to read client certificate of account test")
+	}
+}
+
+func TestMakeClientMalformedKubeconfig(t *testing.T) {
+	kubeconfig := `
+apiVersion: v1
+kind: Config
+current-context: test-context
+clusters:
+- cluster:
+    server: http://mycluster.com
+  name: test-cluster
+contexts:
+- context:
+    cluster: test-cluster
+    user: test-user
+  name: test-context
+users:
+- name: test-user
+  user:
+    token: test-token
+`
+	cases := []struct {
+		name        string
+		content     string
+		errExpected string
+	}{
+		{"kubeconfig", kubeconfig, ""},
+		{"base64 encoded kubeconfig", base64.StdEncoding.EncodeToString([]byte(kubeconfig)), "content is base64 encoded, expected a kubeconfig once decoded"},
+		{"not a kubeconfig", "token: abc", "not a valid kubeconfig: no clusters defined"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			a := &Account{
+				Name: "test",
+				Auth: &interfaces.KubernetesAuth{KubeconfigFile: fmt.Sprintf("encryptedFile:noop!%s", c.content)},
+			}
+			kv := &kubernetesAccountValidator{account: a}
+			ctx := secrets.NewContext(context.TODO(), nil, "ns1")
+			defer secrets.Cleanup(ctx)
+			_, err := kv.makeClient(ctx, TypesFactory.NewService(), nil)
+			if c.errExpected == "" {
+				assert.Nil(t, err)
+				return
+			}
+			if assert.NotNil(t, err) {
+				assert.True(t, errors.Is(err, secrets.ErrMalformedSecret))
+				assert.Contains(t, err.Error(), c.errExpected)
+			}
+		})
+	}
+}
+
+func TestValidateCredentialsFormat(t *testing.T) {
+	v := &kubernetesAccountValidator{account: &Account{Name: "test"}}
+	pem := []byte("-----BEGIN CERTIFICATE-----\nMIIBszCCAVmgAwIBAgIBATAKBggqhkjOPQQDAjAUMRIwEAYDVQQDEwlzcGlubmFr\n-----END CERTIFICATE-----\n")
+	assert.Nil(t, v.validateCredentialsFormat(&rest.Config{TLSClientConfig: rest.TLSClientConfig{CAData: pem}}))
+
+	err := v.validateCredentialsFormat(&rest.Config{TLSClientConfig: rest.TLSClientConfig{CertData: []byte(base64.StdEncoding.EncodeToString(pem))}})
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "malformed secret client-certificate-data of account test: content is base64 encoded, expected a PEM once decoded", err.Error())
+	}
+
+	assert.Nil(t, v.validateCredentialsFormat(&rest.Config{BearerToken: "eyJhbGciOiJSUzI1NiJ9.eyJzdWIiOiJzcGlubmFrZXIifQ.c2ln"}))
+	err = v.validateCredentialsFormat(&rest.Config{BearerToken: `{"type": "service_account", "project_id": "my-project", "private_key": "key"}`})
+	if assert.NotNil(t, err) {
+		assert.True(t, errors.Is(err, secrets.ErrCredentialMismatch))
+		assert.Equal(t, "malformed secret token of account test: credential does not look like a bearer token credential (found GCP JSON key instead)", err.Error())
+	}
+}
+
+func TestMakeClientFromServiceAccount(t *testing.T) {
+	t.Setenv("KUBERNETES_SERVICE_HOST", "10.0.0.1")
+	t.Setenv("KUBERNETES_SERVICE_PORT", "443")
+	spinSvc := test.TypesFactory.NewService()
+	spinSvc.SetNamespace("spinnaker")
+	spinSvc.GetSpinnakerConfig().ServiceSettings = map[string]interfaces.FreeForm{
+		"clouddriver": {"kubernetes": map[string]interface{}{"serviceAccountName": "clouddriver"}},
+	}
+	sa := &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "clouddriver", Namespace: "spinnaker"}}
+	token := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "clouddriver-token",
+			Namespace:   "spinnaker",
+			Annotations: map[string]string{corev1.ServiceAccountNameKey: "clouddriver"},
+		},
+		Type: corev1.SecretTypeServiceAccountToken,
+		Data: map[string][]byte{corev1.ServiceAccountTokenKey: []byte("token")},
+	}
+
+	t.Run("existing service account", func(t *testing.T) {
+		c := crfake.NewClientBuilder().WithObjects(sa, token).Build()
+		cfg, err := makeClientFromServiceAccount(context.TODO(), spinSvc, c)
+		if assert.Nil(t, err) {
+			assert.Equal(t, "token", cfg.BearerToken)
+		}
+	})
+
+	t.Run("missing service account", func(t *testing.T) {
+		c := crfake.NewClientBuilder().WithObjects(token).Build()
+		_, err := makeClientFromServiceAccount(context.TODO(), spinSvc, c)
+		if assert.NotNil(t, err) {
+			assert.True(t, errors.Is(err, ErrServiceAccountNotFound))
+			assert.Equal(t, "service account not found: service account \"clouddriver\" doesn't exist in namespace \"spinnaker\"", err.Error())
+		}
+	})
+
+	t.Run("api reader", func(t *testing.T) {
+		c := crfake.NewClientBuilder().WithObjects(token).Build()
+		r := crfake.NewClientBuilder().WithObjects(sa).Build()
+		ctx := account.NewValidationContext(context.TODO(), account.ValidationOptions{APIReader: r})
+		cfg, err := makeClientFromServiceAccount(ctx, spinSvc, c)
+		if assert.Nil(t, err) {
+			assert.Equal(t, "token", cfg.BearerToken)
+		}
+	})
+}
+
+func TestValidateExecPlugins(t *testing.T) {
+	kubeconfig := func(t *testing.T, user string) *rest.Config {
+		cfg := &clientcmdv1.Config{}
+		assert.Nil(t, yaml.Unmarshal([]byte(`
+apiVersion: v1
+kind: Config
+current-context: ctx
+clusters:
+- name: cluster
+  cluster:
+    server: https://mycluster.com
+contexts:
+- name: ctx
+  context:
+    cluster: cluster
+    user: user
+users:
+- name: user
+  user:
+    `+user), cfg))
+		c, err := makeClientFromConfigAPI(cfg, authSettings{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+	exec := `exec:
+      apiVersion: client.authentication.k8s.io/v1beta1
+      command: aws-iam-authenticator
+      args: ["token", "-i", "cluster"]
+      interactiveMode: Never`
+	v := &kubernetesAccountValidator{account: &Account{Name: "test"}}
+
+	t.Run("allowed exec command", func(t *testing.T) {
+		t.Setenv(ExecPluginAllowlistEnv, "gke-gcloud-auth-plugin,aws-iam-authenticator")
+		assert.Nil(t, v.validateExecPlugins(kubeconfig(t, exec)))
+	})
+
+	t.Run("disallowed exec command", func(t *testing.T) {
+		t.Setenv(ExecPluginAllowlistEnv, "gke-gcloud-auth-plugin")
+		err := v.validateExecPlugins(kubeconfig(t, exec))
+		if assert.NotNil(t, err) {
+			assert.True(t, errors.Is(err, ErrExecPluginNotAllowed))
+			assert.Equal(t, `credential plugin not allowed: account test runs command "aws-iam-authenticator", add it to KUBERNETES_EXEC_PLUGIN_ALLOWLIST to allow it`, err.Error())
+		}
+	})
+
+	t.Run("empty allowlist", func(t *testing.T) {
+		assert.NotNil(t, v.validateExecPlugins(kubeconfig(t, exec)))
+	})
+
+	t.Run("without exec auth", func(t *testing.T) {
+		assert.Nil(t, v.validateExecPlugins(kubeconfig(t, "token: abc")))
+	})
+}
+
+// newTLSServer returns a server presenting a certificate signed by the given CA, or self-signed if ca is nil
+func newTLSServer(t *testing.T, ca *x509.Certificate, caKey crypto.Signer, tmpl *x509.Certificate) *httptest.Server {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parent, parentKey := tmpl, crypto.Signer(key)
+	if ca != nil {
+		parent, parentKey = ca, caKey
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, key.Public(), parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"kind":"NamespaceList","apiVersion":"v1","metadata":{},"items":[]}`)
+	}))
+	s.TLS = &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}}
+	s.StartTLS()
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestValidateAccessCertificateErrors(t *testing.T) {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caTmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-48 * time.Hour),
+		NotAfter:              time.Now().Add(48 * time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTmpl, caTmpl, caKey.Public(), caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, _ := x509.ParseCertificate(caDER)
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER})
+	leaf := func(notAfter time.Time, dnsNames []string, ips []net.IP) *x509.Certificate {
+		return &x509.Certificate{
+			SerialNumber: big.NewInt(2),
+			Subject:      pkix.Name{CommonName: "cluster"},
+			NotBefore:    time.Now().Add(-48 * time.Hour),
+			NotAfter:     notAfter,
+			DNSNames:     dnsNames,
+			IPAddresses:  ips,
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		}
+	}
+	localhost := []net.IP{net.ParseIP("127.0.0.1")}
+	validate := func(s *httptest.Server, caData []byte) error {
+		clientset, err := kubernetes.NewForConfig(&rest.Config{Host: s.URL, TLSClientConfig: rest.TLSClientConfig{CAData: caData}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		v := &kubernetesAccountValidator{account: &Account{Name: "test"}}
+		return v.validateAccess(context.TODO(), clientset)
+	}
+
+	cases := []struct {
+		name        string
+		server      *httptest.Server
+		caData      []byte
+		expected    error
+		errExpected string
+	}{
+		{"valid", newTLSServer(t, ca, caKey, leaf(time.Now().Add(time.Hour), nil, localhost)), caPEM, nil, ""},
+		{"expired", newTLSServer(t, ca, caKey, leaf(time.Now().Add(-time.Hour), nil, localhost)), caPEM, account.ErrEndpointCertificateExpired, "renew it"},
+		{"hostname mismatch", newTLSServer(t, ca, caKey, leaf(time.Now().Add(time.Hour), []string{"cluster.example.com"}, nil)), caPEM, account.ErrEndpointCertificateHostnameMismatch, "the certificate is only valid for cluster.example.com"},
+		{"unknown authority", newTLSServer(t, ca, caKey, leaf(time.Now().Add(time.Hour), nil, localhost)), nil, account.ErrEndpointCertificateUnknownAuthority, "add the CA to the account's CA bundle"},
+		{"self-signed", newTLSServer(t, nil, nil, leaf(time.Now().Add(time.Hour), nil, localhost)), nil, account.ErrEndpointCertificateSelfSigned, "uses a self-signed certificate"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := validate(c.server, c.caData)
+			if c.expected == nil {
+				assert.Nil(t, err)
+				return
+			}
+			if assert.NotNil(t, err) {
+				assert.True(t, errors.Is(err, c.expected), err.Error())
+				assert.Contains(t, err.Error(), c.errExpected)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package accounts
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/armory/spinnaker-operator/pkg/util"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ReferencingKindsEnv lists the kinds of the manifests referencing accounts by name and the paths of the fields
+// holding the names, as apiVersion/Kind=path|path, e.g. "v1/ConfigMap=data.account,example.com/v1/Pipeline=spec.stages[].account".
+// A path segment followed by [] goes through each item of a list.
+const ReferencingKindsEnv = "ACCOUNT_REFERENCING_KINDS"
+
+// ReferencingKind is a kind of manifest referencing accounts by name at the given field paths
+type ReferencingKind struct {
+	schema.GroupVersionKind
+	Paths []string
+}
+
+// ReferencingKindsFromEnv returns the referencing kinds set in the environment
+func ReferencingKindsFromEnv() ([]ReferencingKind, error) {
+	kinds := make([]ReferencingKind, 0)
+	for _, e := range util.ListFromEnv(ReferencingKindsEnv) {
+		k, err := parseReferencingKind(e)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s entry \"%s\": %w", ReferencingKindsEnv, e, err)
+		}
+		kinds = append(kinds, k)
+	}
+	return kinds, nil
+}
+
+func parseReferencingKind(e string) (ReferencingKind, error) {
+	k := ReferencingKind{}
+	kv := strings.SplitN(e, "=", 2)
+	i := strings.LastIndex(kv[0], "/")
+	if len(kv) != 2 || i <= 0 || i == len(kv[0])-1 || kv[1] == "" {
+		return k, errors.New("expected apiVersion/Kind=path|path")
+	}
+	gv, err := schema.ParseGroupVersion(kv[0][:i])
+	if err != nil {
+		return k, err
+	}
+	k.GroupVersionKind = gv.WithKind(kv[0][i+1:])
+	for _, p := range strings.Split(kv[1], "|") {
+		if p == "" || strings.HasPrefix(p, ".") || strings.HasSuffix(p, ".") || strings.Contains(p, "..") {
+			return k, fmt.Errorf("invalid path \"%s\"", p)
+		}
+		k.Paths = append(k.Paths, p)
+	}
+	return k, nil
+}
+
+// FindManifestReferences returns the fields of the manifest at the given paths set to the account name
+func FindManifestReferences(obj map[string]interface{}, paths []string, name string) []string {
+	found := make([]string, 0)
+	for _, p := range paths {
+		found = appendManifestReferences(found, "", obj, strings.Split(p, "."), name)
+	}
+	sort.Strings(found)
+	return found
+}
+
+func appendManifestReferences(found []string, path string, v interface{}, segments []string, name string) []string {
+	if len(segments) == 0 {
+		if s, ok := v.(string); ok && s == name {
+			found = append(found, path)
+		}
+		return found
+	}
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return found
+	}
+	seg := segments[0]
+	key := strings.TrimSuffix(seg, "[]")
+	child := strings.TrimPrefix(path+"."+key, ".")
+	if key == seg {
+		return appendManifestReferences(found, child, m[key], segments[1:], name)
+	}
+	l, _ := m[key].([]interface{})
+	for i, item := range l {
+		found = appendManifestReferences(found, fmt.Sprintf("%s[%d]", child, i), item, segments[1:], name)
+	}
+	return found
+}
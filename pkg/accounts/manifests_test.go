@@ -0,0 +1,40 @@
+package accounts
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestReferencingKindsFromEnv(t *testing.T) {
+	t.Setenv(ReferencingKindsEnv, "v1/ConfigMap=data.account,example.com/v1/Pipeline=spec.account|spec.stages[].account")
+	kinds, err := ReferencingKindsFromEnv()
+	if assert.Nil(t, err) {
+		assert.Equal(t, []ReferencingKind{
+			{GroupVersionKind: schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, Paths: []string{"data.account"}},
+			{GroupVersionKind: schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Pipeline"}, Paths: []string{"spec.account", "spec.stages[].account"}},
+		}, kinds)
+	}
+
+	for _, v := range []string{"ConfigMap=data.account", "v1/ConfigMap", "v1/=data.account", "v1/ConfigMap=", "v1/ConfigMap=data..account", "a/b/c/Kind=spec"} {
+		t.Setenv(ReferencingKindsEnv, v)
+		_, err := ReferencingKindsFromEnv()
+		assert.NotNil(t, err, v)
+	}
+}
+
+func TestFindManifestReferences(t *testing.T) {
+	obj := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"account": "kube",
+			"stages": []interface{}{
+				map[string]interface{}{"account": "other"},
+				map[string]interface{}{"account": "kube"},
+				"not an object",
+			},
+		},
+	}
+	assert.Equal(t, []string{"spec.account", "spec.stages[1].account"}, FindManifestReferences(obj, []string{"spec.account", "spec.stages[].account"}, "kube"))
+	assert.Empty(t, FindManifestReferences(obj, []string{"spec.account", "spec.stages[].account", "spec.missing[].account"}, "gone"))
+}
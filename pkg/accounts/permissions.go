@@ -0,0 +1,46 @@
+package accounts
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
+)
+
+// authzEnabledKey enables Fiat, without it account permissions are not enforced
+const authzEnabledKey = "security.authz.enabled"
+
+// CheckPermissions returns warnings when the account's permissions reference functionality disabled in the
+// SpinnakerService: authorization itself, or the provider of the account's type.
+func CheckPermissions(acc interfaces.SpinnakerAccount, spinSvc interfaces.SpinnakerService) []string {
+	granted := grantedAuthorizations(acc.GetSpec().Permissions)
+	if len(granted) == 0 {
+		return nil
+	}
+	cfg := spinSvc.GetSpinnakerConfig()
+	warnings := make([]string, 0)
+	if enabled, _ := cfg.GetHalConfigPropBool(authzEnabledKey, false); !enabled {
+		warnings = append(warnings, fmt.Sprintf("account %s grants %s permissions but authorization is disabled (%s), permissions will not be enforced", acc.GetName(), strings.Join(granted, ", "), authzEnabledKey))
+	}
+	if t, err := GetType(acc.GetSpec().Type); err == nil {
+		key := strings.TrimSuffix(t.GetConfigAccountsKey(), ".accounts") + ".enabled"
+		// Only warn when the provider is explicitly disabled
+		if enabled, err := cfg.GetHalConfigPropBool(key, true); err == nil && !enabled {
+			warnings = append(warnings, fmt.Sprintf("account %s grants %s permissions on provider %s which is disabled (%s)", acc.GetName(), strings.Join(granted, ", "), t.GetType(), key))
+		}
+	}
+	return warnings
+}
+
+// grantedAuthorizations returns the sorted authorizations granted to at least one role
+func grantedAuthorizations(p interfaces.AccountPermissions) []string {
+	granted := make([]string, 0)
+	for a, roles := range p {
+		if len(roles) > 0 {
+			granted = append(granted, string(a))
+		}
+	}
+	sort.Strings(granted)
+	return granted
+}
@@ -0,0 +1,68 @@
+package accounts
+
+import (
+	"testing"
+
+	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
+	"github.com/armory/spinnaker-operator/pkg/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckPermissions(t *testing.T) {
+	acc := test.TypesFactory.NewAccount()
+	acc.SetName("kube")
+	acc.GetSpec().Type = interfaces.KubernetesAccountType
+	acc.GetSpec().Permissions = interfaces.AccountPermissions{
+		"WRITE": {"admins"},
+		"READ":  {"admins", "devs"},
+	}
+
+	newService := func(config string) interfaces.SpinnakerService {
+		svc := test.TypesFactory.NewService()
+		test.ReadYamlString([]byte(`
+apiVersion: spinnaker.io/v1alpha2
+kind: SpinnakerService
+metadata:
+  name: spinnaker
+spec:
+  spinnakerConfig:
+    config:
+`+config), svc, t)
+		return svc
+	}
+
+	t.Run("disabled features", func(t *testing.T) {
+		svc := newService(`
+      security:
+        authz:
+          enabled: false
+      providers:
+        kubernetes:
+          enabled: false
+`)
+		assert.Equal(t, []string{
+			"account kube grants READ, WRITE permissions but authorization is disabled (security.authz.enabled), permissions will not be enforced",
+			"account kube grants READ, WRITE permissions on provider Kubernetes which is disabled (providers.kubernetes.enabled)",
+		}, CheckPermissions(acc, svc))
+	})
+
+	t.Run("enabled features", func(t *testing.T) {
+		svc := newService(`
+      security:
+        authz:
+          enabled: true
+      providers:
+        kubernetes:
+          enabled: true
+`)
+		assert.Empty(t, CheckPermissions(acc, svc))
+	})
+
+	t.Run("no permissions", func(t *testing.T) {
+		noPerms := acc.DeepCopySpinnakerAccount()
+		noPerms.GetSpec().Permissions = interfaces.AccountPermissions{"READ": {}}
+		assert.Empty(t, CheckPermissions(noPerms, newService(`
+      version: 1.28.0
+`)))
+	})
+}
@@ -0,0 +1,80 @@
+package accounts
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
+	"github.com/armory/spinnaker-operator/pkg/inspect"
+)
+
+const (
+	// canaryAccountSetting names the canary service integration account an account's metrics are read from
+	canaryAccountSetting = "canaryAccount"
+	// artifactAccountSetting names the artifact account an account's manifests are read from
+	artifactAccountSetting = "artifactAccount"
+)
+
+// CheckReferences returns warnings for the canary and artifact accounts referenced in the account's settings
+// that aren't configured in the SpinnakerService.
+func CheckReferences(ctx context.Context, acc interfaces.SpinnakerAccount, spinSvc interfaces.SpinnakerService) []string {
+	cfg := spinSvc.GetSpinnakerConfig()
+	warnings := make([]string, 0)
+	if name, err := inspect.GetObjectPropString(ctx, acc.GetSpec().Settings, canaryAccountSetting); err == nil && name != "" {
+		if !contains(canaryAccounts(ctx, cfg), name) {
+			warnings = append(warnings, fmt.Sprintf("account %s references canary account %s which is not configured in canary.serviceIntegrations of SpinnakerService %s", acc.GetName(), name, spinSvc.GetName()))
+		}
+	}
+	if name, err := inspect.GetObjectPropString(ctx, acc.GetSpec().Settings, artifactAccountSetting); err == nil && name != "" {
+		if !contains(artifactAccounts(cfg), name) {
+			warnings = append(warnings, fmt.Sprintf("account %s references artifact account %s which is not configured in artifacts of SpinnakerService %s", acc.GetName(), name, spinSvc.GetName()))
+		}
+	}
+	return warnings
+}
+
+// canaryAccounts returns the accounts of all canary service integrations
+func canaryAccounts(ctx context.Context, cfg *interfaces.SpinnakerConfig) []string {
+	names := make([]string, 0)
+	integrations, _ := cfg.GetHalConfigObjectArray(ctx, "canary.serviceIntegrations")
+	for _, i := range integrations {
+		names = append(names, accountNames(i)...)
+	}
+	return names
+}
+
+// artifactAccounts returns the accounts of all artifact providers
+func artifactAccounts(cfg *interfaces.SpinnakerConfig) []string {
+	names := make([]string, 0)
+	artifacts, ok := cfg.Config["artifacts"].(map[string]interface{})
+	if !ok {
+		return names
+	}
+	for _, p := range artifacts {
+		if provider, ok := p.(map[string]interface{}); ok {
+			names = append(names, accountNames(provider)...)
+		}
+	}
+	return names
+}
+
+// accountNames returns the names of the accounts listed under the accounts key
+func accountNames(obj map[string]interface{}) []string {
+	names := make([]string, 0)
+	accs, _ := inspect.GetObjectArray(obj, "accounts")
+	for _, a := range accs {
+		if n, ok := a["name"].(string); ok {
+			names = append(names, n)
+		}
+	}
+	return names
+}
+
+func contains(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
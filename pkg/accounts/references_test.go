@@ -0,0 +1,68 @@
+package accounts
+
+import (
+	"context"
+	"testing"
+
+	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
+	"github.com/armory/spinnaker-operator/pkg/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckReferences(t *testing.T) {
+	acc := test.TypesFactory.NewAccount()
+	acc.SetName("kube")
+	acc.GetSpec().Type = interfaces.KubernetesAccountType
+	acc.GetSpec().Settings = interfaces.FreeForm{
+		"canaryAccount":   "prometheus-prod",
+		"artifactAccount": "github-manifests",
+	}
+
+	newService := func(config string) interfaces.SpinnakerService {
+		svc := test.TypesFactory.NewService()
+		test.ReadYamlString([]byte(`
+apiVersion: spinnaker.io/v1alpha2
+kind: SpinnakerService
+metadata:
+  name: spinnaker
+spec:
+  spinnakerConfig:
+    config:
+`+config), svc, t)
+		return svc
+	}
+
+	t.Run("missing configs", func(t *testing.T) {
+		svc := newService(`
+      canary:
+        serviceIntegrations:
+        - name: prometheus
+          accounts:
+          - name: prometheus-staging
+`)
+		assert.Equal(t, []string{
+			"account kube references canary account prometheus-prod which is not configured in canary.serviceIntegrations of SpinnakerService spinnaker",
+			"account kube references artifact account github-manifests which is not configured in artifacts of SpinnakerService spinnaker",
+		}, CheckReferences(context.TODO(), acc, svc))
+	})
+
+	t.Run("present configs", func(t *testing.T) {
+		svc := newService(`
+      canary:
+        serviceIntegrations:
+        - name: google
+          accounts:
+          - name: gcs-canary
+        - name: prometheus
+          accounts:
+          - name: prometheus-staging
+          - name: prometheus-prod
+      artifacts:
+        github:
+          enabled: true
+          accounts:
+          - name: github-manifests
+`)
+		assert.Empty(t, CheckReferences(context.TODO(), acc, svc))
+	})
+}
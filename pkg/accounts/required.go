@@ -0,0 +1,44 @@
+package accounts
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/armory/spinnaker-operator/pkg/accounts/account"
+	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+var ErrMissingRequiredField = errors.New("missing required field")
+
+// CheckRequiredFields returns an error listing the required fields the account doesn't set.
+// Account types not declaring required fields are not checked.
+func CheckRequiredFields(t account.SpinnakerAccountType, acc interfaces.SpinnakerAccount) error {
+	c, ok := t.(account.RequiredFieldsChecker)
+	if !ok {
+		return nil
+	}
+	if errs := c.CheckRequiredFields(acc); len(errs) > 0 {
+		err := fmt.Errorf("%w: account \"%s\": %s", ErrMissingRequiredField, acc.GetName(), errs.ToAggregate().Error())
+		return account.WithSuggestions(err, requiredSuggestions(errs)...)
+	}
+	return nil
+}
+
+// requiredSuggestions suggests adding the missing fields that hold a single value, with a placeholder value
+func requiredSuggestions(errs field.ErrorList) []account.Suggestion {
+	s := make([]account.Suggestion, 0)
+	for _, e := range errs {
+		if e.Type != field.ErrorTypeRequired || e.Detail != "" || strings.Contains(e.Field, "[") {
+			continue
+		}
+		names := strings.Split(e.Field, ".")
+		s = append(s, account.Suggestion{
+			Op:    "add",
+			Path:  "/" + strings.Join(names, "/"),
+			Value: "<" + names[len(names)-1] + ">",
+		})
+	}
+	return s
+}
@@ -0,0 +1,242 @@
+package accounts
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+var ErrSchemaViolation = errors.New("schema violation")
+
+// Schema is a JSON Schema accounts are checked against. Only the structural and value keywords below are supported,
+// schemas using any other keyword are rejected rather than partially enforced.
+type Schema struct {
+	Type                 schemaTypes        `json:"type,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+
+	// annotations, ignored
+	SchemaURI   string        `json:"$schema,omitempty"`
+	ID          string        `json:"$id,omitempty"`
+	Title       string        `json:"title,omitempty"`
+	Description string        `json:"description,omitempty"`
+	Default     interface{}   `json:"default,omitempty"`
+	Examples    []interface{} `json:"examples,omitempty"`
+
+	pattern *regexp.Regexp
+}
+
+// schemaTypes is the type keyword, either a single type or a list of types
+type schemaTypes []string
+
+func (t *schemaTypes) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*t = schemaTypes{s}
+		return nil
+	}
+	var l []string
+	if err := json.Unmarshal(b, &l); err != nil {
+		return errors.New("type must be a string or a list of strings")
+	}
+	*t = l
+	return nil
+}
+
+var schemaTypeNames = map[string]bool{"object": true, "array": true, "string": true, "number": true, "integer": true, "boolean": true, "null": true}
+
+// ParseSchema parses a JSON Schema
+func ParseSchema(data []byte) (*Schema, error) {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.DisallowUnknownFields()
+	s := &Schema{}
+	if err := d.Decode(s); err != nil {
+		return nil, err
+	}
+	if err := s.compile(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Schema) compile() error {
+	for _, t := range s.Type {
+		if !schemaTypeNames[t] {
+			return fmt.Errorf("unknown type %s", t)
+		}
+	}
+	if s.Pattern != "" {
+		p, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern %s: %w", s.Pattern, err)
+		}
+		s.pattern = p
+	}
+	for _, p := range s.Properties {
+		if err := p.compile(); err != nil {
+			return err
+		}
+	}
+	if s.Items != nil {
+		return s.Items.compile()
+	}
+	return nil
+}
+
+// CheckSchema returns an error listing the fields of the account violating the schema
+func CheckSchema(s *Schema, acc interfaces.SpinnakerAccount) error {
+	b, err := json.Marshal(acc)
+	if err != nil {
+		return err
+	}
+	var obj interface{}
+	if err := json.Unmarshal(b, &obj); err != nil {
+		return err
+	}
+	if errs := s.Validate(obj, nil); len(errs) > 0 {
+		return fmt.Errorf("%w: account \"%s\": %s", ErrSchemaViolation, acc.GetName(), errs.ToAggregate().Error())
+	}
+	return nil
+}
+
+// Validate returns the violations of the schema by v, a value decoded from JSON
+func (s *Schema) Validate(v interface{}, path *field.Path) field.ErrorList {
+	if len(s.Type) > 0 && !s.hasType(v) {
+		return field.ErrorList{field.Invalid(path, shown(v), "must be of type "+strings.Join(s.Type, " or "))}
+	}
+	errs := field.ErrorList{}
+	if len(s.Enum) > 0 && !s.inEnum(v) {
+		allowed := make([]string, 0, len(s.Enum))
+		for _, e := range s.Enum {
+			b, _ := json.Marshal(e)
+			allowed = append(allowed, string(b))
+		}
+		errs = append(errs, field.NotSupported(path, shown(v), allowed))
+	}
+	switch t := v.(type) {
+	case map[string]interface{}:
+		errs = append(errs, s.validateObject(t, path)...)
+	case []interface{}:
+		if s.MinItems != nil && len(t) < *s.MinItems {
+			errs = append(errs, field.Invalid(path, shown(v), fmt.Sprintf("must have at least %d items", *s.MinItems)))
+		}
+		if s.MaxItems != nil && len(t) > *s.MaxItems {
+			errs = append(errs, field.TooMany(path, len(t), *s.MaxItems))
+		}
+		if s.Items != nil {
+			for i, item := range t {
+				errs = append(errs, s.Items.Validate(item, path.Index(i))...)
+			}
+		}
+	case string:
+		n := len([]rune(t))
+		if s.MinLength != nil && n < *s.MinLength {
+			errs = append(errs, field.Invalid(path, t, fmt.Sprintf("must be at least %d characters long", *s.MinLength)))
+		}
+		if s.MaxLength != nil && n > *s.MaxLength {
+			errs = append(errs, field.TooLong(path, t, *s.MaxLength))
+		}
+		if s.pattern != nil && !s.pattern.MatchString(t) {
+			errs = append(errs, field.Invalid(path, t, "must match "+s.Pattern))
+		}
+	case float64:
+		if s.Minimum != nil && t < *s.Minimum {
+			errs = append(errs, field.Invalid(path, t, fmt.Sprintf("must be greater than or equal to %v", *s.Minimum)))
+		}
+		if s.Maximum != nil && t > *s.Maximum {
+			errs = append(errs, field.Invalid(path, t, fmt.Sprintf("must be less than or equal to %v", *s.Maximum)))
+		}
+	}
+	return errs
+}
+
+func (s *Schema) validateObject(obj map[string]interface{}, path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	for _, r := range s.Required {
+		if _, ok := obj[r]; !ok {
+			errs = append(errs, field.Required(path.Child(r), ""))
+		}
+	}
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		p, ok := s.Properties[k]
+		if !ok {
+			if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+				errs = append(errs, field.Forbidden(path.Child(k), "not allowed by the schema"))
+			}
+			continue
+		}
+		errs = append(errs, p.Validate(obj[k], path.Child(k))...)
+	}
+	return errs
+}
+
+func (s *Schema) hasType(v interface{}) bool {
+	for _, t := range s.Type {
+		if t == jsonType(v) || (t == "number" && jsonType(v) == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Schema) inEnum(v interface{}) bool {
+	b, _ := json.Marshal(v)
+	for _, e := range s.Enum {
+		if eb, _ := json.Marshal(e); bytes.Equal(b, eb) {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonType returns the JSON Schema type of a value decoded from JSON
+func jsonType(v interface{}) string {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64:
+		if t == math.Trunc(t) {
+			return "integer"
+		}
+		return "number"
+	}
+	return "null"
+}
+
+// shown returns the value to show in errors, objects and arrays are only shown by their type
+func shown(v interface{}) interface{} {
+	switch v.(type) {
+	case map[string]interface{}, []interface{}:
+		return "<" + jsonType(v) + ">"
+	}
+	return v
+}
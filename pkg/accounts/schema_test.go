@@ -0,0 +1,60 @@
+package accounts
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchemaValidate(t *testing.T) {
+	s, err := ParseSchema([]byte(`{
+  "type": "object",
+  "required": ["name"],
+  "additionalProperties": false,
+  "properties": {
+    "name": {"type": "string", "minLength": 3, "maxLength": 8},
+    "tier": {"enum": ["gold", "silver"]},
+    "replicas": {"type": "integer", "minimum": 1, "maximum": 3},
+    "tags": {"type": "array", "maxItems": 2, "items": {"type": "string"}}
+  }
+}`))
+	if !assert.Nil(t, err) {
+		return
+	}
+	cases := []struct {
+		name     string
+		value    map[string]interface{}
+		expected string
+	}{
+		{"valid", map[string]interface{}{"name": "prod", "tier": "gold", "replicas": float64(2), "tags": []interface{}{"a"}}, ""},
+		{"required", map[string]interface{}{}, "name: Required value"},
+		{"additional property", map[string]interface{}{"name": "prod", "owner": "me"}, "owner: Forbidden: not allowed by the schema"},
+		{"too short", map[string]interface{}{"name": "ab"}, `name: Invalid value: "ab": must be at least 3 characters long`},
+		{"enum", map[string]interface{}{"name": "prod", "tier": "bronze"}, `tier: Unsupported value: "bronze": supported values: "\"gold\"", "\"silver\""`},
+		{"not an integer", map[string]interface{}{"name": "prod", "replicas": 1.5}, "replicas: Invalid value: 1.5: must be of type integer"},
+		{"maximum", map[string]interface{}{"name": "prod", "replicas": float64(4)}, "replicas: Invalid value: 4: must be less than or equal to 3"},
+		{"items", map[string]interface{}{"name": "prod", "tags": []interface{}{"a", true}}, "tags[1]: Invalid value: true: must be of type string"},
+		{"object shown by type", map[string]interface{}{"name": map[string]interface{}{}}, `name: Invalid value: "<object>": must be of type string`},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			errs := s.Validate(c.value, nil)
+			if c.expected == "" {
+				assert.Empty(t, errs)
+			} else {
+				assert.Equal(t, c.expected, errs.ToAggregate().Error())
+			}
+		})
+	}
+}
+
+func TestParseSchemaUnsupported(t *testing.T) {
+	_, err := ParseSchema([]byte(`{"oneOf": [{"type": "string"}]}`))
+	assert.NotNil(t, err)
+	_, err = ParseSchema([]byte(`{"type": "text"}`))
+	assert.NotNil(t, err)
+	_, err = ParseSchema([]byte(`{"properties": {"name": {"pattern": "("}}}`))
+	assert.NotNil(t, err)
+	_, err = ParseSchema([]byte(`{"$schema": "http://json-schema.org/draft-07/schema#", "type": ["string", "null"]}`))
+	assert.Nil(t, err)
+}
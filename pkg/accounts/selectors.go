@@ -0,0 +1,56 @@
+package accounts
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/armory/spinnaker-operator/pkg/accounts/account"
+	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+var ErrInvalidLabelSelector = errors.New("invalid label selector")
+
+// CheckLabelSelectors returns an error listing the label selectors of the account Clouddriver can't parse
+func CheckLabelSelectors(t account.SpinnakerAccountType, acc interfaces.SpinnakerAccount) error {
+	p, ok := t.(account.LabelSelectorsProvider)
+	if !ok {
+		return nil
+	}
+	errs := field.ErrorList{}
+	settings := acc.GetSpec().Settings
+	for _, n := range p.GetLabelSelectorSettings() {
+		v, ok := settings[n]
+		if !ok {
+			continue
+		}
+		if err := parseLabelSelector(v); err != nil {
+			errs = append(errs, field.Invalid(field.NewPath("spec", "settings", n), shown(v), err.Error()))
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: account \"%s\": %s", ErrInvalidLabelSelector, acc.GetName(), errs.ToAggregate().Error())
+}
+
+// parseLabelSelector parses a selector written as a string, e.g. "env in (prod),!canary", or as a LabelSelector object
+func parseLabelSelector(v interface{}) error {
+	if s, ok := v.(string); ok {
+		_, err := labels.Parse(s)
+		return err
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	ls := &metav1.LabelSelector{}
+	if err := json.Unmarshal(b, ls); err != nil {
+		return errors.New("must be a string or a label selector with matchLabels and matchExpressions")
+	}
+	_, err = metav1.LabelSelectorAsSelector(ls)
+	return err
+}
@@ -0,0 +1,45 @@
+package accounts
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/armory/spinnaker-operator/pkg/accounts/kubernetes"
+	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
+	"github.com/armory/spinnaker-operator/pkg/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckLabelSelectors(t *testing.T) {
+	cases := []struct {
+		name     string
+		settings interfaces.FreeForm
+		expected string
+	}{
+		{"no selector", interfaces.FreeForm{}, ""},
+		{"valid string selector", interfaces.FreeForm{"labelSelector": "env in (prod,staging),!canary"}, ""},
+		{"valid selector object", interfaces.FreeForm{"namespaceLabelSelector": map[string]interface{}{
+			"matchLabels":      map[string]interface{}{"team": "payments"},
+			"matchExpressions": []interface{}{map[string]interface{}{"key": "env", "operator": "NotIn", "values": []interface{}{"dev"}}},
+		}}, ""},
+		{"invalid string selector", interfaces.FreeForm{"labelSelector": "env in prod"},
+			`invalid label selector: account "kube": spec.settings.labelSelector: Invalid value: "env in prod": unable to parse requirement: found 'prod' expected: '('`},
+		{"invalid selector operator", interfaces.FreeForm{"namespaceLabelSelector": map[string]interface{}{
+			"matchExpressions": []interface{}{map[string]interface{}{"key": "env", "operator": "Is", "values": []interface{}{"dev"}}},
+		}}, `invalid label selector: account "kube": spec.settings.namespaceLabelSelector: Invalid value: "<object>": "Is" is not a valid pod selector operator`},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			acc := test.TypesFactory.NewAccount()
+			acc.SetName("kube")
+			acc.GetSpec().Settings = c.settings
+			err := CheckLabelSelectors(&kubernetes.AccountType{}, acc)
+			if c.expected == "" {
+				assert.Nil(t, err)
+			} else if assert.NotNil(t, err) {
+				assert.True(t, errors.Is(err, ErrInvalidLabelSelector))
+				assert.Equal(t, c.expected, err.Error())
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package accounts
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/armory/spinnaker-operator/pkg/accounts/account"
+	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
+)
+
+// FindDuplicateTargets returns a warning for each enabled account among others of the same type as the account with
+// the same target, which makes Spinnaker cache the target twice. Accounts of types not telling their target are
+// never duplicates.
+func FindDuplicateTargets(t account.SpinnakerAccountType, acc interfaces.SpinnakerAccount, others []interfaces.SpinnakerAccount) []string {
+	warnings := make([]string, 0)
+	target := targetOf(t, acc)
+	if target == "" {
+		return warnings
+	}
+	for _, o := range others {
+		if o.GetName() == acc.GetName() || !o.GetSpec().Enabled || !strings.EqualFold(string(o.GetSpec().Type), string(acc.GetSpec().Type)) {
+			continue
+		}
+		if targetOf(t, o) == target {
+			warnings = append(warnings, fmt.Sprintf("account %s has the same target as account %s: %s", acc.GetName(), o.GetName(), target))
+		}
+	}
+	return warnings
+}
+
+func targetOf(t account.SpinnakerAccountType, acc interfaces.SpinnakerAccount) string {
+	a, err := t.FromCRD(acc)
+	if err != nil {
+		return ""
+	}
+	if tp, ok := a.(account.TargetProvider); ok {
+		return tp.GetTarget()
+	}
+	return ""
+}
@@ -0,0 +1,48 @@
+package accounts
+
+import (
+	"testing"
+
+	"github.com/armory/spinnaker-operator/pkg/accounts/kubernetes"
+	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
+	"github.com/armory/spinnaker-operator/pkg/test"
+	"github.com/stretchr/testify/assert"
+	clientv1 "k8s.io/client-go/tools/clientcmd/api/v1"
+)
+
+func TestFindDuplicateTargets(t *testing.T) {
+	account := func(name, server string, namespaces ...interface{}) interfaces.SpinnakerAccount {
+		acc := test.TypesFactory.NewAccount()
+		acc.SetName(name)
+		acc.GetSpec().Enabled = true
+		acc.GetSpec().Type = interfaces.KubernetesAccountType
+		acc.GetSpec().Kubernetes = &interfaces.KubernetesAuth{Kubeconfig: &clientv1.Config{
+			CurrentContext: "default",
+			Contexts:       []clientv1.NamedContext{{Name: "default", Context: clientv1.Context{Cluster: "cluster"}}},
+			Clusters:       []clientv1.NamedCluster{{Name: "cluster", Cluster: clientv1.Cluster{Server: server}}},
+		}}
+		if len(namespaces) > 0 {
+			acc.GetSpec().Settings = interfaces.FreeForm{"namespaces": namespaces}
+		}
+		return acc
+	}
+	others := []interfaces.SpinnakerAccount{
+		account("prod", "https://prod.example.com:443"),
+		account("staging", "https://staging.example.com", "dev", "qa"),
+	}
+
+	t.Run("duplicate target", func(t *testing.T) {
+		w := FindDuplicateTargets(&kubernetes.AccountType{}, account("prod2", "https://PROD.example.com/"), others)
+		assert.Equal(t, []string{"account prod2 has the same target as account prod: https://prod.example.com:443"}, w)
+	})
+
+	t.Run("duplicate target with the same namespaces", func(t *testing.T) {
+		w := FindDuplicateTargets(&kubernetes.AccountType{}, account("staging2", "https://staging.example.com", "qa", "dev"), others)
+		assert.Equal(t, []string{"account staging2 has the same target as account staging: https://staging.example.com:443 namespaces dev,qa"}, w)
+	})
+
+	t.Run("unique target", func(t *testing.T) {
+		assert.Empty(t, FindDuplicateTargets(&kubernetes.AccountType{}, account("dev", "https://dev.example.com"), others))
+		assert.Empty(t, FindDuplicateTargets(&kubernetes.AccountType{}, account("staging-dev", "https://staging.example.com", "dev"), others))
+	})
+}
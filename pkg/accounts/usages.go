@@ -0,0 +1,54 @@
+package accounts
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
+)
+
+// FindAccountUsages returns the paths of the SpinnakerService config referencing the account by name: settings
+// whose key ends with "account" set to the name, or whose key ends with "accounts" listing it.
+func FindAccountUsages(name string, spinSvc interfaces.SpinnakerService) []string {
+	cfg := spinSvc.GetSpinnakerConfig()
+	paths := make([]string, 0)
+	paths = appendUsages(paths, "config", cfg.Config, name)
+	for svc, p := range cfg.Profiles {
+		paths = appendUsages(paths, "profiles."+svc, p, name)
+	}
+	for svc, s := range cfg.ServiceSettings {
+		paths = appendUsages(paths, "service-settings."+svc, s, name)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func appendUsages(paths []string, path string, v interface{}, name string) []string {
+	switch t := v.(type) {
+	case interfaces.FreeForm:
+		return appendUsages(paths, path, map[string]interface{}(t), name)
+	case map[string]interface{}:
+		for k, c := range t {
+			p := path + "." + k
+			key := strings.ToLower(k)
+			if s, ok := c.(string); ok && strings.HasSuffix(key, "account") && s == name {
+				paths = append(paths, p)
+				continue
+			}
+			if l, ok := c.([]interface{}); ok && strings.HasSuffix(key, "accounts") {
+				for i, e := range l {
+					if s, ok := e.(string); ok && s == name {
+						paths = append(paths, fmt.Sprintf("%s[%d]", p, i))
+					}
+				}
+			}
+			paths = appendUsages(paths, p, c, name)
+		}
+	case []interface{}:
+		for i, c := range t {
+			paths = appendUsages(paths, fmt.Sprintf("%s[%d]", path, i), c, name)
+		}
+	}
+	return paths
+}
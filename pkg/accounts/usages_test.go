@@ -0,0 +1,24 @@
+package accounts
+
+import (
+	"testing"
+
+	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
+	"github.com/armory/spinnaker-operator/pkg/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindAccountUsages(t *testing.T) {
+	svc := test.TypesFactory.NewService()
+	svc.GetSpinnakerConfig().Config = interfaces.FreeForm{
+		"providers": map[string]interface{}{
+			"kubernetes": map[string]interface{}{"primaryAccount": "kube", "accounts": []interface{}{map[string]interface{}{"name": "kube"}}},
+			"docker":     map[string]interface{}{"primaryAccount": "other"},
+		},
+	}
+	svc.GetSpinnakerConfig().Profiles = map[string]interfaces.FreeForm{
+		"orca": {"allowedAccounts": []interface{}{"other", "kube"}, "name": "kube"},
+	}
+	assert.Equal(t, []string{"config.providers.kubernetes.primaryAccount", "profiles.orca.allowedAccounts[1]"}, FindAccountUsages("kube", svc))
+	assert.Empty(t, FindAccountUsages("unused", svc))
+}
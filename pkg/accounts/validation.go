@@ -0,0 +1,47 @@
+package accounts
+
+import (
+	"os"
+	"strings"
+
+	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
+	"github.com/armory/spinnaker-operator/pkg/util"
+	"k8s.io/apimachinery/pkg/api/meta"
+)
+
+// AsyncValidationEnv moves the slow account validations from the admission webhook to the account controller
+const AsyncValidationEnv = "ACCOUNT_VALIDATION_ASYNC"
+
+// StrictValidationEnv denies accounts that would otherwise only get a warning
+const StrictValidationEnv = "ACCOUNT_VALIDATION_STRICT"
+
+// ProviderConnectivityEnvPrefix prefixes the variables overriding connectivity checks for a provider,
+// e.g. VALIDATE_CONNECTIVITY_DOCKER=false
+const ProviderConnectivityEnvPrefix = "VALIDATE_CONNECTIVITY_"
+
+// ProviderConnectivityFromEnv returns the connectivity overrides set in the environment, keyed by lowercase provider name
+func ProviderConnectivityFromEnv() (map[string]bool, error) {
+	overrides := map[string]bool{}
+	for _, e := range os.Environ() {
+		name := strings.SplitN(e, "=", 2)[0]
+		if !strings.HasPrefix(name, ProviderConnectivityEnvPrefix) || name == ProviderConnectivityEnvPrefix {
+			continue
+		}
+		enabled, err := util.BoolFromEnv(name, true)
+		if err != nil {
+			return nil, err
+		}
+		overrides[strings.ToLower(strings.TrimPrefix(name, ProviderConnectivityEnvPrefix))] = enabled
+	}
+	return overrides, nil
+}
+
+// AsyncValidationEnabled returns true if accounts are validated in the background
+func AsyncValidationEnabled() (bool, error) {
+	return util.BoolFromEnv(AsyncValidationEnv, false)
+}
+
+// failedValidation returns true if the account was found invalid by a background validation
+func failedValidation(a interfaces.SpinnakerAccount) bool {
+	return meta.IsStatusConditionFalse(a.GetStatus().Conditions, interfaces.AccountValidatedCondition)
+}
@@ -0,0 +1,61 @@
+package validator
+
+import (
+	"context"
+
+	"github.com/armory/spinnaker-operator/pkg/accounts"
+	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
+	"github.com/go-logr/logr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// StructuralCheck checks the fields, settings and endpoints of the account without connecting to it
+func StructuralCheck(ctx context.Context, r *Run) error {
+	for _, c := range StructuralChecks(nil) {
+		if err := c(ctx, r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StructuralChecks returns the steps of StructuralCheck in order, checking the given setting conflicts on top of
+// the ones of the account's type
+func StructuralChecks(conflicts accounts.SettingConflicts) []Check {
+	return []Check{
+		func(_ context.Context, r *Run) error {
+			return accounts.CheckRequiredFields(r.Type, r.Account)
+		},
+		func(_ context.Context, r *Run) error {
+			return accounts.CheckCanonicalValues(r.Type, r.Account)
+		},
+		func(_ context.Context, r *Run) error {
+			return accounts.CheckLabelSelectors(r.Type, r.Account)
+		},
+		func(_ context.Context, r *Run) error {
+			return accounts.CheckSettingConflicts(r.Type, r.Account, conflicts)
+		},
+		func(_ context.Context, r *Run) error {
+			a, err := r.Parsed()
+			if err != nil {
+				return err
+			}
+			return accounts.ValidateEndpoints(a)
+		},
+	}
+}
+
+// ProviderCheck runs the validator of the account's type against the SpinnakerService. Validators look the service
+// up with the client if it's nil, the check is skipped if both are nil.
+func ProviderCheck(spinSvc interfaces.SpinnakerService, c client.Client, log logr.Logger) Check {
+	return func(ctx context.Context, r *Run) error {
+		if spinSvc == nil && c == nil {
+			return nil
+		}
+		a, err := r.Parsed()
+		if err != nil {
+			return err
+		}
+		return a.NewValidator().Validate(spinSvc, c, ctx, log)
+	}
+}
@@ -0,0 +1,173 @@
+// Package validator validates SpinnakerAccounts outside of the admission webhook, e.g. in a provisioning service.
+// The webhook runs its own checks through the same pipeline.
+package validator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/armory/spinnaker-operator/pkg/accounts"
+	"github.com/armory/spinnaker-operator/pkg/accounts/account"
+	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
+	"github.com/armory/spinnaker-operator/pkg/secrets"
+	"github.com/go-logr/logr"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+var (
+	// ErrUnsupportedType is returned for accounts of a type that isn't registered with accounts.Register
+	ErrUnsupportedType = errors.New("unsupported account type")
+	// ErrUnparsable matches the errors of accounts their type can't parse
+	ErrUnparsable = errors.New("unparsable account")
+	// ErrNoTypesFactory is returned by Decode if accounts.TypesFactory isn't set
+	ErrNoTypesFactory = errors.New("accounts.TypesFactory is not set")
+)
+
+// Options configure a Validator
+type Options struct {
+	// RestConfig reads the Kubernetes secrets referenced by accounts
+	RestConfig *rest.Config
+	// Client reads the objects provider validations need, e.g. service accounts
+	Client client.Client
+	// Service is the SpinnakerService accounts are validated against, looked up with Client if nil. Provider
+	// validations are skipped if both are nil.
+	Service interfaces.SpinnakerService
+	// Validation holds the options of account validators, e.g. whether they can connect to the account's endpoints
+	Validation account.ValidationOptions
+	// Timeout bounds the validation of an account, unbounded if zero
+	Timeout time.Duration
+	// SecretNamespace is where Kubernetes secrets are looked up before the account's namespace
+	SecretNamespace string
+	// SecretOverrides replace the value of secrets, see secrets.NewContextWithOverrides
+	SecretOverrides map[string][]byte
+	// Checks are run in order on each account, StructuralCheck and ProviderCheck if nil
+	Checks []Check
+	// Log defaults to a logger discarding messages
+	Log logr.Logger
+}
+
+// Validator validates accounts
+type Validator struct {
+	opts Options
+}
+
+// Run is the validation of an account, passed to checks
+type Run struct {
+	Account interfaces.SpinnakerAccount
+	Type    account.SpinnakerAccountType
+	parsed  account.Account
+}
+
+// Parsed returns the account parsed by its type, parsing it on first use. Errors match ErrUnparsable.
+func (r *Run) Parsed() (account.Account, error) {
+	if r.parsed == nil {
+		a, err := r.Type.FromCRD(r.Account)
+		if err != nil {
+			return nil, &parseError{err: err}
+		}
+		r.parsed = a
+	}
+	return r.parsed, nil
+}
+
+// parseError keeps the message of the error returned by the account's type
+type parseError struct {
+	err error
+}
+
+func (e *parseError) Error() string {
+	return e.err.Error()
+}
+
+func (e *parseError) Unwrap() error {
+	return e.err
+}
+
+func (e *parseError) Is(target error) bool {
+	return target == ErrUnparsable
+}
+
+// Check is a step of the validation of an account. Warnings are recorded with account.Warn.
+type Check func(ctx context.Context, r *Run) error
+
+// Result is the outcome of the validation of an account
+type Result struct {
+	// Warnings raised by the checks, nil if the account is invalid
+	Warnings []string
+	// Probed is true if the checks connected to the account's endpoints
+	Probed bool
+}
+
+// New returns a validator with the given options
+func New(opts Options) *Validator {
+	if opts.Log.GetSink() == nil {
+		opts.Log = logr.Discard()
+	}
+	if opts.Checks == nil {
+		opts.Checks = []Check{StructuralCheck, ProviderCheck(opts.Service, opts.Client, opts.Log)}
+	}
+	return &Validator{opts: opts}
+}
+
+// Decode returns the account of a JSON or YAML manifest
+func Decode(manifest []byte) (interfaces.SpinnakerAccount, error) {
+	if accounts.TypesFactory == nil {
+		return nil, ErrNoTypesFactory
+	}
+	acc := accounts.TypesFactory.NewAccount()
+	if err := yaml.Unmarshal(manifest, acc); err != nil {
+		return nil, fmt.Errorf("unable to decode account: %w", err)
+	}
+	return acc, nil
+}
+
+// Validate runs the checks on the account, returning the warnings raised. The result is returned along with errors,
+// e.g. to tell whether endpoints were probed.
+func (v *Validator) Validate(ctx context.Context, acc interfaces.SpinnakerAccount) (Result, error) {
+	accType, err := accounts.GetType(acc.GetSpec().Type)
+	if err != nil {
+		return Result{}, fmt.Errorf("%w: %v", ErrUnsupportedType, err)
+	}
+	opts := v.opts.Validation
+	if old, ok := PreviousFrom(ctx); ok {
+		if prev, err := accType.FromCRD(old); err == nil {
+			opts.Previous = prev
+		}
+	}
+
+	if v.opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, v.opts.Timeout)
+		defer cancel()
+	}
+	ctx = secrets.NewContextWithOverrides(ctx, v.opts.RestConfig, acc.GetNamespace(), v.opts.SecretOverrides)
+	ctx = secrets.WithLookupNamespace(ctx, v.opts.SecretNamespace)
+	defer secrets.Cleanup(ctx)
+	ctx = account.NewValidationContext(ctx, opts)
+	vc, _ := account.ValidationContextFrom(ctx)
+
+	r := &Run{Account: acc, Type: accType}
+	for _, c := range v.opts.Checks {
+		if err := c(ctx, r); err != nil {
+			return Result{Probed: vc.Probed()}, err
+		}
+	}
+	return Result{Warnings: vc.Warnings(), Probed: vc.Probed()}, nil
+}
+
+type previousKey struct{}
+
+// WithPrevious records the account replaced by the one validated, for checks comparing them
+func WithPrevious(ctx context.Context, old interfaces.SpinnakerAccount) context.Context {
+	return context.WithValue(ctx, previousKey{}, old)
+}
+
+// PreviousFrom returns the account replaced by the one validated, if any
+func PreviousFrom(ctx context.Context) (interfaces.SpinnakerAccount, bool) {
+	old, ok := ctx.Value(previousKey{}).(interfaces.SpinnakerAccount)
+	return old, ok
+}
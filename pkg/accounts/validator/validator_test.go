@@ -0,0 +1,124 @@
+package validator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/armory/spinnaker-operator/pkg/accounts"
+	"github.com/armory/spinnaker-operator/pkg/accounts/account"
+	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
+	"github.com/armory/spinnaker-operator/pkg/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	accounts.TypesFactory = test.TypesFactory
+}
+
+const manifest = `
+apiVersion: spinnaker.io/v1alpha2
+kind: SpinnakerAccount
+metadata:
+  name: kube
+  namespace: ns1
+spec:
+  enabled: true
+  type: Kubernetes
+  kubernetes:
+    kubeconfig:
+      apiVersion: v1
+      kind: Config
+      current-context: ctx
+      clusters:
+      - name: cluster
+        cluster:
+          server: %s
+      contexts:
+      - name: ctx
+        context:
+          cluster: cluster
+          user: user
+      users:
+      - name: user
+        user:
+          token: token
+  settings:
+    providerVersion: V2
+`
+
+func decode(t *testing.T, server string) interfaces.SpinnakerAccount {
+	acc, err := Decode([]byte(fmt.Sprintf(manifest, server)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return acc
+}
+
+func TestDecode(t *testing.T) {
+	acc := decode(t, "https://kube.example.com")
+	assert.Equal(t, "kube", acc.GetName())
+	assert.Equal(t, interfaces.KubernetesAccountType, acc.GetSpec().Type)
+
+	_, err := Decode([]byte("spec: ["))
+	assert.NotNil(t, err)
+
+	accounts.TypesFactory = nil
+	defer func() { accounts.TypesFactory = test.TypesFactory }()
+	_, err = Decode([]byte(fmt.Sprintf(manifest, "https://kube.example.com")))
+	assert.Equal(t, ErrNoTypesFactory, err)
+}
+
+func TestValidate(t *testing.T) {
+	t.Run("valid account", func(t *testing.T) {
+		res, err := New(Options{}).Validate(context.TODO(), decode(t, "https://kube.example.com"))
+		assert.Nil(t, err)
+		assert.Empty(t, res.Warnings)
+		assert.False(t, res.Probed)
+	})
+
+	t.Run("invalid endpoint", func(t *testing.T) {
+		_, err := New(Options{}).Validate(context.TODO(), decode(t, "kube.example.com"))
+		assert.True(t, errors.Is(err, accounts.ErrInvalidEndpoint))
+	})
+
+	t.Run("unsupported type", func(t *testing.T) {
+		acc := decode(t, "https://kube.example.com")
+		acc.GetSpec().Type = "Mainframe"
+		_, err := New(Options{}).Validate(context.TODO(), acc)
+		assert.True(t, errors.Is(err, ErrUnsupportedType))
+	})
+
+	t.Run("custom checks", func(t *testing.T) {
+		var previous string
+		check := func(ctx context.Context, r *Run) error {
+			if prev, ok := account.PreviousFrom(ctx); ok {
+				previous = prev.GetName()
+			}
+			if account.ConnectivityEnabled(ctx) {
+				account.Warn(ctx, "probed %s", r.Account.GetName())
+			}
+			return nil
+		}
+		v := New(Options{Validation: account.ValidationOptions{Connectivity: true}, Checks: []Check{StructuralCheck, check}})
+		old := decode(t, "https://old.example.com")
+		old.SetName("old")
+		res, err := v.Validate(WithPrevious(context.TODO(), old), decode(t, "https://kube.example.com"))
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"probed kube"}, res.Warnings)
+		assert.True(t, res.Probed)
+		assert.Equal(t, "old", previous)
+	})
+}
+
+func TestRunParsed(t *testing.T) {
+	acc := decode(t, "https://kube.example.com")
+	acc.GetSpec().Settings["namespaces"] = 3
+	accType, err := accounts.GetType(acc.GetSpec().Type)
+	if !assert.Nil(t, err) {
+		return
+	}
+	_, err = (&Run{Account: acc, Type: accType}).Parsed()
+	assert.True(t, errors.Is(err, ErrUnparsable))
+}
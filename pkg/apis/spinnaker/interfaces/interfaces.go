@@ -45,6 +45,7 @@ type TypesFactory interface {
 	NewServiceList() SpinnakerServiceList
 	NewAccount() SpinnakerAccount
 	NewAccountList() SpinnakerAccountList
+	NewAccountGroup() SpinnakerAccountGroup
 	GetGroupVersion() schema.GroupVersion
 	DeepCopyLatestTypesFactory() TypesFactory
 	GetContinue() string
@@ -89,6 +90,14 @@ type SpinnakerAccount interface {
 	DeepCopySpinnakerAccount() SpinnakerAccount
 }
 
+// +kubebuilder:object:generate=false
+type SpinnakerAccountGroup interface {
+	v1.Object
+	runtime.Object
+	GetSpec() *SpinnakerAccountGroupSpec
+	DeepCopySpinnakerAccountGroup() SpinnakerAccountGroup
+}
+
 // +kubebuilder:object:generate=false
 type SpinnakerAccountList interface {
 	runtime.Object
@@ -306,6 +315,18 @@ type SpinnakerAccountSpec struct {
 	Settings FreeForm `json:"settings,omitempty"`
 }
 
+// SpinnakerAccountGroupSpec defines accounts managed and validated together
+// +k8s:openapi-gen=true
+type SpinnakerAccountGroupSpec struct {
+	Accounts []SpinnakerAccountGroupMember `json:"accounts"`
+}
+
+// +k8s:openapi-gen=true
+type SpinnakerAccountGroupMember struct {
+	Name string               `json:"name"`
+	Spec SpinnakerAccountSpec `json:"spec"`
+}
+
 // +k8s:openapi-gen=true
 type KubernetesAuth struct {
 	// KubeconfigFile referenced as an encrypted secret
@@ -333,8 +354,14 @@ type SecretInNamespaceReference struct {
 type SpinnakerAccountStatus struct {
 	InvalidReason   string        `json:"invalidReason"`
 	LastValidatedAt *v1.Timestamp `json:"lastValidatedAt"`
+	// Conditions of the account, such as AccountValidatedCondition
+	// +optional
+	Conditions []v1.Condition `json:"conditions,omitempty"`
 }
 
+// AccountValidatedCondition is set on accounts validated in the background
+const AccountValidatedCondition = "Validated"
+
 var _ TypesFactory = &TypesFactoryImpl{}
 
 // +kubebuilder:object:generate=false
@@ -358,6 +385,10 @@ func (f *TypesFactoryImpl) NewAccountList() SpinnakerAccountList {
 	return f.Factories[LatestVersion].NewAccountList()
 }
 
+func (f *TypesFactoryImpl) NewAccountGroup() SpinnakerAccountGroup {
+	return f.Factories[LatestVersion].NewAccountGroup()
+}
+
 func (f *TypesFactoryImpl) GetGroupVersion() schema.GroupVersion {
 	return f.Factories[LatestVersion].GetGroupVersion()
 }
@@ -657,6 +688,46 @@ func (in *SpinnakerAccountSpec) DeepCopy() *SpinnakerAccountSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SpinnakerAccountGroupSpec) DeepCopyInto(out *SpinnakerAccountGroupSpec) {
+	*out = *in
+	if in.Accounts != nil {
+		in, out := &in.Accounts, &out.Accounts
+		*out = make([]SpinnakerAccountGroupMember, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SpinnakerAccountGroupSpec.
+func (in *SpinnakerAccountGroupSpec) DeepCopy() *SpinnakerAccountGroupSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SpinnakerAccountGroupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SpinnakerAccountGroupMember) DeepCopyInto(out *SpinnakerAccountGroupMember) {
+	*out = *in
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SpinnakerAccountGroupMember.
+func (in *SpinnakerAccountGroupMember) DeepCopy() *SpinnakerAccountGroupMember {
+	if in == nil {
+		return nil
+	}
+	out := new(SpinnakerAccountGroupMember)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SpinnakerAccountStatus) DeepCopyInto(out *SpinnakerAccountStatus) {
 	*out = *in
@@ -665,6 +736,13 @@ func (in *SpinnakerAccountStatus) DeepCopyInto(out *SpinnakerAccountStatus) {
 		*out = new(v1.Timestamp)
 		**out = **in
 	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
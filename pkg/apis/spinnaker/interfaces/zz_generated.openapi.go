@@ -438,12 +438,25 @@ func schema_pkg_apis_spinnaker_interfaces_SpinnakerAccountStatus(ref common.Refe
 							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.Timestamp"),
 						},
 					},
+					"conditions": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Conditions of the account, such as AccountValidatedCondition",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.Condition"),
+									},
+								},
+							},
+						},
+					},
 				},
 				Required: []string{"invalidReason", "lastValidatedAt"},
 			},
 		},
 		Dependencies: []string{
-			"k8s.io/apimachinery/pkg/apis/meta/v1.Timestamp"},
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Condition", "k8s.io/apimachinery/pkg/apis/meta/v1.Timestamp"},
 	}
 }
 
@@ -26,8 +26,8 @@ func (s *SpinnakerAccountList) GetItems() []interfaces.SpinnakerAccount {
 		return nil
 	} else {
 		var result []interfaces.SpinnakerAccount
-		for _, i := range s.Items {
-			result = append(result, &i)
+		for i := range s.Items {
+			result = append(result, &s.Items[i])
 		}
 		return result
 	}
@@ -45,3 +45,12 @@ func (s *SpinnakerAccountList) GetContinue() string               { return s.Con
 func (s *SpinnakerAccountList) SetContinue(c string)              { s.Continue = c }
 func (s *SpinnakerAccountList) GetRemainingItemCount() *int64     { return s.RemainingItemCount }
 func (s *SpinnakerAccountList) SetRemainingItemCount(c *int64)    { s.RemainingItemCount = c }
+
+var _ interfaces.SpinnakerAccountGroup = &SpinnakerAccountGroup{}
+
+func (s *SpinnakerAccountGroup) GetSpec() *interfaces.SpinnakerAccountGroupSpec {
+	return &s.Spec
+}
+func (s *SpinnakerAccountGroup) DeepCopySpinnakerAccountGroup() interfaces.SpinnakerAccountGroup {
+	return s.DeepCopy()
+}
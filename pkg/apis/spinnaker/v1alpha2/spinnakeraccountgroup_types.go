@@ -0,0 +1,31 @@
+package v1alpha2
+
+import (
+	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// SpinnakerAccountGroup is the Schema for the spinnakeraccountgroups API
+// +k8s:openapi-gen=true
+// +kubebuilder:resource:path=spinnakeraccountgroups,shortName=spinaccountgroup
+type SpinnakerAccountGroup struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec interfaces.SpinnakerAccountGroupSpec `json:"spec,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// SpinnakerAccountGroupList contains a list of SpinnakerAccountGroup
+type SpinnakerAccountGroupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SpinnakerAccountGroup `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SpinnakerAccountGroup{}, &SpinnakerAccountGroupList{})
+}
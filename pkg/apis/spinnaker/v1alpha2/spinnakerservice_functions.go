@@ -48,8 +48,8 @@ func (s *SpinnakerServiceList) GetItems() []interfaces.SpinnakerService {
 		return nil
 	} else {
 		var result []interfaces.SpinnakerService
-		for _, i := range s.Items {
-			result = append(result, &i)
+		for i := range s.Items {
+			result = append(result, &s.Items[i])
 		}
 		return result
 	}
@@ -74,6 +74,9 @@ func (f *TypesFactory) NewAccount() interfaces.SpinnakerAccount {
 func (f *TypesFactory) NewAccountList() interfaces.SpinnakerAccountList {
 	return &SpinnakerAccountList{}
 }
+func (f *TypesFactory) NewAccountGroup() interfaces.SpinnakerAccountGroup {
+	return &SpinnakerAccountGroup{}
+}
 func (f *TypesFactory) GetGroupVersion() schema.GroupVersion {
 	return SchemeGroupVersion
 }
@@ -2,16 +2,21 @@ package accountvalidating
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
-	"github.com/armory/spinnaker-operator/pkg/accounts"
+	"github.com/armory/spinnaker-operator/pkg/accounts/account"
+	"github.com/armory/spinnaker-operator/pkg/accounts/validator"
 	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
 	"github.com/armory/spinnaker-operator/pkg/controller/webhook"
-	"github.com/armory/spinnaker-operator/pkg/secrets"
+	"github.com/armory/spinnaker-operator/pkg/util"
+	admissionv1 "k8s.io/api/admission/v1"
 	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
@@ -24,9 +29,27 @@ var TypesFactory interfaces.TypesFactory
 
 // spinnakerValidatingController performs preflight checks
 type accountValidatingController struct {
-	client     client.Client
+	client client.Client
+	// apiReader reads from the API server, bypassing the manager's cache
+	apiReader client.Reader
+	// readClient reads from the server of readKubeconfigEnv, e.g. a read replica, nil if not set
+	readClient client.Reader
 	restConfig *rest.Config
 	decoder    *admission.Decoder
+	settings   settings
+	retries    retryTracker
+	// updateRates tracks the updates of accounts to throttle the ones updated in a loop
+	updateRates updateRateTracker
+	breakers    breakerSet
+	// cache is the manager's cache the client reads from, nil for clients reading from the API server
+	cache cacheSyncer
+	sync  syncTracker
+	// targetService overrides the SpinnakerService accounts are validated against
+	targetService *client.ObjectKey
+	// hooks are called with the decisions of the webhook, e.g. to publish them to an audit sink
+	hooks []decisionHook
+	// secretOverrides replace the value of the secrets used by accounts, see secrets.NewContextWithOverrides
+	secretOverrides map[string][]byte
 }
 
 // Implement all intended interfaces.
@@ -34,51 +57,215 @@ var _ admission.Handler = &accountValidatingController{}
 var _ inject.Config = &accountValidatingController{}
 var _ inject.Client = &accountValidatingController{}
 var _ admission.DecoderInjector = &accountValidatingController{}
-var log = logf.Log.WithName("accountvalidate")
+var _ inject.Cache = &accountValidatingController{}
+var log = util.VerboseLogger(logf.Log.WithName("accountvalidate"))
 
 // Add adds the validating admission controller
 func Add(m manager.Manager) error {
-	gvk, err := apiutil.GVKForObject(TypesFactory.NewAccount(), m.GetScheme())
+	s, err := loadSettings()
 	if err != nil {
 		return err
 	}
-	webhook.Register(gvk, "spinnakeraccounts", &accountValidatingController{})
+	gvk, groupGvk, err := waitForAccountTypes(m.GetScheme(), s.typesWaitTimeout)
+	if err != nil {
+		return err
+	}
+	v := &accountValidatingController{settings: s}
+	if s.readKubeconfig != "" {
+		if v.readClient, err = newReadClient(s.readKubeconfig, m.GetScheme(), m.GetRESTMapper()); err != nil {
+			return err
+		}
+	}
+	v.hooks = decisionHooks(s)
+	for _, h := range v.hooks {
+		if err := m.Add(h); err != nil {
+			return err
+		}
+	}
+	if len(s.referencingKinds) > 0 {
+		webhook.RegisterWithDeletes(gvk, "spinnakeraccounts", v)
+	} else {
+		webhook.Register(gvk, "spinnakeraccounts", v)
+	}
+	webhook.Register(groupGvk, "spinnakeraccountgroups", v)
 	return nil
 }
 
 // Handle is the entry point for spinnaker preflight validations
 func (v *accountValidatingController) Handle(ctx context.Context, req admission.Request) admission.Response {
+	ctx, probes := withProbeTracker(ctx)
+	ctx, checks := withCheckTracker(ctx, traceFor(req))
+	ctx = withSeverities(ctx)
+	r := v.responseWithSeverity(ctx, v.handle(ctx, req))
+	if checks.trace != nil {
+		r = withTrace(req, r, checks.trace)
+	}
+	r = withChecks(withCacheability(r, !probes.get()), checks.list())
+	v.recordUpdate(req, r)
+	for _, h := range v.hooks {
+		h.decided(req, r)
+	}
+	return r
+}
+
+func (v *accountValidatingController) handle(ctx context.Context, req admission.Request) admission.Response {
 	log.Info(fmt.Sprintf("Handling admission request for: %s", req.AdmissionRequest.Kind.Kind))
-	gv := TypesFactory.GetGroupVersion()
-	acc := TypesFactory.NewAccount()
+	log.V(2).Info("Admission request", "uid", req.UID, "operation", req.Operation, "object", util.RedactJSON(req.Object.Raw))
+	if !isAccountRequest(req) && !isAccountGroupRequest(req) {
+		return admission.ValidationResponse(true, "")
+	}
+	if req.DryRun != nil && *req.DryRun {
+		ctx = withDryRun(ctx)
+	}
+	if err := v.checkObjectSize(req); err != nil {
+		return v.respond(req, nil, err)
+	}
+	if err := v.checkNamespacePolicy(req); err != nil {
+		return v.respond(req, nil, err)
+	}
+	if !v.cacheSynced(ctx) {
+		return v.respond(req, nil, unavailable(errCacheNotSynced))
+	}
+	if msg, err := v.maintenance(req.Namespace); err != nil {
+		return v.respond(req, nil, internalError(err))
+	} else if msg != "" {
+		log.Info("Skipping validation during maintenance", "namespace", req.Namespace, "name", req.Name)
+		return v.respond(req, []string{msg}, nil)
+	}
+	if req.Operation == admissionv1.Delete {
+		return v.handleDelete(ctx, req)
+	}
+	if isAccountGroupRequest(req) {
+		return v.handleGroup(ctx, req)
+	}
 
-	if "SpinnakerAccount" == req.AdmissionRequest.Kind.Kind &&
-		gv.Group == req.AdmissionRequest.Kind.Group &&
-		gv.Version == req.AdmissionRequest.Kind.Version {
+	acc := TypesFactory.NewAccount()
+	if err := v.decoder.Decode(req, acc); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
 
-		if err := v.decoder.Decode(req, acc); err != nil {
+	if req.Operation == admissionv1.Update {
+		old := TypesFactory.NewAccount()
+		if err := v.decoder.DecodeRaw(req.OldObject, old); err != nil {
 			return admission.Errored(http.StatusBadRequest, err)
 		}
-
-		accType, err := accounts.GetType(acc.GetSpec().Type)
-		if err != nil {
-			return admission.Errored(http.StatusBadRequest, err)
+		if err := checkIdentity(old, acc); err != nil {
+			return v.respond(req, nil, err)
 		}
-
-		spinAccount, err := accType.FromCRD(acc)
-		if err != nil {
-			return admission.Errored(http.StatusBadRequest, err)
+		if err := v.checkUpdateRate(req); err != nil {
+			return v.respond(req, nil, err)
+		}
+		if v.settings.immutable {
+			if err := checkImmutable(old, acc); err != nil {
+				return v.respond(req, nil, err)
+			}
 		}
+		ctx = validator.WithPrevious(ctx, old)
+	}
+
+	warnings, err := v.validate(ctx, acc)
+	if err != nil && req.Operation == admissionv1.Update && v.settings.isGrandfathered(acc.GetCreationTimestamp().Time) {
+		log.Info("Allowing update of account created before the enforcement cutoff", "account", acc.GetName(), "error", err.Error())
+		warnings = append(warnings, fmt.Sprintf("account %s was created before the enforcement cutoff %s (%s), allowing the update although it fails validation: %s",
+			acc.GetName(), v.settings.enforcementCutoff.UTC().Format(time.RFC3339), enforcementCutoffEnv, err.Error()))
+		err = nil
+	}
+	return v.respond(req, warnings, err)
+}
+
+// ValidateAccountObject runs the validations of the admission webhook on the given account, returning the warnings
+// raised. Settings are read from the environment as in the webhook.
+func ValidateAccountObject(ctx context.Context, c client.Client, restConfig *rest.Config, acc interfaces.SpinnakerAccount) ([]string, error) {
+	s, err := loadSettings()
+	if err != nil {
+		return nil, err
+	}
+	v := &accountValidatingController{client: c, restConfig: restConfig, settings: s}
+	return v.validate(ctx, acc)
+}
+
+// ValidateAgainstService validates the account as if it was attached to the given SpinnakerService, using the
+// service's configuration instead of the configuration of the service in the account's namespace.
+func ValidateAgainstService(ctx context.Context, c client.Client, restConfig *rest.Config, acc interfaces.SpinnakerAccount, serviceRef client.ObjectKey) ([]string, error) {
+	s, err := loadSettings()
+	if err != nil {
+		return nil, err
+	}
+	v := &accountValidatingController{client: c, restConfig: restConfig, settings: s, targetService: &serviceRef}
+	return v.validate(ctx, acc)
+}
+
+// ValidateWithSecretOverride validates the account using the given secret values instead of the values of its
+// secrets, e.g. to check new credentials before rotating them. Overrides are keyed by the secret reference as
+// written in the account, or by <name>/<key> for Kubernetes secrets of the account's namespace.
+func ValidateWithSecretOverride(ctx context.Context, c client.Client, restConfig *rest.Config, acc interfaces.SpinnakerAccount, overrides map[string][]byte) ([]string, error) {
+	s, err := loadSettings()
+	if err != nil {
+		return nil, err
+	}
+	v := &accountValidatingController{client: c, restConfig: restConfig, settings: s, secretOverrides: overrides}
+	return v.validate(ctx, acc)
+}
+
+// ValidationOptions returns the options of account validators. Settings are read from the environment as in the
+// webhook.
+func ValidationOptions() (account.ValidationOptions, error) {
+	s, err := loadSettings()
+	if err != nil {
+		return account.ValidationOptions{}, err
+	}
+	return s.validationOptions(), nil
+}
+
+// BackgroundOptions returns the options of the validations left to the account controller in async mode. Settings
+// are read from the environment as in the webhook.
+func BackgroundOptions() (validator.Options, error) {
+	s, err := loadSettings()
+	if err != nil {
+		return validator.Options{}, err
+	}
+	return validator.Options{
+		SecretNamespace: s.secretNamespace,
+		Validation:      s.validationOptions(),
+	}, nil
+}
 
-		av := spinAccount.NewValidator()
-		ctx = secrets.NewContext(ctx, v.restConfig, acc.GetNamespace())
-		defer secrets.Cleanup(ctx)
+func (v *accountValidatingController) validate(ctx context.Context, acc interfaces.SpinnakerAccount) ([]string, error) {
+	if !v.settings.isTypeAllowed(string(acc.GetSpec().Type)) {
+		return nil, rejected(ReasonAccountTypeNotAllowed, fmt.Sprintf("account type %s is not allowed in this cluster, allowed types are %s", acc.GetSpec().Type, strings.Join(v.settings.allowedTypes, ", ")))
+	}
 
-		if err := av.Validate(nil, v.client, ctx, log); err != nil {
-			return admission.Errored(http.StatusUnprocessableEntity, err)
+	opts := v.settings.validationOptions()
+	opts.APIReader = v.apiReader
+	lib := validator.New(validator.Options{
+		RestConfig:      v.restConfig,
+		Timeout:         v.settings.timeout,
+		SecretNamespace: v.settings.secretNamespace,
+		SecretOverrides: v.secretOverrides,
+		Validation:      opts,
+		Checks:          []validator.Check{v.runStages},
+		Log:             log,
+	})
+	res, err := lib.Validate(ctx, acc)
+	if res.Probed {
+		recordProbed(ctx)
+		recordCheck(ctx, connectivityCheck)
+	}
+	if errors.Is(err, validator.ErrUnsupportedType) {
+		return nil, badRequest(err)
+	}
+	return res.Warnings, err
+}
+
+// runStages runs the validation stages of the settings, stopping at the first error not downgraded by its severity
+func (v *accountValidatingController) runStages(ctx context.Context, lr *validator.Run) error {
+	r := &validationRun{Run: lr}
+	for _, stage := range v.settings.stages {
+		if err := v.withSeverity(ctx, validationStages[stage](v, ctx, r)); err != nil {
+			return err
 		}
 	}
-	return admission.ValidationResponse(true, "")
+	return nil
 }
 
 // InjectClient injects the client.
@@ -87,12 +274,36 @@ func (v *accountValidatingController) InjectClient(c client.Client) error {
 	return nil
 }
 
+// InjectAPIReader injects the reader bypassing the manager's cache.
+func (v *accountValidatingController) InjectAPIReader(r client.Reader) error {
+	v.apiReader = r
+	return nil
+}
+
+// reader returns the reader of the checks comparing the account with other objects: the read client if configured,
+// the API reader if uncached reads are enabled, trading latency for up-to-date objects, and the client otherwise
+func (v *accountValidatingController) reader() client.Reader {
+	if v.readClient != nil {
+		return v.readClient
+	}
+	if v.settings.uncachedReads && v.apiReader != nil {
+		return v.apiReader
+	}
+	return v.client
+}
+
 // InjectDecoder injects the decoder.
 func (v *accountValidatingController) InjectDecoder(d *admission.Decoder) error {
 	v.decoder = d
 	return nil
 }
 
+// InjectCache injects the manager's cache.
+func (v *accountValidatingController) InjectCache(c cache.Cache) error {
+	v.cache = c
+	return nil
+}
+
 // InjectConfig injects the rest config for creating raw kubernetes clients.
 func (v *accountValidatingController) InjectConfig(c *rest.Config) error {
 	v.restConfig = c
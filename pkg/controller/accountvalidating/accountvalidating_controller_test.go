@@ -0,0 +1,426 @@
+package accountvalidating
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/armory/spinnaker-operator/pkg/accounts"
+	"github.com/armory/spinnaker-operator/pkg/apis"
+	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
+	"github.com/armory/spinnaker-operator/pkg/controller/accountvalidating/accountvalidatingtest"
+	"github.com/armory/spinnaker-operator/pkg/controller/webhook"
+	"github.com/armory/spinnaker-operator/pkg/test"
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func init() {
+	TypesFactory = test.TypesFactory
+}
+
+func newTestController(t *testing.T, objs ...client.Object) *accountValidatingController {
+	s := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+	if err := apis.AddToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+	d, err := admission.NewDecoder(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	st, err := loadSettings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &accountValidatingController{
+		client:   fake.NewClientBuilder().WithScheme(s).WithObjects(objs...).Build(),
+		decoder:  d,
+		settings: st,
+	}
+}
+
+// newFakeKubernetesAPI returns a server answering the requests made by the Kubernetes account validator
+func newFakeKubernetesAPI(t *testing.T) *httptest.Server {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, "/selfsubjectaccessreviews") {
+			fmt.Fprint(w, `{"kind":"SelfSubjectAccessReview","apiVersion":"authorization.k8s.io/v1","status":{"allowed":true}}`)
+			return
+		}
+		fmt.Fprint(w, `{"kind":"List","apiVersion":"v1","metadata":{},"items":[]}`)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// kubernetesAccount returns a Kubernetes account with an inlined kubeconfig pointing to the given server
+func kubernetesAccount(t *testing.T, name, server string, settings string) interfaces.SpinnakerAccount {
+	acc := test.TypesFactory.NewAccount()
+	test.ReadYamlString([]byte(fmt.Sprintf(`
+apiVersion: spinnaker.io/v1alpha2
+kind: SpinnakerAccount
+metadata:
+  name: %s
+  namespace: ns1
+spec:
+  enabled: true
+  type: Kubernetes
+  kubernetes:
+    kubeconfig:
+      apiVersion: v1
+      kind: Config
+      current-context: ctx
+      clusters:
+      - name: cluster
+        cluster:
+          server: %s
+      contexts:
+      - name: ctx
+        context:
+          cluster: cluster
+          user: user
+      users:
+      - name: user
+        user:
+          token: token
+  settings:
+    %s
+`, name, server, settings)), acc, t)
+	return acc
+}
+
+func spinnakerService(t *testing.T, version string) interfaces.SpinnakerService {
+	svc := test.TypesFactory.NewService()
+	test.ReadYamlString([]byte(fmt.Sprintf(`
+apiVersion: spinnaker.io/v1alpha2
+kind: SpinnakerService
+metadata:
+  name: spinnaker
+  namespace: ns1
+spec:
+  spinnakerConfig:
+    config:
+      version: %s
+`, version)), svc, t)
+	return svc
+}
+
+func TestHandleVersionCompatibility(t *testing.T) {
+	api := newFakeKubernetesAPI(t)
+	acc := kubernetesAccount(t, "kube", api.URL, "cacheAllApplicationRelationships: true")
+
+	t.Run("old Spinnaker version", func(t *testing.T) {
+		v := newTestController(t, spinnakerService(t, "1.26.0"))
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountAdmissionRequest(acc, admissionv1.Create))
+		assert.False(t, r.Allowed)
+		assert.Equal(t, ReasonIncompatibleVersion, r.Result.Reason)
+		assert.Contains(t, r.Result.Message, "requires Spinnaker 1.27.0 or later")
+	})
+
+	t.Run("recent Spinnaker version", func(t *testing.T) {
+		v := newTestController(t, spinnakerService(t, "1.28.1"))
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountAdmissionRequest(acc, admissionv1.Create))
+		assert.True(t, r.Allowed)
+	})
+
+	t.Run("no SpinnakerService", func(t *testing.T) {
+		v := newTestController(t)
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountAdmissionRequest(acc, admissionv1.Create))
+		assert.True(t, r.Allowed)
+	})
+}
+
+func TestHandleAllowedAccountTypes(t *testing.T) {
+	api := newFakeKubernetesAPI(t)
+	acc := kubernetesAccount(t, "kube", api.URL, "{}")
+
+	cases := []struct {
+		name    string
+		allowed string
+		admit   bool
+	}{
+		{"allowed type", "Docker, kubernetes", true},
+		{"disallowed type", "Docker,AWS", false},
+		{"empty allowlist", "", true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			t.Setenv(allowedAccountTypesEnv, c.allowed)
+			v := newTestController(t)
+			s, err := loadSettings()
+			if !assert.Nil(t, err) {
+				return
+			}
+			v.settings = s
+			r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountAdmissionRequest(acc, admissionv1.Create))
+			assert.Equal(t, c.admit, r.Allowed)
+			if !c.admit {
+				assert.Equal(t, ReasonAccountTypeNotAllowed, r.Result.Reason)
+				assert.Equal(t, "account type Kubernetes is not allowed in this cluster, allowed types are Docker, AWS", r.Result.Message)
+			}
+		})
+	}
+}
+
+func TestHandleConnectivity(t *testing.T) {
+	requests := 0
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		http.Error(w, "forbidden", http.StatusForbidden)
+	}))
+	defer api.Close()
+	acc := kubernetesAccount(t, "kube", api.URL, "{}")
+
+	t.Run("connectivity enabled", func(t *testing.T) {
+		v := newTestController(t)
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountAdmissionRequest(acc, admissionv1.Create))
+		assert.False(t, r.Allowed)
+		assert.Contains(t, r.Result.Message, "error listing namespaces")
+	})
+
+	t.Run("connectivity disabled", func(t *testing.T) {
+		requests = 0
+		t.Setenv(connectivityEnv, "false")
+		v := newTestController(t)
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountAdmissionRequest(acc, admissionv1.Create))
+		assert.True(t, r.Allowed)
+		assert.Equal(t, 0, requests)
+	})
+
+	t.Run("provider connectivity disabled", func(t *testing.T) {
+		requests = 0
+		t.Setenv(accounts.ProviderConnectivityEnvPrefix+"KUBERNETES", "false")
+		v := newTestController(t)
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountAdmissionRequest(acc, admissionv1.Create))
+		assert.True(t, r.Allowed)
+		assert.Equal(t, 0, requests)
+	})
+
+	t.Run("other provider connectivity disabled", func(t *testing.T) {
+		requests = 0
+		t.Setenv(accounts.ProviderConnectivityEnvPrefix+"DOCKER", "false")
+		v := newTestController(t)
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountAdmissionRequest(acc, admissionv1.Create))
+		assert.False(t, r.Allowed)
+		assert.NotEqual(t, 0, requests)
+	})
+}
+
+func TestHandleReservedMetadata(t *testing.T) {
+	api := newFakeKubernetesAPI(t)
+	reserved := kubernetesAccount(t, "kube", api.URL, "{}")
+	reserved.SetLabels(map[string]string{"app": "kube", "moniker.spinnaker.io/application": "kube"})
+	reserved.SetAnnotations(map[string]string{"spinnaker.io/managed": "true"})
+	clean := kubernetesAccount(t, "kube", api.URL, "{}")
+	clean.SetLabels(map[string]string{"app": "kube", "team": "spinnaker.io"})
+
+	t.Run("reserved keys warn", func(t *testing.T) {
+		v := newTestController(t)
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountAdmissionRequest(reserved, admissionv1.Create))
+		assert.True(t, r.Allowed)
+		assert.Equal(t, []string{"account kube uses keys reserved by Spinnaker: moniker.spinnaker.io/application, spinnaker.io/managed"}, r.Warnings)
+	})
+
+	t.Run("reserved keys denied in strict mode", func(t *testing.T) {
+		t.Setenv(strictEnv, "true")
+		v := newTestController(t)
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountAdmissionRequest(reserved, admissionv1.Create))
+		assert.False(t, r.Allowed)
+		assert.Equal(t, ReasonReservedMetadataKey, r.Result.Reason)
+	})
+
+	t.Run("clean metadata", func(t *testing.T) {
+		t.Setenv(strictEnv, "true")
+		v := newTestController(t)
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountAdmissionRequest(clean, admissionv1.Create))
+		assert.True(t, r.Allowed)
+		assert.Empty(t, r.Warnings)
+	})
+
+	t.Run("custom prefixes", func(t *testing.T) {
+		t.Setenv(reservedPrefixesEnv, "example.com/")
+		v := newTestController(t)
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountAdmissionRequest(reserved, admissionv1.Create))
+		assert.True(t, r.Allowed)
+		assert.Empty(t, r.Warnings)
+	})
+}
+
+func TestHandleAsyncValidation(t *testing.T) {
+	requests := 0
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		http.Error(w, "forbidden", http.StatusForbidden)
+	}))
+	defer api.Close()
+	acc := kubernetesAccount(t, "kube", api.URL, "{}")
+
+	t.Setenv(accounts.AsyncValidationEnv, "true")
+	v := newTestController(t)
+	r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountAdmissionRequest(acc, admissionv1.Create))
+	assert.True(t, r.Allowed)
+	assert.Equal(t, []string{"account kube will be validated in the background, see its Validated condition"}, r.Warnings)
+	assert.Equal(t, 0, requests)
+}
+
+func TestHandleRequiredFields(t *testing.T) {
+	acc := test.TypesFactory.NewAccount()
+	test.ReadYamlString([]byte(`
+apiVersion: spinnaker.io/v1alpha2
+kind: SpinnakerAccount
+metadata:
+  name: kube
+  namespace: ns1
+spec:
+  enabled: true
+  type: Kubernetes
+  kubernetes:
+    kubeconfigSecret:
+      name: kubeconfig
+`), acc, t)
+
+	v := newTestController(t)
+	r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountAdmissionRequest(acc, admissionv1.Create))
+	assert.False(t, r.Allowed)
+	assert.Equal(t, ReasonMissingRequiredField, r.Result.Reason)
+	assert.Equal(t, `missing required field: account "kube": spec.kubernetes.kubeconfigSecret.key: Required value
+suggested fix (JSON patch): [{"op":"add","path":"/spec/kubernetes/kubeconfigSecret/key","value":"<key>"}]`, r.Result.Message)
+}
+
+func TestHandleSuggestsEndpointScheme(t *testing.T) {
+	acc := kubernetesAccount(t, "kube", "mycluster.com:6443", "{}")
+	v := newTestController(t)
+	r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountAdmissionRequest(acc, admissionv1.Create))
+	assert.False(t, r.Allowed)
+	assert.Equal(t, ReasonInvalidEndpoint, r.Result.Reason)
+	assert.Equal(t, `account "kube": invalid endpoint spec.kubernetes.kubeconfig.clusters[cluster].cluster.server "mycluster.com:6443": expected an absolute URL such as https://host:port
+suggested fix (JSON patch): [{"op":"replace","path":"/spec/kubernetes/kubeconfig/clusters/0/cluster/server","value":"https://mycluster.com:6443"}]`, r.Result.Message)
+}
+
+func TestHandleCacheability(t *testing.T) {
+	api := newFakeKubernetesAPI(t)
+	acc := kubernetesAccount(t, "kube", api.URL, "{}")
+
+	t.Run("connectivity", func(t *testing.T) {
+		v := newTestController(t)
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountAdmissionRequest(acc, admissionv1.Create))
+		assert.True(t, r.Allowed)
+		assert.Equal(t, "false", r.AuditAnnotations[webhook.CacheableAnnotation])
+	})
+
+	t.Run("structural only", func(t *testing.T) {
+		t.Setenv(connectivityEnv, "false")
+		v := newTestController(t)
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountAdmissionRequest(acc, admissionv1.Create))
+		assert.True(t, r.Allowed)
+		assert.Equal(t, "true", r.AuditAnnotations[webhook.CacheableAnnotation])
+	})
+}
+
+func TestValidateAgainstService(t *testing.T) {
+	service := func(ns string, kubernetesEnabled bool) interfaces.SpinnakerService {
+		svc := test.TypesFactory.NewService()
+		test.ReadYamlString([]byte(fmt.Sprintf(`
+apiVersion: spinnaker.io/v1alpha2
+kind: SpinnakerService
+metadata:
+  name: spinnaker
+  namespace: %s
+spec:
+  spinnakerConfig:
+    config:
+      version: 1.28.1
+      security:
+        authz:
+          enabled: true
+      providers:
+        kubernetes:
+          enabled: %t
+`, ns, kubernetesEnabled)), svc, t)
+		return svc
+	}
+	api := newFakeKubernetesAPI(t)
+	acc := kubernetesAccount(t, "kube", api.URL, "{}")
+	acc.GetSpec().Permissions = interfaces.AccountPermissions{"READ": []string{"dev"}}
+	t.Setenv(connectivityEnv, "false")
+	c := newTestController(t, service("staging", true), service("prod", false)).client
+
+	w, err := ValidateAgainstService(context.TODO(), c, nil, acc, client.ObjectKey{Namespace: "staging", Name: "spinnaker"})
+	assert.Nil(t, err)
+	assert.Empty(t, w)
+
+	w, err = ValidateAgainstService(context.TODO(), c, nil, acc, client.ObjectKey{Namespace: "prod", Name: "spinnaker"})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"account kube grants READ permissions on provider Kubernetes which is disabled (providers.kubernetes.enabled)"}, w)
+
+	_, err = ValidateAgainstService(context.TODO(), c, nil, acc, client.ObjectKey{Namespace: "qa", Name: "spinnaker"})
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "unable to get SpinnakerService qa/spinnaker")
+	}
+}
+
+func TestHandleSettingBounds(t *testing.T) {
+	api := newFakeKubernetesAPI(t)
+	t.Setenv(connectivityEnv, "false")
+	msg := `value out of range: account "kube": spec.settings.cacheIntervalSeconds: Invalid value: 86400: must be between 10 and 3600`
+
+	t.Run("within range", func(t *testing.T) {
+		v := newTestController(t)
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountAdmissionRequest(kubernetesAccount(t, "kube", api.URL, "cacheIntervalSeconds: 60"), admissionv1.Create))
+		assert.True(t, r.Allowed)
+		assert.Empty(t, r.Warnings)
+	})
+
+	t.Run("out of range", func(t *testing.T) {
+		v := newTestController(t)
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountAdmissionRequest(kubernetesAccount(t, "kube", api.URL, "cacheIntervalSeconds: 86400"), admissionv1.Create))
+		assert.True(t, r.Allowed)
+		assert.Equal(t, []string{msg}, r.Warnings)
+	})
+
+	t.Run("strict", func(t *testing.T) {
+		t.Setenv(strictEnv, "true")
+		v := newTestController(t)
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountAdmissionRequest(kubernetesAccount(t, "kube", api.URL, "cacheIntervalSeconds: 86400"), admissionv1.Create))
+		assert.False(t, r.Allowed)
+		assert.Equal(t, ReasonValueOutOfRange, r.Result.Reason)
+		assert.Equal(t, msg, r.Result.Message)
+	})
+}
+
+func TestBackgroundOptions(t *testing.T) {
+	t.Setenv(connectivityEnv, "false")
+	t.Setenv(accounts.ProviderConnectivityEnvPrefix+"KUBERNETES", "true")
+	t.Setenv(expiryWarningWindowEnv, "48h")
+	t.Setenv(strictEnv, "true")
+	t.Setenv(secretNamespaceEnv, "shared")
+
+	opts, err := BackgroundOptions()
+	if assert.Nil(t, err) {
+		assert.Equal(t, "shared", opts.SecretNamespace)
+		assert.False(t, opts.Validation.Connectivity)
+		assert.Equal(t, map[string]bool{"kubernetes": true}, opts.Validation.ProviderConnectivity)
+		assert.Equal(t, 48*time.Hour, opts.Validation.ExpiryWarningWindow)
+		assert.True(t, opts.Validation.Strict)
+	}
+	validation, err := ValidationOptions()
+	if assert.Nil(t, err) {
+		assert.Equal(t, opts.Validation, validation)
+	}
+
+	t.Setenv(expiryWarningWindowEnv, "soon")
+	_, err = BackgroundOptions()
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,97 @@
+package accountvalidatingtest
+
+import (
+	"encoding/json"
+
+	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
+	"github.com/armory/spinnaker-operator/pkg/test"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	accountKind      = "SpinnakerAccount"
+	accountGroupKind = "SpinnakerAccountGroup"
+)
+
+// admissionObject is an object sent to the account validating webhook
+type admissionObject interface {
+	metav1.Object
+	runtime.Object
+}
+
+// NewAccountAdmissionRequest builds an admission request for the given account the way the API server
+// would send it to the account validating webhook.
+func NewAccountAdmissionRequest(acc interfaces.SpinnakerAccount, op admissionv1.Operation) admission.Request {
+	return newAdmissionRequest(acc, accountKind, "spinnakeraccounts", op)
+}
+
+// NewAccountUpdateAdmissionRequest builds an admission request updating old to acc.
+func NewAccountUpdateAdmissionRequest(old, acc interfaces.SpinnakerAccount) admission.Request {
+	req := newAdmissionRequest(acc, accountKind, "spinnakeraccounts", admissionv1.Update)
+	o, _ := withKind(old, accountKind)
+	req.OldObject = rawExtension(o)
+	return req
+}
+
+// NewAccountDeleteAdmissionRequest builds an admission request deleting acc, sent with the old object only.
+func NewAccountDeleteAdmissionRequest(acc interfaces.SpinnakerAccount) admission.Request {
+	req := newAdmissionRequest(acc, accountKind, "spinnakeraccounts", admissionv1.Delete)
+	req.OldObject = req.Object
+	req.Object = runtime.RawExtension{}
+	return req
+}
+
+// NewAccountGroupAdmissionRequest builds an admission request for the given account group the way the API server
+// would send it to the account validating webhook.
+func NewAccountGroupAdmissionRequest(g interfaces.SpinnakerAccountGroup, op admissionv1.Operation) admission.Request {
+	return newAdmissionRequest(g, accountGroupKind, "spinnakeraccountgroups", op)
+}
+
+func newAdmissionRequest(obj admissionObject, kind, resource string, op admissionv1.Operation) admission.Request {
+	o, gvk := withKind(obj, kind)
+	return admission.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			UID: types.UID(obj.GetNamespace() + "-" + obj.GetName()),
+			Kind: metav1.GroupVersionKind{
+				Group:   gvk.Group,
+				Version: gvk.Version,
+				Kind:    gvk.Kind,
+			},
+			Resource: metav1.GroupVersionResource{
+				Group:    gvk.Group,
+				Version:  gvk.Version,
+				Resource: resource,
+			},
+			Name:      obj.GetName(),
+			Namespace: obj.GetNamespace(),
+			Operation: op,
+			Object:    rawExtension(o),
+		},
+	}
+}
+
+// withKind returns a copy of the object with the kind set if it was built without apiVersion and kind, along with
+// the object's kind. The given object is left untouched.
+func withKind(obj runtime.Object, kind string) (runtime.Object, schema.GroupVersionKind) {
+	obj = obj.DeepCopyObject()
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	if gvk.Empty() {
+		gvk = test.TypesFactory.GetGroupVersion().WithKind(kind)
+		obj.GetObjectKind().SetGroupVersionKind(gvk)
+	}
+	return obj, gvk
+}
+
+func rawExtension(obj runtime.Object) runtime.RawExtension {
+	b, err := json.Marshal(obj)
+	if err != nil {
+		// Accounts are plain structs, this can only fail on programming errors
+		panic(err)
+	}
+	return runtime.RawExtension{Raw: b}
+}
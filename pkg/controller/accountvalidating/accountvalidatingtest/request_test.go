@@ -0,0 +1,57 @@
+package accountvalidatingtest
+
+import (
+	"testing"
+
+	"github.com/armory/spinnaker-operator/pkg/apis"
+	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
+	"github.com/armory/spinnaker-operator/pkg/test"
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func TestNewAccountAdmissionRequest(t *testing.T) {
+	acc := test.TypesFactory.NewAccount()
+	test.ReadYamlString([]byte(`
+metadata:
+  name: account1
+  namespace: ns1
+spec:
+  enabled: true
+  type: Kubernetes
+  kubernetes:
+    useServiceAccount: true
+  settings:
+    namespaces:
+    - ns2
+`), acc, t)
+
+	req := NewAccountAdmissionRequest(acc, admissionv1.Create)
+	assert.Equal(t, "SpinnakerAccount", req.Kind.Kind)
+	assert.Equal(t, "spinnaker.io", req.Kind.Group)
+	assert.Equal(t, "v1alpha2", req.Kind.Version)
+	assert.Equal(t, "account1", req.Name)
+	assert.Equal(t, "ns1", req.Namespace)
+	assert.Equal(t, admissionv1.Create, req.Operation)
+	// the fixture isn't modified
+	assert.True(t, acc.GetObjectKind().GroupVersionKind().Empty())
+
+	s := runtime.NewScheme()
+	if !assert.Nil(t, apis.AddToScheme(s)) {
+		return
+	}
+	d, err := admission.NewDecoder(s)
+	if !assert.Nil(t, err) {
+		return
+	}
+	decoded := test.TypesFactory.NewAccount()
+	if !assert.Nil(t, d.Decode(req, decoded)) {
+		return
+	}
+	assert.Equal(t, "account1", decoded.GetName())
+	assert.Equal(t, interfaces.KubernetesAccountType, decoded.GetSpec().Type)
+	assert.True(t, decoded.GetSpec().Kubernetes.UseServiceAccount)
+	assert.Equal(t, []interface{}{"ns2"}, decoded.GetSpec().Settings["namespaces"])
+}
@@ -0,0 +1,145 @@
+package accountvalidating
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/armory/spinnaker-operator/pkg/util"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	// auditPublishTimeout bounds the time spent publishing a single record
+	auditPublishTimeout   = 10 * time.Second
+	defaultAuditQueueSize = 1000
+	// kafkaRESTContentType is the content type of JSON records sent to a Kafka REST proxy
+	kafkaRESTContentType = "application/vnd.kafka.json.v2+json"
+)
+
+// auditRecord is the record of a validation decision published to the audit sink
+type auditRecord struct {
+	Timestamp time.Time `json:"timestamp"`
+	UID       string    `json:"uid"`
+	Kind      string    `json:"kind"`
+	Name      string    `json:"name"`
+	Namespace string    `json:"namespace"`
+	Operation string    `json:"operation"`
+	DryRun    bool      `json:"dryRun,omitempty"`
+	// Outcome is allowed or denied
+	Outcome  string   `json:"outcome"`
+	Code     int32    `json:"code,omitempty"`
+	Reason   string   `json:"reason,omitempty"`
+	Message  string   `json:"message,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
+	User     string   `json:"user"`
+	Groups   []string `json:"groups,omitempty"`
+}
+
+func newAuditRecord(req admission.Request, r admission.Response) auditRecord {
+	rec := auditRecord{
+		Timestamp: time.Now().UTC(),
+		UID:       string(req.UID),
+		Kind:      req.Kind.Kind,
+		Name:      req.Name,
+		Namespace: req.Namespace,
+		Operation: string(req.Operation),
+		DryRun:    req.DryRun != nil && *req.DryRun,
+		Outcome:   "allowed",
+		Warnings:  r.Warnings,
+		User:      req.UserInfo.Username,
+		Groups:    req.UserInfo.Groups,
+	}
+	if !r.Allowed {
+		rec.Outcome = "denied"
+	}
+	if r.Result != nil {
+		rec.Code = r.Result.Code
+		rec.Reason = string(r.Result.Reason)
+		rec.Message = r.Result.Message
+	}
+	return rec
+}
+
+// auditSink publishes validation decisions in the background. Records are dropped when the queue is full so
+// admission responses never wait on the sink.
+type auditSink struct {
+	url string
+	// topic sends records to the topic of a Kafka REST proxy at url instead of posting them as is
+	topic string
+	queue chan auditRecord
+}
+
+func newAuditSink(s settings) *auditSink {
+	if s.auditSinkURL == "" {
+		return nil
+	}
+	return &auditSink{url: s.auditSinkURL, topic: s.auditKafkaTopic, queue: make(chan auditRecord, s.auditQueueSize)}
+}
+
+// decided enqueues the decision
+func (a *auditSink) decided(req admission.Request, r admission.Response) {
+	select {
+	case a.queue <- newAuditRecord(req, r):
+	default:
+		log.Info("Audit queue is full, dropping decision record", "uid", req.UID, "namespace", req.Namespace, "name", req.Name)
+	}
+}
+
+// Start publishes queued records until the context is done
+func (a *auditSink) Start(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case rec := <-a.queue:
+			if err := a.publish(ctx, rec); err != nil {
+				log.Error(err, "Unable to publish decision record", "uid", rec.UID, "namespace", rec.Namespace, "name", rec.Name)
+			}
+		}
+	}
+}
+
+// NeedLeaderElection returns false, all replicas answer admission requests
+func (a *auditSink) NeedLeaderElection() bool {
+	return false
+}
+
+func (a *auditSink) publish(ctx context.Context, rec auditRecord) error {
+	ctx, cancel := context.WithTimeout(ctx, auditPublishTimeout)
+	defer cancel()
+	u, contentType := a.url, "application/json"
+	var payload interface{} = rec
+	if a.topic != "" {
+		u = strings.TrimSuffix(a.url, "/") + "/topics/" + url.PathEscape(a.topic)
+		contentType = kafkaRESTContentType
+		payload = map[string]interface{}{
+			"records": []map[string]interface{}{{"key": rec.Namespace + "/" + rec.Name, "value": rec}},
+		}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	svc := &util.HttpService{}
+	req, err := svc.Request(ctx, util.POST, u, nil, map[string]string{"Content-Type": contentType}, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	resp, err := svc.Execute(ctx, req)
+	if err != nil {
+		return err
+	}
+	if _, err := svc.ParseResponseBody(resp.Body); err != nil {
+		return err
+	}
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("%s returned %d", u, resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package accountvalidating
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/armory/spinnaker-operator/pkg/controller/accountvalidating/accountvalidatingtest"
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+)
+
+// newMockAuditSink returns a sink forwarding the records it receives to the returned channel
+func newMockAuditSink(t *testing.T) (*httptest.Server, chan auditRecord) {
+	records := make(chan auditRecord, 10)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var rec auditRecord
+		if err := json.NewDecoder(r.Body).Decode(&rec); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		records <- rec
+	}))
+	t.Cleanup(s.Close)
+	return s, records
+}
+
+func TestHandleAudit(t *testing.T) {
+	api := newFakeKubernetesAPI(t)
+	server, records := newMockAuditSink(t)
+	t.Setenv(auditSinkURLEnv, server.URL)
+	t.Setenv(allowedAccountTypesEnv, "AWS")
+	v := newTestController(t)
+	sink := newAuditSink(v.settings)
+	v.hooks = []decisionHook{sink}
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	go sink.Start(ctx)
+
+	req := accountvalidatingtest.NewAccountAdmissionRequest(kubernetesAccount(t, "kube", api.URL, "{}"), admissionv1.Create)
+	req.UserInfo = authenticationv1.UserInfo{Username: "jane", Groups: []string{"platform"}}
+	r := v.Handle(context.TODO(), req)
+	assert.False(t, r.Allowed)
+
+	select {
+	case rec := <-records:
+		assert.Equal(t, "SpinnakerAccount", rec.Kind)
+		assert.Equal(t, "kube", rec.Name)
+		assert.Equal(t, "ns1", rec.Namespace)
+		assert.Equal(t, "CREATE", rec.Operation)
+		assert.Equal(t, "denied", rec.Outcome)
+		assert.Equal(t, string(ReasonAccountTypeNotAllowed), rec.Reason)
+		assert.Equal(t, "jane", rec.User)
+		assert.Equal(t, []string{"platform"}, rec.Groups)
+		assert.False(t, rec.Timestamp.IsZero())
+	case <-time.After(5 * time.Second):
+		t.Fatal("no decision record published")
+	}
+}
+
+func TestAuditSinkFull(t *testing.T) {
+	t.Setenv(auditSinkURLEnv, "http://localhost:1")
+	t.Setenv(auditQueueSizeEnv, "1")
+	v := newTestController(t)
+	sink := newAuditSink(v.settings)
+	req := accountvalidatingtest.NewAccountAdmissionRequest(kubernetesAccount(t, "kube", "https://localhost:1", "{}"), admissionv1.Create)
+
+	// the sink isn't started, the second record is dropped instead of blocking
+	sink.decided(req, v.respond(req, nil, nil))
+	sink.decided(req, v.respond(req, nil, nil))
+	assert.Len(t, sink.queue, 1)
+}
@@ -0,0 +1,31 @@
+package accountvalidating
+
+import (
+	"sync"
+
+	"github.com/armory/spinnaker-operator/pkg/util"
+)
+
+// opaBackend is the name of the OPA circuit breaker
+const opaBackend = "opa"
+
+// breakerSet holds a circuit breaker per external backend called during validation
+type breakerSet struct {
+	mu       sync.Mutex
+	breakers map[string]*util.CircuitBreaker
+}
+
+// get returns the circuit breaker of the backend, creating it with the given settings if needed
+func (b *breakerSet) get(backend string, s settings) *util.CircuitBreaker {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.breakers == nil {
+		b.breakers = map[string]*util.CircuitBreaker{}
+	}
+	cb, ok := b.breakers[backend]
+	if !ok {
+		cb = util.NewCircuitBreaker(s.breakerThreshold, s.breakerCooldown)
+		b.breakers[backend] = cb
+	}
+	return cb
+}
@@ -0,0 +1,63 @@
+package accountvalidating
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/armory/spinnaker-operator/pkg/controller/accountvalidating/accountvalidatingtest"
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+)
+
+func TestHandlePolicyCircuitBreaker(t *testing.T) {
+	api := newFakeKubernetesAPI(t)
+	calls, healthy := 0, false
+	opa := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if !healthy {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, `{"result":true}`)
+	}))
+	t.Cleanup(opa.Close)
+	t.Setenv(opaURLEnv, opa.URL)
+	t.Setenv(breakerThresholdEnv, "2")
+	t.Setenv(breakerCooldownEnv, "50ms")
+	acc := kubernetesAccount(t, "kube", api.URL, "{}")
+	req := accountvalidatingtest.NewAccountAdmissionRequest(acc, admissionv1.Create)
+
+	t.Run("fail fast", func(t *testing.T) {
+		calls, healthy = 0, false
+		v := newTestController(t)
+		for i := 0; i < 3; i++ {
+			r := v.Handle(context.TODO(), req)
+			assert.False(t, r.Allowed)
+			assert.Equal(t, int32(http.StatusInternalServerError), r.Result.Code)
+		}
+		assert.Equal(t, 2, calls, "OPA isn't queried once the breaker is open")
+		assert.Contains(t, v.Handle(context.TODO(), req).Result.Message, "OPA is failing: circuit breaker open")
+
+		healthy = true
+		time.Sleep(60 * time.Millisecond)
+		assert.True(t, v.Handle(context.TODO(), req).Allowed, "the probe closes the breaker")
+		assert.True(t, v.Handle(context.TODO(), req).Allowed)
+		assert.Equal(t, 4, calls)
+	})
+
+	t.Run("fail open", func(t *testing.T) {
+		t.Setenv(breakerFailOpenEnv, "true")
+		calls, healthy = 0, false
+		v := newTestController(t)
+		v.Handle(context.TODO(), req)
+		v.Handle(context.TODO(), req)
+		r := v.Handle(context.TODO(), req)
+		assert.True(t, r.Allowed)
+		assert.Equal(t, []string{"OPA policy spinnaker/accounts/decision was not checked for account kube, OPA is failing"}, r.Warnings)
+		assert.Equal(t, 2, calls)
+	})
+}
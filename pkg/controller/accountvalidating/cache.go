@@ -0,0 +1,47 @@
+package accountvalidating
+
+import (
+	"context"
+	"strconv"
+	"sync"
+
+	"github.com/armory/spinnaker-operator/pkg/controller/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+type probeTrackerKey struct{}
+
+// probeTracker records whether any account validated for a request connected to the account's endpoints
+type probeTracker struct {
+	mu     sync.Mutex
+	probed bool
+}
+
+func withProbeTracker(ctx context.Context) (context.Context, *probeTracker) {
+	t := &probeTracker{}
+	return context.WithValue(ctx, probeTrackerKey{}, t), t
+}
+
+func recordProbed(ctx context.Context) {
+	if t, ok := ctx.Value(probeTrackerKey{}).(*probeTracker); ok {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		t.probed = true
+	}
+}
+
+func (t *probeTracker) get() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.probed
+}
+
+// withCacheability annotates the response with whether it only depends on the request: responses of validations
+// connecting to the account's endpoints can change between identical requests.
+func withCacheability(r admission.Response, cacheable bool) admission.Response {
+	if r.AuditAnnotations == nil {
+		r.AuditAnnotations = map[string]string{}
+	}
+	r.AuditAnnotations[webhook.CacheableAnnotation] = strconv.FormatBool(cacheable)
+	return r
+}
@@ -0,0 +1,97 @@
+package accountvalidating
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const defaultUpdateRateWindow = time.Minute
+
+// updateRateTracker records the recent updates of each account
+type updateRateTracker struct {
+	mu      sync.Mutex
+	updates map[string][]time.Time
+	// swept is when accounts without updates in the window were last evicted
+	swept time.Time
+}
+
+// wait returns how long until the next update of the key is allowed if limit updates already happened in the window
+// before now
+func (t *updateRateTracker) wait(key string, now time.Time, limit int, window time.Duration) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	recent := t.recent(key, now, window)
+	if len(recent) >= limit {
+		return recent[0].Add(window).Sub(now)
+	}
+	return 0
+}
+
+// record records an update of the key at now, evicting the accounts not updated within the window
+func (t *updateRateTracker) record(key string, now time.Time, window time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.updates == nil {
+		t.updates = map[string][]time.Time{}
+	}
+	t.updates[key] = append(t.recent(key, now, window), now)
+	if now.Sub(t.swept) < window {
+		return
+	}
+	t.swept = now
+	for k, u := range t.updates {
+		if now.Sub(u[len(u)-1]) >= window {
+			delete(t.updates, k)
+		}
+	}
+}
+
+// recent returns the updates of the key within the window before now, must be called with the lock held
+func (t *updateRateTracker) recent(key string, now time.Time, window time.Duration) []time.Time {
+	recent := make([]time.Time, 0, len(t.updates[key])+1)
+	for _, u := range t.updates[key] {
+		if now.Sub(u) < window {
+			recent = append(recent, u)
+		}
+	}
+	return recent
+}
+
+// checkUpdateRate throttles updates of accounts updated more than the configured limit within the window, which is
+// likely automation updating accounts in a loop. Updates are counted per replica of the operator.
+func (v *accountValidatingController) checkUpdateRate(req admission.Request) error {
+	if !v.countsTowardUpdateRate(req) {
+		return nil
+	}
+	key := req.Namespace + "/" + req.Name
+	if wait := v.updateRates.wait(key, time.Now(), v.settings.updateRateLimit, v.settings.updateRateWindow); wait > 0 {
+		msg := fmt.Sprintf("account %s was updated more than %d times in %s, which looks like a loop, retry in %s",
+			req.Name, v.settings.updateRateLimit, v.settings.updateRateWindow, wait.Round(time.Second))
+		return throttled(ReasonUpdateRateExceeded, msg, wait)
+	}
+	return nil
+}
+
+// recordUpdate counts an admitted update of an account toward its update rate, dry runs don't update the account
+func (v *accountValidatingController) recordUpdate(req admission.Request, r admission.Response) {
+	if !r.Allowed || !v.countsTowardUpdateRate(req) || (req.DryRun != nil && *req.DryRun) {
+		return
+	}
+	v.updateRates.record(req.Namespace+"/"+req.Name, time.Now(), v.settings.updateRateWindow)
+}
+
+func (v *accountValidatingController) countsTowardUpdateRate(req admission.Request) bool {
+	return v.settings.updateRateLimit > 0 && req.Operation == admissionv1.Update && isAccountRequest(req)
+}
+
+// throttled returns an error for requests the client should retry after the given time
+func throttled(reason metav1.StatusReason, msg string, retryAfter time.Duration) error {
+	return &statusError{code: http.StatusTooManyRequests, reason: reason, err: errors.New(msg), retryAfter: retryAfter}
+}
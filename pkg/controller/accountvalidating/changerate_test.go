@@ -0,0 +1,78 @@
+package accountvalidating
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/armory/spinnaker-operator/pkg/controller/accountvalidating/accountvalidatingtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleUpdateRate(t *testing.T) {
+	api := newFakeKubernetesAPI(t)
+	t.Setenv(connectivityEnv, "false")
+	t.Setenv(updateRateLimitEnv, "3")
+	v := newTestController(t)
+	acc := kubernetesAccount(t, "kube", api.URL, "{}")
+	req := accountvalidatingtest.NewAccountUpdateAdmissionRequest(acc, acc)
+
+	for i := 0; i < 3; i++ {
+		r := v.Handle(context.TODO(), req)
+		assert.True(t, r.Allowed, "update %d", i)
+	}
+
+	r := v.Handle(context.TODO(), req)
+	assert.False(t, r.Allowed)
+	assert.Equal(t, int32(http.StatusTooManyRequests), r.Result.Code)
+	assert.Equal(t, ReasonUpdateRateExceeded, r.Result.Reason)
+	assert.Contains(t, r.Result.Message, "account kube was updated more than 3 times in 1m0s, which looks like a loop")
+	if assert.NotNil(t, r.Result.Details) {
+		assert.InDelta(t, 60, r.Result.Details.RetryAfterSeconds, 1)
+	}
+
+	// other accounts aren't throttled
+	other := kubernetesAccount(t, "kube2", api.URL, "{}")
+	r = v.Handle(context.TODO(), accountvalidatingtest.NewAccountUpdateAdmissionRequest(other, other))
+	assert.True(t, r.Allowed)
+}
+
+func TestHandleUpdateRateNotAdmitted(t *testing.T) {
+	api := newFakeKubernetesAPI(t)
+	t.Setenv(connectivityEnv, "false")
+	t.Setenv(updateRateLimitEnv, "1")
+	t.Setenv(strictEnv, "true")
+	v := newTestController(t)
+	acc := kubernetesAccount(t, "kube", api.URL, "{}")
+
+	dryRun := true
+	req := accountvalidatingtest.NewAccountUpdateAdmissionRequest(acc, acc)
+	req.DryRun = &dryRun
+	assert.True(t, v.Handle(context.TODO(), req).Allowed)
+	invalid := kubernetesAccount(t, "kube", api.URL, "cacheIntervalSeconds: 86400")
+	assert.False(t, v.Handle(context.TODO(), accountvalidatingtest.NewAccountUpdateAdmissionRequest(acc, invalid)).Allowed)
+
+	// neither the dry run nor the rejected update counted
+	r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountUpdateAdmissionRequest(acc, acc))
+	assert.True(t, r.Allowed)
+	r = v.Handle(context.TODO(), accountvalidatingtest.NewAccountUpdateAdmissionRequest(acc, acc))
+	assert.False(t, r.Allowed)
+	assert.Equal(t, ReasonUpdateRateExceeded, r.Result.Reason)
+}
+
+func TestUpdateRateTracker(t *testing.T) {
+	var tr updateRateTracker
+	start := time.Now()
+	tr.record("a", start, time.Minute)
+	assert.Zero(t, tr.wait("a", start.Add(10*time.Second), 2, time.Minute))
+	tr.record("a", start.Add(10*time.Second), time.Minute)
+	assert.Equal(t, 30*time.Second, tr.wait("a", start.Add(30*time.Second), 2, time.Minute))
+	// the first update left the window
+	assert.Zero(t, tr.wait("a", start.Add(61*time.Second), 2, time.Minute))
+
+	// accounts not updated within the window are evicted
+	tr.record("b", start.Add(2*time.Minute), time.Minute)
+	assert.NotContains(t, tr.updates, "a")
+	assert.Contains(t, tr.updates, "b")
+}
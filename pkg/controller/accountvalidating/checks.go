@@ -0,0 +1,81 @@
+package accountvalidating
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// ChecksAnnotation is the audit annotation listing the checks run for a request, in the order they first ran
+const ChecksAnnotation = "checks"
+
+const (
+	structuralCheck          = "structural"
+	schemaCheck              = "schema"
+	uniquenessCheck          = "uniqueness"
+	reservedKeysCheck        = "reserved-keys"
+	secretConflictsCheck     = "secret-conflicts"
+	privilegedCheck          = "privileged-secrets"
+	softLimitCheck           = "soft-limit"
+	duplicateTargetsCheck    = "duplicate-targets"
+	identityGroupsCheck      = "identity-groups"
+	compatibilityCheck       = "compatibility"
+	providerCheck            = "provider"
+	connectivityCheck        = "connectivity"
+	policyCheck              = "policy"
+	manifestReferencesCheck  = "manifest-references"
+	inventoryCheck           = "inventory"
+	deprecationsCheck        = "deprecations"
+	notificationTargetsCheck = "notification-targets"
+	groupSettingsCheck       = "group-settings"
+)
+
+type checkTrackerKey struct{}
+
+// checkTracker records the checks run for a request
+type checkTracker struct {
+	mu     sync.Mutex
+	checks []string
+	// trace is the detailed trace of the checks, nil unless requested with DebugAnnotation
+	trace *validationTrace
+}
+
+func withCheckTracker(ctx context.Context, trace *validationTrace) (context.Context, *checkTracker) {
+	t := &checkTracker{trace: trace}
+	return context.WithValue(ctx, checkTrackerKey{}, t), t
+}
+
+func recordCheck(ctx context.Context, check string) {
+	t, ok := ctx.Value(checkTrackerKey{}).(*checkTracker)
+	if !ok {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, c := range t.checks {
+		if c == check {
+			return
+		}
+	}
+	t.checks = append(t.checks, check)
+	if t.trace != nil {
+		t.trace.record(check)
+	}
+}
+
+func (t *checkTracker) list() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]string{}, t.checks...)
+}
+
+// withChecks annotates the response with the checks run, so that they're recorded in the API server audit log
+func withChecks(r admission.Response, checks []string) admission.Response {
+	if r.AuditAnnotations == nil {
+		r.AuditAnnotations = map[string]string{}
+	}
+	r.AuditAnnotations[ChecksAnnotation] = strings.Join(checks, ",")
+	return r
+}
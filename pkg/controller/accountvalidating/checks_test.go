@@ -0,0 +1,31 @@
+package accountvalidating
+
+import (
+	"context"
+	"testing"
+
+	"github.com/armory/spinnaker-operator/pkg/controller/accountvalidating/accountvalidatingtest"
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+)
+
+func TestHandleChecksAnnotation(t *testing.T) {
+	api := newFakeKubernetesAPI(t)
+	acc := kubernetesAccount(t, "kube", api.URL, "{}")
+
+	t.Run("connectivity", func(t *testing.T) {
+		v := newTestController(t)
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountAdmissionRequest(acc, admissionv1.Create))
+		assert.True(t, r.Allowed)
+		assert.Equal(t, "structural,reserved-keys,provider,connectivity", r.AuditAnnotations[ChecksAnnotation])
+	})
+
+	t.Run("structural only", func(t *testing.T) {
+		t.Setenv(connectivityEnv, "false")
+		t.Setenv(secretConflictsEnv, "true")
+		v := newTestController(t)
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountAdmissionRequest(acc, admissionv1.Create))
+		assert.True(t, r.Allowed)
+		assert.Equal(t, "structural,reserved-keys,secret-conflicts,provider", r.AuditAnnotations[ChecksAnnotation])
+	})
+}
@@ -0,0 +1,58 @@
+package accountvalidating
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// namespaceAllowlist holds the pairs of namespaces whose accounts can reference secrets of another namespace, keyed
+// by the namespace of the account, "*" standing for any namespace
+type namespaceAllowlist map[string]map[string]bool
+
+// parseNamespaceAllowlist parses <account namespace>=<secret namespace> entries
+func parseNamespaceAllowlist(entries []string) (namespaceAllowlist, error) {
+	l := namespaceAllowlist{}
+	for _, e := range entries {
+		kv := strings.Split(e, "=")
+		if len(kv) != 2 || kv[0] == "" || kv[1] == "" {
+			return nil, fmt.Errorf("invalid entry \"%s\", expected <account namespace>=<secret namespace>", e)
+		}
+		if l[kv[0]] == nil {
+			l[kv[0]] = map[string]bool{}
+		}
+		l[kv[0]][kv[1]] = true
+	}
+	return l, nil
+}
+
+func (l namespaceAllowlist) allows(source, target string) bool {
+	return l[source][target] || l["*"][target]
+}
+
+// checkSecretNamespaces denies accounts whose secrets are read from another namespace, which could leak the
+// credentials of another tenant, unless the allowlist permits the pair of namespaces. Secrets are read from the
+// lookup namespace when it holds them.
+func (v *accountValidatingController) checkSecretNamespaces(ctx context.Context, acc interfaces.SpinnakerAccount) error {
+	ns := v.settings.secretNamespace
+	if ns == "" || ns == acc.GetNamespace() || v.settings.secretNamespaceAllowlist.allows(acc.GetNamespace(), ns) {
+		return nil
+	}
+	for _, r := range secretRefs(acc) {
+		err := v.reader().Get(ctx, client.ObjectKey{Namespace: ns, Name: r.name}, &v1.Secret{})
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return internalError(fmt.Errorf("unable to get secret %s of namespace %s: %w", r.name, ns, err))
+		}
+		return rejected(ReasonCrossNamespaceSecret, fmt.Sprintf("account %s in namespace %s uses secret %s of namespace %s (%s), allow the pair in %s to use it",
+			acc.GetName(), acc.GetNamespace(), r.name, ns, secretNamespaceEnv, secretNamespaceAllowlistEnv))
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package accountvalidating
+
+import (
+	"context"
+	"testing"
+
+	"github.com/armory/spinnaker-operator/pkg/accounts"
+	"github.com/armory/spinnaker-operator/pkg/controller/accountvalidating/accountvalidatingtest"
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestHandleCrossNamespaceSecret(t *testing.T) {
+	own := &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "kubeconfigs", Namespace: "ns1"}}
+	shared := &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "kubeconfigs", Namespace: "shared"}}
+	t.Setenv(accounts.AsyncValidationEnv, "true")
+	t.Setenv(secretNamespaceEnv, "shared")
+
+	t.Run("same namespace", func(t *testing.T) {
+		v := newTestController(t, own)
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountAdmissionRequest(secretAccount(t, "dev", "kubeconfigs", "dev"), admissionv1.Create))
+		assert.True(t, r.Allowed)
+	})
+
+	t.Run("other namespace", func(t *testing.T) {
+		v := newTestController(t, own, shared)
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountAdmissionRequest(secretAccount(t, "dev", "kubeconfigs", "dev"), admissionv1.Create))
+		assert.False(t, r.Allowed)
+		assert.Equal(t, ReasonCrossNamespaceSecret, r.Result.Reason)
+		assert.Equal(t, "account dev in namespace ns1 uses secret kubeconfigs of namespace shared (SECRET_LOOKUP_NAMESPACE), allow the pair in ACCOUNT_SECRET_NAMESPACE_ALLOWLIST to use it", r.Result.Message)
+	})
+
+	t.Run("allowlisted namespace", func(t *testing.T) {
+		t.Setenv(secretNamespaceAllowlistEnv, "ns3=ns4,ns1=shared")
+		v := newTestController(t, own, shared)
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountAdmissionRequest(secretAccount(t, "dev", "kubeconfigs", "dev"), admissionv1.Create))
+		assert.True(t, r.Allowed)
+	})
+
+	t.Run("invalid allowlist", func(t *testing.T) {
+		t.Setenv(secretNamespaceAllowlistEnv, "ns1")
+		_, err := loadSettings()
+		assert.Error(t, err)
+	})
+}
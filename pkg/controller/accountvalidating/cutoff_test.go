@@ -0,0 +1,54 @@
+package accountvalidating
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/armory/spinnaker-operator/pkg/controller/accountvalidating/accountvalidatingtest"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func TestHandleEnforcementCutoff(t *testing.T) {
+	t.Setenv(enforcementCutoffEnv, "2022-01-01T00:00:00Z")
+	// invalid returns an update of an account with an invalid endpoint
+	invalid := func(created time.Time) admission.Request {
+		acc := kubernetesAccount(t, "kube", "mycluster.com", "{}")
+		acc.SetCreationTimestamp(metav1.NewTime(created))
+		return accountvalidatingtest.NewAccountUpdateAdmissionRequest(acc, acc)
+	}
+
+	t.Run("account created before the cutoff", func(t *testing.T) {
+		v := newTestController(t)
+		r := v.Handle(context.TODO(), invalid(time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)))
+		assert.True(t, r.Allowed)
+		if assert.Len(t, r.Warnings, 1) {
+			assert.Contains(t, r.Warnings[0], "account kube was created before the enforcement cutoff 2022-01-01T00:00:00Z (ACCOUNT_ENFORCEMENT_CUTOFF), allowing the update although it fails validation: account \"kube\": invalid endpoint")
+		}
+	})
+
+	t.Run("account created after the cutoff", func(t *testing.T) {
+		v := newTestController(t)
+		r := v.Handle(context.TODO(), invalid(time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)))
+		assert.False(t, r.Allowed)
+		assert.Equal(t, ReasonInvalidEndpoint, r.Result.Reason)
+	})
+}
+
+func TestCutoffFromEnv(t *testing.T) {
+	start := time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC)
+	c, err := cutoffFromEnv(enforcementCutoffEnv, start)
+	assert.Nil(t, err)
+	assert.True(t, c.IsZero())
+
+	t.Setenv(enforcementCutoffEnv, "24h")
+	c, err = cutoffFromEnv(enforcementCutoffEnv, start)
+	assert.Nil(t, err)
+	assert.Equal(t, start.Add(-24*time.Hour), c)
+
+	t.Setenv(enforcementCutoffEnv, "yesterday")
+	_, err = cutoffFromEnv(enforcementCutoffEnv, start)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,108 @@
+package accountvalidating
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/armory/spinnaker-operator/pkg/util"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// DebugAnnotation traces the validation of an account when set to "true". The trace is logged at info level and,
+// when the account is denied, appended to the denial message.
+const DebugAnnotation = "spinnaker.io/debug-validation"
+
+type traceStep struct {
+	check    string
+	start    time.Time
+	duration time.Duration
+	probed   bool
+}
+
+// validationTrace records the checks run for a request, how long they took and how they ended
+type validationTrace struct {
+	now   func() time.Time
+	steps []*traceStep
+}
+
+// traceFor returns a trace if the object of the request has DebugAnnotation, nil otherwise
+func traceFor(req admission.Request) *validationTrace {
+	obj := &metav1.PartialObjectMetadata{}
+	if err := json.Unmarshal(req.Object.Raw, obj); err != nil || !strings.EqualFold(obj.GetAnnotations()[DebugAnnotation], "true") {
+		return nil
+	}
+	return &validationTrace{now: time.Now}
+}
+
+// record starts a step for the check, ending the current step. Connectivity is probed by other checks, it's noted on
+// the current step rather than traced as a step of its own.
+func (t *validationTrace) record(check string) {
+	if check == connectivityCheck {
+		if len(t.steps) > 0 {
+			t.steps[len(t.steps)-1].probed = true
+		}
+		return
+	}
+	now := t.now()
+	t.end(now)
+	t.steps = append(t.steps, &traceStep{check: check, start: now})
+}
+
+func (t *validationTrace) end(now time.Time) {
+	if len(t.steps) > 0 {
+		if s := t.steps[len(t.steps)-1]; s.duration == 0 {
+			s.duration = now.Sub(s.start)
+		}
+	}
+}
+
+// lines returns the steps of the trace and the result of the request. Checks stop at the first denial, so only the
+// last step can have denied the request.
+func (t *validationTrace) lines(r admission.Response) []string {
+	t.end(t.now())
+	lines := make([]string, 0, len(t.steps)+1)
+	for i, s := range t.steps {
+		result := "passed"
+		if i == len(t.steps)-1 && !r.Allowed {
+			result = "denied"
+		}
+		l := fmt.Sprintf("%d. %s: %s in %s", i+1, s.check, result, s.duration.Round(time.Millisecond))
+		if s.probed {
+			l += ", connectivity probed"
+		}
+		lines = append(lines, l)
+	}
+	switch {
+	case r.Allowed:
+		lines = append(lines, fmt.Sprintf("result: allowed with %d warnings", len(r.Warnings)))
+	case len(t.steps) == 0:
+		lines = append(lines, "result: denied before any check ran")
+	case r.Result != nil:
+		lines = append(lines, fmt.Sprintf("result: denied (%s)", r.Result.Reason))
+	default:
+		lines = append(lines, "result: denied")
+	}
+	return lines
+}
+
+// withTrace logs the trace of the request and appends it to the message of denials. Values of the object's sensitive
+// keys are redacted from both.
+func withTrace(req admission.Request, r admission.Response, t *validationTrace) admission.Response {
+	sensitive := util.SensitiveValues(req.Object.Raw)
+	lines := t.lines(r)
+	for i := range lines {
+		lines[i] = util.RedactValues(lines[i], sensitive)
+	}
+	var msg string
+	if r.Result != nil {
+		msg = util.RedactValues(r.Result.Message, sensitive)
+	}
+	log.Info("Validation trace", "kind", req.Kind.Kind, "namespace", req.Namespace, "name", req.Name, "allowed", r.Allowed, "message", msg, "trace", lines)
+	if !r.Allowed && r.Result != nil {
+		r.Result.Message = msg + "\nvalidation trace:\n" + strings.Join(lines, "\n")
+	}
+	return r
+}
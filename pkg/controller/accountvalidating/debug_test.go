@@ -0,0 +1,49 @@
+package accountvalidating
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/armory/spinnaker-operator/pkg/controller/accountvalidating/accountvalidatingtest"
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func TestHandleDebugTrace(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"InternalError","code":500}`, http.StatusInternalServerError)
+	}))
+	defer api.Close()
+
+	t.Run("denial with trace", func(t *testing.T) {
+		acc := kubernetesAccount(t, "kube", api.URL, "{}")
+		acc.SetAnnotations(map[string]string{DebugAnnotation: "true"})
+		v := newTestController(t)
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountAdmissionRequest(acc, admissionv1.Create))
+		assert.False(t, r.Allowed)
+		assert.Contains(t, r.Result.Message, "\nvalidation trace:\n1. structural: passed in ")
+		assert.Contains(t, r.Result.Message, "\n2. reserved-keys: passed in ")
+		assert.Regexp(t, `\n3\. provider: denied in [^\n]+, connectivity probed\nresult: denied \(\w+\)$`, r.Result.Message)
+	})
+
+	t.Run("no trace without the annotation", func(t *testing.T) {
+		acc := kubernetesAccount(t, "kube", api.URL, "{}")
+		v := newTestController(t)
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountAdmissionRequest(acc, admissionv1.Create))
+		assert.False(t, r.Allowed)
+		assert.NotContains(t, r.Result.Message, "validation trace")
+	})
+
+	t.Run("secrets are redacted", func(t *testing.T) {
+		acc := kubernetesAccount(t, "kube", api.URL, "{}")
+		acc.SetAnnotations(map[string]string{DebugAnnotation: "true"})
+		acc.GetSpec().Settings["password"] = "hunter2"
+		req := accountvalidatingtest.NewAccountAdmissionRequest(acc, admissionv1.Create)
+		r := withTrace(req, admission.Errored(http.StatusForbidden, errors.New("login with hunter2 failed")), traceFor(req))
+		assert.Equal(t, "login with **REDACTED** failed\nvalidation trace:\nresult: denied before any check ran", r.Result.Message)
+	})
+}
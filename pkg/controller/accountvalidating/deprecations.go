@@ -0,0 +1,34 @@
+package accountvalidating
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/armory/spinnaker-operator/pkg/accounts"
+	"github.com/armory/spinnaker-operator/pkg/accounts/account"
+	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
+	v1 "k8s.io/api/core/v1"
+)
+
+// checkDeprecations warns about the deprecated settings of the account in the deprecation schedule, denying the
+// account once their sunset date is reached
+func (v *accountValidatingController) checkDeprecations(ctx context.Context, acc interfaces.SpinnakerAccount) error {
+	if v.settings.deprecationConfigMap == nil {
+		return nil
+	}
+	recordCheck(ctx, deprecationsCheck)
+	cm := &v1.ConfigMap{}
+	if err := v.client.Get(ctx, *v.settings.deprecationConfigMap, cm); err != nil {
+		return internalError(fmt.Errorf("unable to get deprecation schedule from ConfigMap %s: %w", v.settings.deprecationConfigMap, err))
+	}
+	deps, err := accounts.ParseDeprecations(cm.Data)
+	if err != nil {
+		return internalError(fmt.Errorf("invalid deprecation schedule in ConfigMap %s: %w", v.settings.deprecationConfigMap, err))
+	}
+	warnings, err := accounts.CheckDeprecations(acc, deps, time.Now())
+	for _, msg := range warnings {
+		account.Warn(ctx, msg)
+	}
+	return err
+}
@@ -0,0 +1,41 @@
+package accountvalidating
+
+import (
+	"context"
+	"testing"
+
+	"github.com/armory/spinnaker-operator/pkg/controller/accountvalidating/accountvalidatingtest"
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestHandleDeprecations(t *testing.T) {
+	api := newFakeKubernetesAPI(t)
+	t.Setenv(connectivityEnv, "false")
+	t.Setenv(deprecationConfigMapEnv, "operator/deprecations")
+	schedule := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "operator", Name: "deprecations"},
+		Data: map[string]string{
+			"Kubernetes.liveManifestCalls":                "sunset: 2000-01-01\nmessage: manifests are always read live",
+			"Kubernetes.cacheAllApplicationRelationships": "sunset: 2999-01-01",
+		},
+	}
+	v := newTestController(t, schedule)
+
+	t.Run("deprecated setting", func(t *testing.T) {
+		acc := kubernetesAccount(t, "kube", api.URL, "cacheAllApplicationRelationships: true")
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountAdmissionRequest(acc, admissionv1.Create))
+		assert.True(t, r.Allowed)
+		assert.Equal(t, []string{"account kube uses setting cacheAllApplicationRelationships is deprecated and will be removed on 2999-01-01"}, r.Warnings)
+	})
+
+	t.Run("removed setting", func(t *testing.T) {
+		acc := kubernetesAccount(t, "kube", api.URL, "liveManifestCalls: true")
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountAdmissionRequest(acc, admissionv1.Create))
+		assert.False(t, r.Allowed)
+		assert.Equal(t, ReasonFeatureSunset, r.Result.Reason)
+		assert.Contains(t, r.Result.Message, "liveManifestCalls was removed on 2000-01-01: manifests are always read live")
+	})
+}
@@ -0,0 +1,31 @@
+package accountvalidating
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/armory/spinnaker-operator/pkg/accounts"
+	"github.com/armory/spinnaker-operator/pkg/accounts/account"
+	"github.com/armory/spinnaker-operator/pkg/accounts/validator"
+	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
+)
+
+// checkDisable warns about, or in strict mode rejects, updates disabling an account the SpinnakerService config
+// still references
+func (v *accountValidatingController) checkDisable(ctx context.Context, acc interfaces.SpinnakerAccount, spinSvc interfaces.SpinnakerService) error {
+	old, ok := validator.PreviousFrom(ctx)
+	if !ok || !old.GetSpec().Enabled || acc.GetSpec().Enabled {
+		return nil
+	}
+	usages := accounts.FindAccountUsages(acc.GetName(), spinSvc)
+	if len(usages) == 0 {
+		return nil
+	}
+	msg := fmt.Sprintf("account %s is being disabled but SpinnakerService %s still references it at %s", acc.GetName(), spinSvc.GetName(), strings.Join(usages, ", "))
+	if v.settings.strict {
+		return rejected(ReasonAccountInUse, msg)
+	}
+	account.Warn(ctx, msg)
+	return nil
+}
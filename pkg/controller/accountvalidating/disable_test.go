@@ -0,0 +1,58 @@
+package accountvalidating
+
+import (
+	"context"
+	"testing"
+
+	"github.com/armory/spinnaker-operator/pkg/controller/accountvalidating/accountvalidatingtest"
+	"github.com/armory/spinnaker-operator/pkg/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleDisableReferencedAccount(t *testing.T) {
+	svc := test.TypesFactory.NewService()
+	test.ReadYamlString([]byte(`
+apiVersion: spinnaker.io/v1alpha2
+kind: SpinnakerService
+metadata:
+  name: spinnaker
+  namespace: ns1
+spec:
+  spinnakerConfig:
+    config:
+      version: 1.28.1
+      providers:
+        kubernetes:
+          enabled: true
+          primaryAccount: kube
+`), svc, t)
+	api := newFakeKubernetesAPI(t)
+	t.Setenv(connectivityEnv, "false")
+	old := kubernetesAccount(t, "kube", api.URL, "{}")
+	disabled := kubernetesAccount(t, "kube", api.URL, "{}")
+	disabled.GetSpec().Enabled = false
+	msg := "account kube is being disabled but SpinnakerService spinnaker still references it at config.providers.kubernetes.primaryAccount"
+
+	t.Run("warning", func(t *testing.T) {
+		v := newTestController(t, svc)
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountUpdateAdmissionRequest(old, disabled))
+		assert.True(t, r.Allowed)
+		assert.Equal(t, []string{msg}, r.Warnings)
+	})
+
+	t.Run("strict", func(t *testing.T) {
+		t.Setenv(strictEnv, "true")
+		v := newTestController(t, svc)
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountUpdateAdmissionRequest(old, disabled))
+		assert.False(t, r.Allowed)
+		assert.Equal(t, ReasonAccountInUse, r.Result.Reason)
+		assert.Equal(t, msg, r.Result.Message)
+	})
+
+	t.Run("already disabled", func(t *testing.T) {
+		v := newTestController(t, svc)
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountUpdateAdmissionRequest(disabled, disabled))
+		assert.True(t, r.Allowed)
+		assert.Empty(t, r.Warnings)
+	})
+}
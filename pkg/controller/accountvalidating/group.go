@@ -0,0 +1,141 @@
+package accountvalidating
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func (v *accountValidatingController) handleGroup(ctx context.Context, req admission.Request) admission.Response {
+	g := TypesFactory.NewAccountGroup()
+	if err := v.decoder.Decode(req, g); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	warnings, err := v.validateGroup(ctx, g)
+	return v.respond(req, warnings, err)
+}
+
+// validateGroup validates the group's members as accounts of the group's namespace and reports all invalid members
+// at once
+func (v *accountValidatingController) validateGroup(ctx context.Context, g interfaces.SpinnakerAccountGroup) ([]string, error) {
+	members := g.GetSpec().Accounts
+	recordCheck(ctx, uniquenessCheck)
+	if dups := duplicateNames(members, v.settings.caseInsensitiveNames); len(dups) > 0 {
+		return nil, &statusError{
+			code:   http.StatusUnprocessableEntity,
+			reason: ReasonDuplicateAccountName,
+			err:    fmt.Errorf("account group %s has duplicate account names: %s", g.GetName(), strings.Join(dups, ", ")),
+		}
+	}
+
+	recordCheck(ctx, groupSettingsCheck)
+	if msgs := inconsistentSettings(members, v.settings.groupConsistentSettings); len(msgs) > 0 {
+		return nil, &statusError{
+			code:   http.StatusUnprocessableEntity,
+			reason: ReasonInvalidAccountGroup,
+			err:    fmt.Errorf("account group %s has accounts with inconsistent settings: %s", g.GetName(), strings.Join(msgs, "; ")),
+		}
+	}
+
+	// Members share the time allotted to the request
+	ctx, cancel := context.WithTimeout(ctx, v.settings.timeout)
+	defer cancel()
+
+	warnings := make([]string, 0)
+	failures := make([]string, 0)
+	for _, m := range members {
+		acc := TypesFactory.NewAccount()
+		acc.SetName(m.Name)
+		acc.SetNamespace(g.GetNamespace())
+		acc.SetLabels(g.GetLabels())
+		acc.SetAnnotations(g.GetAnnotations())
+		m.Spec.DeepCopyInto(acc.GetSpec())
+
+		w, err := v.validate(ctx, acc)
+		for _, msg := range w {
+			warnings = append(warnings, fmt.Sprintf("account %s: %s", m.Name, msg))
+		}
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("account %s: %s", m.Name, err.Error()))
+		}
+	}
+	if len(failures) > 0 {
+		return nil, &statusError{
+			code:   http.StatusUnprocessableEntity,
+			reason: ReasonInvalidAccountGroup,
+			err:    fmt.Errorf("account group %s has %d invalid account(s):\n%s", g.GetName(), len(failures), strings.Join(failures, "\n")),
+		}
+	}
+	return warnings, nil
+}
+
+// duplicateNames returns the names used by more than one member, in order of first duplicate.
+// When names are compared case-insensitively, names differing from the first use only by case are returned
+// with the conflicting name.
+func duplicateNames(members []interfaces.SpinnakerAccountGroupMember, caseInsensitive bool) []string {
+	first := map[string]string{}
+	reported := map[string]bool{}
+	dups := make([]string, 0)
+	for _, m := range members {
+		key := m.Name
+		if caseInsensitive {
+			key = strings.ToLower(m.Name)
+		}
+		existing, ok := first[key]
+		if !ok {
+			first[key] = m.Name
+			continue
+		}
+		dup := m.Name
+		if existing != m.Name {
+			dup = fmt.Sprintf("%s (conflicts with %s)", m.Name, existing)
+		}
+		if !reported[dup] {
+			reported[dup] = true
+			dups = append(dups, dup)
+		}
+	}
+	return dups
+}
+
+// inconsistentSettings describes the settings members of the same type set to different values. Members not
+// setting them use the type's default and aren't compared.
+func inconsistentSettings(members []interfaces.SpinnakerAccountGroupMember, consistent map[string][]string) []string {
+	msgs := make([]string, 0)
+	for _, tp := range memberTypes(members) {
+		for _, name := range consistent[strings.ToLower(string(tp))] {
+			var values, uses []string
+			for _, m := range members {
+				val, ok := m.Spec.Settings[name]
+				if !ok || m.Spec.Type != tp {
+					continue
+				}
+				uses = append(uses, fmt.Sprintf("%v (%s)", val, m.Name))
+				if v := fmt.Sprint(val); len(values) == 0 || values[0] != v {
+					values = append(values, v)
+				}
+			}
+			if len(values) > 1 {
+				msgs = append(msgs, fmt.Sprintf("%s setting %s is %s", tp, name, strings.Join(uses, ", ")))
+			}
+		}
+	}
+	return msgs
+}
+
+// memberTypes returns the types of the members in order of first use
+func memberTypes(members []interfaces.SpinnakerAccountGroupMember) []interfaces.AccountType {
+	seen := map[interfaces.AccountType]bool{}
+	types := make([]interfaces.AccountType, 0)
+	for _, m := range members {
+		if !seen[m.Spec.Type] {
+			seen[m.Spec.Type] = true
+			types = append(types, m.Spec.Type)
+		}
+	}
+	return types
+}
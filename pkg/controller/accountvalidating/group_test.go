@@ -0,0 +1,92 @@
+package accountvalidating
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
+	"github.com/armory/spinnaker-operator/pkg/controller/accountvalidating/accountvalidatingtest"
+	"github.com/armory/spinnaker-operator/pkg/test"
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+)
+
+func accountGroup(t *testing.T, server string, members ...string) interfaces.SpinnakerAccountGroup {
+	g := test.TypesFactory.NewAccountGroup()
+	g.SetName("group")
+	g.SetNamespace("ns1")
+	for _, m := range members {
+		acc := kubernetesAccount(t, m, server, "{}")
+		g.GetSpec().Accounts = append(g.GetSpec().Accounts, interfaces.SpinnakerAccountGroupMember{Name: m, Spec: *acc.GetSpec()})
+	}
+	return g
+}
+
+func TestHandleAccountGroup(t *testing.T) {
+	api := newFakeKubernetesAPI(t)
+
+	t.Run("duplicate names", func(t *testing.T) {
+		v := newTestController(t)
+		g := accountGroup(t, api.URL, "kube1", "kube2", "kube1")
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountGroupAdmissionRequest(g, admissionv1.Create))
+		assert.False(t, r.Allowed)
+		assert.Equal(t, ReasonDuplicateAccountName, r.Result.Reason)
+		assert.Equal(t, "account group group has duplicate account names: kube1", r.Result.Message)
+	})
+
+	t.Run("case-only collision", func(t *testing.T) {
+		g := accountGroup(t, api.URL, "prod", "kube2", "Prod")
+		t.Setenv(caseInsensitiveEnv, "true")
+		v := newTestController(t)
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountGroupAdmissionRequest(g, admissionv1.Create))
+		assert.False(t, r.Allowed)
+		assert.Equal(t, ReasonDuplicateAccountName, r.Result.Reason)
+		assert.Equal(t, "account group group has duplicate account names: Prod (conflicts with prod)", r.Result.Message)
+
+		t.Setenv(caseInsensitiveEnv, "false")
+		v = newTestController(t)
+		r = v.Handle(context.TODO(), accountvalidatingtest.NewAccountGroupAdmissionRequest(g, admissionv1.Create))
+		assert.True(t, r.Allowed)
+	})
+
+	t.Run("invalid members", func(t *testing.T) {
+		v := newTestController(t)
+		g := accountGroup(t, api.URL, "kube1", "kube2", "kube3")
+		for _, i := range []int{0, 2} {
+			g.GetSpec().Accounts[i].Spec.Settings = interfaces.FreeForm{
+				"namespaces":     []interface{}{"ns1"},
+				"omitNamespaces": []interface{}{"ns2"},
+			}
+		}
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountGroupAdmissionRequest(g, admissionv1.Create))
+		assert.False(t, r.Allowed)
+		assert.Equal(t, ReasonInvalidAccountGroup, r.Result.Reason)
+		assert.Equal(t, fmt.Sprintf("account group group has 2 invalid account(s):\n%s\n%s",
+			`account kube1: at most one of "namespaces" and "omitNamespaces" can be supplied.`,
+			`account kube3: at most one of "namespaces" and "omitNamespaces" can be supplied.`), r.Result.Message)
+	})
+
+	t.Run("inconsistent provider settings", func(t *testing.T) {
+		v := newTestController(t)
+		g := accountGroup(t, api.URL, "kube1", "kube2", "kube3")
+		g.GetSpec().Accounts[0].Spec.Settings = interfaces.FreeForm{"providerVersion": "V2"}
+		g.GetSpec().Accounts[2].Spec.Settings = interfaces.FreeForm{"providerVersion": "V1"}
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountGroupAdmissionRequest(g, admissionv1.Create))
+		assert.False(t, r.Allowed)
+		assert.Equal(t, ReasonInvalidAccountGroup, r.Result.Reason)
+		assert.Equal(t, "account group group has accounts with inconsistent settings: Kubernetes setting providerVersion is V2 (kube1), V1 (kube3)", r.Result.Message)
+
+		t.Setenv(groupConsistentSettingsEnv, "Kubernetes=cacheThreads")
+		v = newTestController(t)
+		r = v.Handle(context.TODO(), accountvalidatingtest.NewAccountGroupAdmissionRequest(g, admissionv1.Create))
+		assert.True(t, r.Allowed)
+	})
+
+	t.Run("valid group", func(t *testing.T) {
+		v := newTestController(t)
+		g := accountGroup(t, api.URL, "kube1", "kube2", "kube3")
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountGroupAdmissionRequest(g, admissionv1.Create))
+		assert.True(t, r.Allowed)
+	})
+}
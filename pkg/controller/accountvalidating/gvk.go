@@ -0,0 +1,59 @@
+package accountvalidating
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
+	"github.com/armory/spinnaker-operator/pkg/util"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func isAccountRequest(req admission.Request) bool {
+	return isKindRequest(req, "SpinnakerAccount")
+}
+
+func isAccountGroupRequest(req admission.Request) bool {
+	return isKindRequest(req, "SpinnakerAccountGroup")
+}
+
+func isKindRequest(req admission.Request, kind string) bool {
+	gv := TypesFactory.GetGroupVersion()
+	return kind == req.AdmissionRequest.Kind.Kind &&
+		gv.Group == req.AdmissionRequest.Kind.Group &&
+		gv.Version == req.AdmissionRequest.Kind.Version
+}
+
+// getSpinnakerService returns the SpinnakerService the account belongs to, or nil if there's none.
+// There should be only one SpinnakerService per namespace.
+func (v *accountValidatingController) getSpinnakerService(ns string) (interfaces.SpinnakerService, error) {
+	list, err := util.GetSpinnakerServices(TypesFactory.NewServiceList(), ns, v.reader())
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, nil
+	}
+	return list[0], nil
+}
+
+// resolveService returns the SpinnakerService the account is validated against: the target service if set,
+// otherwise the service of the account's namespace
+func (v *accountValidatingController) resolveService(ctx context.Context, acc interfaces.SpinnakerAccount) (interfaces.SpinnakerService, error) {
+	if v.targetService == nil {
+		return v.getSpinnakerService(acc.GetNamespace())
+	}
+	svc := TypesFactory.NewService()
+	if err := v.reader().Get(ctx, *v.targetService, svc); err != nil {
+		return nil, fmt.Errorf("unable to get SpinnakerService %s: %w", v.targetService, err)
+	}
+	return svc, nil
+}
+
+// getSpinnakerVersion returns the configured Spinnaker version, defaulting to the deployed version
+func getSpinnakerVersion(ctx context.Context, spinSvc interfaces.SpinnakerService) string {
+	if v, err := spinSvc.GetSpinnakerConfig().GetHalConfigPropString(ctx, "version"); err == nil && v != "" {
+		return v
+	}
+	return spinSvc.GetStatus().Version
+}
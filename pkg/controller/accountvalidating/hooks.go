@@ -0,0 +1,25 @@
+package accountvalidating
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// decisionHook is called with each decision of the webhook. Hooks are started with the manager and must not block
+// the admission response.
+type decisionHook interface {
+	manager.Runnable
+	decided(req admission.Request, r admission.Response)
+}
+
+// decisionHooks returns the hooks configured in the settings
+func decisionHooks(s settings) []decisionHook {
+	hooks := make([]decisionHook, 0)
+	if a := newAuditSink(s); a != nil {
+		hooks = append(hooks, a)
+	}
+	if n := newNotifier(s); n != nil {
+		hooks = append(hooks, n)
+	}
+	return hooks
+}
@@ -0,0 +1,83 @@
+package accountvalidating
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/armory/spinnaker-operator/pkg/accounts/account"
+	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
+	"github.com/armory/spinnaker-operator/pkg/util"
+)
+
+// identityBackend is the name of the identity groups circuit breaker
+const identityBackend = "identity"
+
+// permissionRoles returns the sorted roles granted permissions on the account
+func permissionRoles(acc interfaces.SpinnakerAccount) []string {
+	set := map[string]bool{}
+	for _, roles := range acc.GetSpec().Permissions {
+		for _, r := range roles {
+			set[r] = true
+		}
+	}
+	roles := make([]string, 0, len(set))
+	for r := range set {
+		roles = append(roles, r)
+	}
+	sort.Strings(roles)
+	return roles
+}
+
+// checkIdentityGroups warns about the roles of the account's permissions that aren't groups of the identity backend.
+// Groups are looked up with GET <identityGroupsURL>/<group>, a 404 meaning the group doesn't exist.
+func (v *accountValidatingController) checkIdentityGroups(ctx context.Context, acc interfaces.SpinnakerAccount) {
+	roles := permissionRoles(acc)
+	if len(roles) == 0 || !account.ConnectivityEnabled(ctx) {
+		return
+	}
+	b := v.breakers.get(identityBackend, v.settings)
+	unknown := make([]string, 0)
+	for _, role := range roles {
+		if !b.Allow() {
+			account.Warn(ctx, "groups of account %s were not checked, the identity backend is failing", acc.GetName())
+			return
+		}
+		exists, err := v.groupExists(ctx, role)
+		b.Record(err != nil)
+		if err != nil {
+			account.Warn(ctx, "unable to check the groups of account %s: %v", acc.GetName(), err)
+			return
+		}
+		if !exists {
+			unknown = append(unknown, role)
+		}
+	}
+	if len(unknown) > 0 {
+		account.Warn(ctx, "account %s grants permissions to groups unknown to the identity backend: %s", acc.GetName(), strings.Join(unknown, ", "))
+	}
+}
+
+func (v *accountValidatingController) groupExists(ctx context.Context, group string) (bool, error) {
+	svc := &util.HttpService{}
+	u := strings.TrimSuffix(v.settings.identityGroupsURL, "/") + "/" + url.PathEscape(group)
+	req, err := svc.Request(ctx, util.GET, u, nil, nil, nil)
+	if err != nil {
+		return false, err
+	}
+	resp, err := svc.Execute(ctx, req)
+	if err != nil {
+		return false, fmt.Errorf("unable to look up group %s: %w", group, err)
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	}
+	return false, fmt.Errorf("unable to look up group %s: %s returned %d", group, u, resp.StatusCode)
+}
@@ -0,0 +1,59 @@
+package accountvalidating
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
+	"github.com/armory/spinnaker-operator/pkg/controller/accountvalidating/accountvalidatingtest"
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+)
+
+func TestHandleIdentityGroups(t *testing.T) {
+	api := newFakeKubernetesAPI(t)
+	directory := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/groups/platform", "/groups/sre team":
+			w.WriteHeader(http.StatusOK)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(directory.Close)
+	t.Setenv(identityGroupCheckEnv, "true")
+	t.Setenv(identityGroupsURLEnv, directory.URL+"/groups/")
+
+	t.Run("known groups", func(t *testing.T) {
+		acc := kubernetesAccount(t, "kube", api.URL, "{}")
+		acc.GetSpec().Permissions = interfaces.AccountPermissions{"READ": {"platform", "sre team"}, "WRITE": {"platform"}}
+		r := newTestController(t).Handle(context.TODO(), accountvalidatingtest.NewAccountAdmissionRequest(acc, admissionv1.Create))
+		assert.True(t, r.Allowed)
+		assert.Empty(t, r.Warnings)
+	})
+
+	t.Run("unknown groups", func(t *testing.T) {
+		acc := kubernetesAccount(t, "kube", api.URL, "{}")
+		acc.GetSpec().Permissions = interfaces.AccountPermissions{"READ": {"platform", "qa"}, "WRITE": {"plaform"}}
+		r := newTestController(t).Handle(context.TODO(), accountvalidatingtest.NewAccountAdmissionRequest(acc, admissionv1.Create))
+		assert.True(t, r.Allowed)
+		assert.Equal(t, []string{"account kube grants permissions to groups unknown to the identity backend: plaform, qa"}, r.Warnings)
+	})
+
+	t.Run("connectivity disabled", func(t *testing.T) {
+		t.Setenv(connectivityEnv, "false")
+		acc := kubernetesAccount(t, "kube", api.URL, "{}")
+		acc.GetSpec().Permissions = interfaces.AccountPermissions{"READ": {"qa"}}
+		r := newTestController(t).Handle(context.TODO(), accountvalidatingtest.NewAccountAdmissionRequest(acc, admissionv1.Create))
+		assert.True(t, r.Allowed)
+		assert.Empty(t, r.Warnings)
+	})
+}
+
+func TestLoadSettingsIdentityGroups(t *testing.T) {
+	t.Setenv(identityGroupCheckEnv, "true")
+	_, err := loadSettings()
+	assert.EqualError(t, err, "IDENTITY_GROUPS_URL is required when IDENTITY_GROUP_CHECK is enabled")
+}
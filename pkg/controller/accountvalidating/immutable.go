@@ -0,0 +1,93 @@
+package accountvalidating
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
+)
+
+// AllowUpdateAnnotation lets an account be updated when accounts are immutable
+const AllowUpdateAnnotation = "operator.spinnaker.io/allow-update"
+
+// checkImmutable rejects updates changing the spec of the account unless the override annotation is set to true
+func checkImmutable(old, acc interfaces.SpinnakerAccount) error {
+	if acc.GetAnnotations()[AllowUpdateAnnotation] == "true" {
+		return nil
+	}
+	changed, err := changedFields("spec", old.GetSpec(), acc.GetSpec())
+	if err != nil {
+		return internalError(err)
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+	return rejected(ReasonImmutableAccount, fmt.Sprintf("account %s is immutable, changed fields: %s. Set annotation %s: \"true\" to allow the update", acc.GetName(), strings.Join(changed, ", "), AllowUpdateAnnotation))
+}
+
+// checkIdentity rejects updates changing the name or type of the account, Spinnaker would see a new account and
+// orphan the old one
+func checkIdentity(old, acc interfaces.SpinnakerAccount) error {
+	changed := make([]string, 0)
+	if old.GetName() != acc.GetName() {
+		changed = append(changed, fmt.Sprintf("name from %s to %s", old.GetName(), acc.GetName()))
+	}
+	if old.GetSpec().Type != acc.GetSpec().Type {
+		changed = append(changed, fmt.Sprintf("type from %s to %s", old.GetSpec().Type, acc.GetSpec().Type))
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+	return rejected(ReasonAccountIdentityChanged, fmt.Sprintf("account %s cannot change its %s, delete the account and create a new one instead", old.GetName(), strings.Join(changed, " or ")))
+}
+
+// changedFields returns the sorted paths of the fields that differ between the JSON representations of old and new
+func changedFields(path string, old, new interface{}) ([]string, error) {
+	o, err := toJSONValue(old)
+	if err != nil {
+		return nil, err
+	}
+	n, err := toJSONValue(new)
+	if err != nil {
+		return nil, err
+	}
+	changed := diffValues(path, o, n)
+	sort.Strings(changed)
+	return changed, nil
+}
+
+func toJSONValue(obj interface{}) (interface{}, error) {
+	b, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	var v interface{}
+	err = json.Unmarshal(b, &v)
+	return v, err
+}
+
+func diffValues(path string, old, new interface{}) []string {
+	om, oIsMap := old.(map[string]interface{})
+	nm, nIsMap := new.(map[string]interface{})
+	if !oIsMap || !nIsMap {
+		if reflect.DeepEqual(old, new) {
+			return nil
+		}
+		return []string{path}
+	}
+	changed := make([]string, 0)
+	keys := map[string]bool{}
+	for k := range om {
+		keys[k] = true
+	}
+	for k := range nm {
+		keys[k] = true
+	}
+	for k := range keys {
+		changed = append(changed, diffValues(path+"."+k, om[k], nm[k])...)
+	}
+	return changed
+}
@@ -0,0 +1,80 @@
+package accountvalidating
+
+import (
+	"context"
+	"testing"
+
+	"github.com/armory/spinnaker-operator/pkg/controller/accountvalidating/accountvalidatingtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleImmutableAccounts(t *testing.T) {
+	api := newFakeKubernetesAPI(t)
+	old := kubernetesAccount(t, "kube", api.URL, "cacheThreads: 2")
+	t.Setenv(immutableEnv, "true")
+
+	t.Run("unchanged update", func(t *testing.T) {
+		acc := old.DeepCopySpinnakerAccount()
+		acc.SetLabels(map[string]string{"team": "platform"})
+		v := newTestController(t)
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountUpdateAdmissionRequest(old, acc))
+		assert.True(t, r.Allowed)
+	})
+
+	t.Run("changed update", func(t *testing.T) {
+		acc := kubernetesAccount(t, "kube", api.URL, "cacheThreads: 4")
+		acc.GetSpec().Enabled = false
+		v := newTestController(t)
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountUpdateAdmissionRequest(old, acc))
+		assert.False(t, r.Allowed)
+		assert.Equal(t, ReasonImmutableAccount, r.Result.Reason)
+		assert.Equal(t, `account kube is immutable, changed fields: spec.enabled, spec.settings.cacheThreads. Set annotation operator.spinnaker.io/allow-update: "true" to allow the update`, r.Result.Message)
+	})
+
+	t.Run("override annotation", func(t *testing.T) {
+		acc := kubernetesAccount(t, "kube", api.URL, "cacheThreads: 4")
+		acc.SetAnnotations(map[string]string{AllowUpdateAnnotation: "true"})
+		v := newTestController(t)
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountUpdateAdmissionRequest(old, acc))
+		assert.True(t, r.Allowed)
+	})
+
+	t.Run("mutable accounts", func(t *testing.T) {
+		t.Setenv(immutableEnv, "false")
+		acc := kubernetesAccount(t, "kube", api.URL, "cacheThreads: 4")
+		v := newTestController(t)
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountUpdateAdmissionRequest(old, acc))
+		assert.True(t, r.Allowed)
+	})
+}
+
+func TestHandleAccountIdentityChanges(t *testing.T) {
+	api := newFakeKubernetesAPI(t)
+	old := kubernetesAccount(t, "kube", api.URL, "cacheThreads: 2")
+
+	t.Run("name change", func(t *testing.T) {
+		acc := kubernetesAccount(t, "kube2", api.URL, "cacheThreads: 2")
+		v := newTestController(t)
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountUpdateAdmissionRequest(old, acc))
+		assert.False(t, r.Allowed)
+		assert.Equal(t, ReasonAccountIdentityChanged, r.Result.Reason)
+		assert.Equal(t, "account kube cannot change its name from kube to kube2, delete the account and create a new one instead", r.Result.Message)
+	})
+
+	t.Run("type change", func(t *testing.T) {
+		acc := old.DeepCopySpinnakerAccount()
+		acc.GetSpec().Type = "Docker"
+		v := newTestController(t)
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountUpdateAdmissionRequest(old, acc))
+		assert.False(t, r.Allowed)
+		assert.Equal(t, ReasonAccountIdentityChanged, r.Result.Reason)
+		assert.Contains(t, r.Result.Message, "type from Kubernetes to Docker")
+	})
+
+	t.Run("other change", func(t *testing.T) {
+		acc := kubernetesAccount(t, "kube", api.URL, "cacheThreads: 4")
+		v := newTestController(t)
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountUpdateAdmissionRequest(old, acc))
+		assert.True(t, r.Allowed)
+	})
+}
@@ -0,0 +1,88 @@
+package accountvalidating
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/armory/spinnaker-operator/pkg/accounts/account"
+	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
+	"github.com/armory/spinnaker-operator/pkg/util"
+)
+
+// inventoryBackend is the name of the inventory circuit breaker
+const inventoryBackend = "inventory"
+
+// inventoryRequest identifies the account looked up in the inventory
+type inventoryRequest struct {
+	Name        string            `json:"name"`
+	Namespace   string            `json:"namespace"`
+	Type        string            `json:"type"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// inventoryResponse is the answer of the inventory, message explaining why the account isn't registered
+type inventoryResponse struct {
+	Registered *bool  `json:"registered"`
+	Message    string `json:"message,omitempty"`
+}
+
+// checkInventory denies accounts the inventory doesn't know about. The inventory being unreachable or failing is
+// reported as a transient error so clients retry, it isn't called while its circuit breaker is open.
+func (v *accountValidatingController) checkInventory(ctx context.Context, acc interfaces.SpinnakerAccount) error {
+	b := v.breakers.get(inventoryBackend, v.settings)
+	if !b.Allow() {
+		if v.settings.breakerFailOpen {
+			account.Warn(ctx, "account %s was not looked up in the inventory, the inventory is failing", acc.GetName())
+			return nil
+		}
+		return unavailable(fmt.Errorf("unable to look up account %s in the inventory, the inventory is failing: %w", acc.GetName(), util.ErrCircuitOpen))
+	}
+	err := v.queryInventory(ctx, acc)
+	b.Record(isTransient(err))
+	return err
+}
+
+func (v *accountValidatingController) queryInventory(ctx context.Context, acc interfaces.SpinnakerAccount) error {
+	body, err := json.Marshal(inventoryRequest{
+		Name:        acc.GetName(),
+		Namespace:   acc.GetNamespace(),
+		Type:        string(acc.GetSpec().Type),
+		Labels:      acc.GetLabels(),
+		Annotations: acc.GetAnnotations(),
+	})
+	if err != nil {
+		return internalError(err)
+	}
+	svc := &util.HttpService{}
+	req, err := svc.Request(ctx, util.POST, v.settings.inventoryURL, nil, map[string]string{"Content-Type": "application/json"}, bytes.NewReader(body))
+	if err != nil {
+		return internalError(err)
+	}
+	resp, err := svc.Execute(ctx, req)
+	if err != nil {
+		return unavailable(fmt.Errorf("unable to look up account %s in the inventory: %w", acc.GetName(), err))
+	}
+	b, err := svc.ParseResponseBody(resp.Body)
+	if err != nil {
+		return unavailable(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return unavailable(fmt.Errorf("unable to look up account %s in the inventory: %s returned %d", acc.GetName(), v.settings.inventoryURL, resp.StatusCode))
+	}
+	var r inventoryResponse
+	if err := json.Unmarshal(b, &r); err != nil || r.Registered == nil {
+		return unavailable(fmt.Errorf("invalid answer from the inventory for account %s, expected an object with a registered field: %s", acc.GetName(), string(b)))
+	}
+	if *r.Registered {
+		return nil
+	}
+	msg := fmt.Sprintf("account %s of namespace %s is not registered in the inventory", acc.GetName(), acc.GetNamespace())
+	if r.Message != "" {
+		msg += ": " + r.Message
+	}
+	return rejected(ReasonAccountNotRegistered, msg)
+}
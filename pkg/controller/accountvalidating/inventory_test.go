@@ -0,0 +1,67 @@
+package accountvalidating
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/armory/spinnaker-operator/pkg/controller/accountvalidating/accountvalidatingtest"
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+)
+
+// newMockInventory returns an inventory where only account kube of namespace ns1 is registered
+func newMockInventory(t *testing.T) *httptest.Server {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body inventoryRequest
+		if r.Method != http.MethodPost || json.NewDecoder(r.Body).Decode(&body) != nil {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		if body.Name == "kube" && body.Namespace == "ns1" && body.Type == "Kubernetes" {
+			fmt.Fprint(w, `{"registered":true}`)
+			return
+		}
+		fmt.Fprint(w, `{"registered":false,"message":"register it at https://cmdb.example.com"}`)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestHandleInventory(t *testing.T) {
+	api := newFakeKubernetesAPI(t)
+	t.Setenv(inventoryURLEnv, newMockInventory(t).URL)
+
+	t.Run("registered", func(t *testing.T) {
+		acc := kubernetesAccount(t, "kube", api.URL, "{}")
+		r := newTestController(t).Handle(context.TODO(), accountvalidatingtest.NewAccountAdmissionRequest(acc, admissionv1.Create))
+		assert.True(t, r.Allowed)
+	})
+
+	t.Run("unregistered", func(t *testing.T) {
+		acc := kubernetesAccount(t, "unknown", api.URL, "{}")
+		r := newTestController(t).Handle(context.TODO(), accountvalidatingtest.NewAccountAdmissionRequest(acc, admissionv1.Create))
+		assert.False(t, r.Allowed)
+		assert.Equal(t, ReasonAccountNotRegistered, r.Result.Reason)
+		assert.Equal(t, "account unknown of namespace ns1 is not registered in the inventory: register it at https://cmdb.example.com", r.Result.Message)
+		assert.Nil(t, r.Result.Details)
+	})
+
+	t.Run("inventory outage", func(t *testing.T) {
+		down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer down.Close()
+		t.Setenv(inventoryURLEnv, down.URL)
+		acc := kubernetesAccount(t, "kube", api.URL, "{}")
+		r := newTestController(t).Handle(context.TODO(), accountvalidatingtest.NewAccountAdmissionRequest(acc, admissionv1.Create))
+		assert.False(t, r.Allowed)
+		assert.Equal(t, int32(http.StatusServiceUnavailable), r.Result.Code)
+		if assert.NotNil(t, r.Result.Details) {
+			assert.Equal(t, int32(1), r.Result.Details.RetryAfterSeconds)
+		}
+	})
+}
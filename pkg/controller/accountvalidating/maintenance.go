@@ -0,0 +1,25 @@
+package accountvalidating
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MaintenanceAnnotation suspends the validation of the accounts of a SpinnakerService's namespace when set to "true"
+// on the SpinnakerService, e.g. during migrations. Accounts are then admitted with a warning. The annotation is read
+// on each request, so setting or removing it takes effect immediately.
+const MaintenanceAnnotation = "operator.spinnaker.io/account-validation-maintenance"
+
+// maintenance returns the warning to admit accounts of the namespace with when validation is suspended, or an
+// empty string if it isn't
+func (v *accountValidatingController) maintenance(ns string) (string, error) {
+	svc, err := v.getSpinnakerService(ns)
+	if err != nil {
+		return "", fmt.Errorf("unable to get the SpinnakerService of namespace %s: %w", ns, err)
+	}
+	if svc == nil || !strings.EqualFold(svc.GetAnnotations()[MaintenanceAnnotation], "true") {
+		return "", nil
+	}
+	return fmt.Sprintf("account validation is suspended in namespace %s by annotation %s on SpinnakerService %s",
+		ns, MaintenanceAnnotation, svc.GetName()), nil
+}
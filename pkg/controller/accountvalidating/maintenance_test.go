@@ -0,0 +1,49 @@
+package accountvalidating
+
+import (
+	"context"
+	"testing"
+
+	"github.com/armory/spinnaker-operator/pkg/controller/accountvalidating/accountvalidatingtest"
+	"github.com/armory/spinnaker-operator/pkg/test"
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestHandleMaintenance(t *testing.T) {
+	svc := test.TypesFactory.NewService()
+	test.ReadYamlString([]byte(`
+apiVersion: spinnaker.io/v1alpha2
+kind: SpinnakerService
+metadata:
+  name: spinnaker
+  namespace: ns1
+spec:
+  spinnakerConfig:
+    config:
+      version: 1.28.1
+`), svc, t)
+	v := newTestController(t, svc)
+	req := accountvalidatingtest.NewAccountAdmissionRequest(kubernetesAccount(t, "kube", "mycluster.com:6443", "{}"), admissionv1.Create)
+
+	r := v.Handle(context.TODO(), req)
+	assert.False(t, r.Allowed, "enforced")
+
+	setAnnotation := func(value string) {
+		if !assert.Nil(t, v.client.Get(context.TODO(), client.ObjectKeyFromObject(svc), svc)) {
+			return
+		}
+		svc.SetAnnotations(map[string]string{MaintenanceAnnotation: value})
+		assert.Nil(t, v.client.Update(context.TODO(), svc))
+	}
+
+	setAnnotation("true")
+	r = v.Handle(context.TODO(), req)
+	assert.True(t, r.Allowed, "suspended")
+	assert.Equal(t, []string{"account validation is suspended in namespace ns1 by annotation operator.spinnaker.io/account-validation-maintenance on SpinnakerService spinnaker"}, r.Warnings)
+
+	setAnnotation("false")
+	r = v.Handle(context.TODO(), req)
+	assert.False(t, r.Allowed, "enforced again")
+}
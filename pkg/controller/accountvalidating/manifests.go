@@ -0,0 +1,64 @@
+package accountvalidating
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/armory/spinnaker-operator/pkg/accounts"
+	"github.com/armory/spinnaker-operator/pkg/accounts/account"
+	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// handleDelete checks no manifest references a deleted account. Deletions are only sent to the webhook when
+// referencing kinds are configured.
+func (v *accountValidatingController) handleDelete(ctx context.Context, req admission.Request) admission.Response {
+	if !isAccountRequest(req) {
+		return admission.ValidationResponse(true, "")
+	}
+	acc := TypesFactory.NewAccount()
+	if err := v.decoder.DecodeRaw(req.OldObject, acc); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	ctx = account.NewValidationContext(ctx, account.ValidationOptions{Strict: v.settings.strict})
+	if err := v.checkManifestReferences(ctx, acc, "deleted"); err != nil {
+		return v.respond(req, nil, err)
+	}
+	vc, _ := account.ValidationContextFrom(ctx)
+	return v.respond(req, vc.Warnings(), nil)
+}
+
+// checkManifestReferences warns about, or in strict mode rejects, deleting or disabling an account that manifests
+// of the referencing kinds still reference in its namespace
+func (v *accountValidatingController) checkManifestReferences(ctx context.Context, acc interfaces.SpinnakerAccount, action string) error {
+	if len(v.settings.referencingKinds) == 0 {
+		return nil
+	}
+	recordCheck(ctx, manifestReferencesCheck)
+	refs := make([]string, 0)
+	for _, k := range v.settings.referencingKinds {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(k.GroupVersion().WithKind(k.Kind + "List"))
+		if err := v.reader().List(ctx, list, client.InNamespace(acc.GetNamespace())); err != nil {
+			return internalError(fmt.Errorf("unable to list %s in namespace %s: %w", k.Kind, acc.GetNamespace(), err))
+		}
+		for _, o := range list.Items {
+			for _, p := range accounts.FindManifestReferences(o.Object, k.Paths, acc.GetName()) {
+				refs = append(refs, fmt.Sprintf("%s %s at %s", k.Kind, o.GetName(), p))
+			}
+		}
+	}
+	if len(refs) == 0 {
+		return nil
+	}
+	msg := fmt.Sprintf("account %s is being %s but manifests still reference it: %s", acc.GetName(), action, strings.Join(refs, ", "))
+	if v.settings.strict {
+		return rejected(ReasonAccountInUse, msg)
+	}
+	account.Warn(ctx, msg)
+	return nil
+}
@@ -0,0 +1,61 @@
+package accountvalidating
+
+import (
+	"context"
+	"testing"
+
+	"github.com/armory/spinnaker-operator/pkg/controller/accountvalidating/accountvalidatingtest"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestHandleManifestReferences(t *testing.T) {
+	api := newFakeKubernetesAPI(t)
+	pipeline := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "pipeline", Namespace: "ns1"},
+		Data:       map[string]string{"account": "kube1"},
+	}
+	other := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "ns2"},
+		Data:       map[string]string{"account": "kube1"},
+	}
+	t.Setenv(connectivityEnv, "false")
+	t.Setenv(referencingKindsEnv, "v1/ConfigMap=data.account")
+	expected := "account kube1 is being deleted but manifests still reference it: ConfigMap pipeline at data.account"
+
+	t.Run("delete referenced account", func(t *testing.T) {
+		acc := kubernetesAccount(t, "kube1", api.URL, "{}")
+		v := newTestController(t, acc, pipeline, other)
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountDeleteAdmissionRequest(acc))
+		assert.True(t, r.Allowed)
+		assert.Equal(t, []string{expected}, r.Warnings)
+	})
+
+	t.Run("delete referenced account in strict mode", func(t *testing.T) {
+		t.Setenv(strictEnv, "true")
+		acc := kubernetesAccount(t, "kube1", api.URL, "{}")
+		v := newTestController(t, acc, pipeline)
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountDeleteAdmissionRequest(acc))
+		assert.False(t, r.Allowed)
+		assert.Contains(t, r.Result.Message, expected)
+	})
+
+	t.Run("delete unreferenced account", func(t *testing.T) {
+		acc := kubernetesAccount(t, "kube2", api.URL, "{}")
+		v := newTestController(t, acc, pipeline)
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountDeleteAdmissionRequest(acc))
+		assert.True(t, r.Allowed)
+		assert.Empty(t, r.Warnings)
+	})
+
+	t.Run("disable referenced account", func(t *testing.T) {
+		old := kubernetesAccount(t, "kube1", api.URL, "{}")
+		acc := kubernetesAccount(t, "kube1", api.URL, "{}")
+		acc.GetSpec().Enabled = false
+		v := newTestController(t, old, pipeline)
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountUpdateAdmissionRequest(old, acc))
+		assert.True(t, r.Allowed)
+		assert.Contains(t, r.Warnings, "account kube1 is being disabled but manifests still reference it: ConfigMap pipeline at data.account")
+	})
+}
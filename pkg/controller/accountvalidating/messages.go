@@ -0,0 +1,114 @@
+package accountvalidating
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const defaultLocale = "en"
+
+// catalogs hold the templates of denial messages by locale and reason. Templates are given the Reason and the
+// English Message of the denial. Denials without a template in the catalog keep the English message.
+var catalogs = map[string]map[metav1.StatusReason]string{
+	defaultLocale: {},
+	"es": {
+		ReasonSecretFileNotFound:     "No se encontró el archivo secreto: {{.Message}}",
+		ReasonSecretFileUnreadable:   "No se puede leer el archivo secreto: {{.Message}}",
+		ReasonIncompatibleVersion:    "La cuenta no es compatible con la versión de Spinnaker: {{.Message}}",
+		ReasonAccountTypeNotAllowed:  "El tipo de cuenta no está permitido: {{.Message}}",
+		ReasonInvalidEndpoint:        "Endpoint no válido: {{.Message}}",
+		ReasonReservedMetadataKey:    "La cuenta usa claves reservadas por Spinnaker: {{.Message}}",
+		ReasonCredentialExpired:      "Las credenciales han expirado: {{.Message}}",
+		ReasonDuplicateAccountName:   "Nombre de cuenta duplicado: {{.Message}}",
+		ReasonInvalidAccountGroup:    "Grupo de cuentas no válido: {{.Message}}",
+		ReasonMalformedSecret:        "Secreto mal formado: {{.Message}}",
+		ReasonServiceAccountNotFound: "No se encontró la cuenta de servicio: {{.Message}}",
+		ReasonImmutableAccount:       "La cuenta es inmutable: {{.Message}}",
+		ReasonAccountIdentityChanged: "No se puede cambiar el nombre ni el tipo de la cuenta: {{.Message}}",
+		ReasonPrivilegedSecretShared: "La cuenta usa las credenciales de una cuenta privilegiada: {{.Message}}",
+		ReasonNamespaceInUse:         "Los namespaces eliminados siguen en uso: {{.Message}}",
+		ReasonMissingRequiredField:   "Falta un campo obligatorio: {{.Message}}",
+		ReasonNonCanonicalValue:      "Valor no canónico: {{.Message}}",
+		ReasonObjectTooLarge:         "El objeto es demasiado grande: {{.Message}}",
+		ReasonPolicyDenied:           "Denegado por la política: {{.Message}}",
+		ReasonExecPluginNotAllowed:   "Plugin de credenciales no permitido: {{.Message}}",
+		ReasonCertificateSelfSigned:  "El certificado del endpoint es autofirmado: {{.Message}}",
+		ReasonCertificateExpired:     "El certificado del endpoint ha expirado: {{.Message}}",
+		ReasonCertificateHostname:    "El certificado del endpoint no coincide con el host: {{.Message}}",
+		ReasonCertificateUnknownCA:   "El certificado del endpoint está firmado por una autoridad desconocida: {{.Message}}",
+		ReasonProxyUnreachable:       "No se puede conectar al proxy: {{.Message}}",
+		ReasonEndpointUnreachable:    "No se puede conectar al endpoint: {{.Message}}",
+		ReasonSchemaViolation:        "La cuenta no cumple el esquema de su tipo: {{.Message}}",
+		ReasonAccountInUse:           "La cuenta sigue en uso: {{.Message}}",
+		ReasonValueOutOfRange:        "Valor fuera de rango: {{.Message}}",
+		ReasonInvalidLabelSelector:   "Selector de etiquetas no válido: {{.Message}}",
+		ReasonNamespaceNotAllowed:    "No se permiten cuentas en este namespace: {{.Message}}",
+		ReasonCredentialMismatch:     "Las credenciales no corresponden al tipo de cuenta: {{.Message}}",
+		ReasonEmptySecret:            "El secreto referenciado está vacío: {{.Message}}",
+		ReasonAccountNotRegistered:   "La cuenta no está registrada en el inventario: {{.Message}}",
+		ReasonConflictingSettings:    "La cuenta habilita opciones incompatibles: {{.Message}}",
+		ReasonUpdateRateExceeded:     "La cuenta se actualiza con demasiada frecuencia: {{.Message}}",
+		ReasonFeatureSunset:          "La cuenta usa opciones retiradas: {{.Message}}",
+		ReasonCrossNamespaceSecret:   "La cuenta usa un secreto de otro namespace: {{.Message}}",
+	},
+}
+
+// messageCatalog renders denial messages in a locale
+type messageCatalog map[metav1.StatusReason]*template.Template
+
+// loadCatalog returns the catalog of the locale, e.g. "es" or "es_ES.UTF-8", defaulting to English if empty
+func loadCatalog(locale string) (messageCatalog, error) {
+	lang := defaultLocale
+	if parts := strings.FieldsFunc(locale, func(r rune) bool { return r == '_' || r == '-' || r == '.' }); len(parts) > 0 {
+		lang = strings.ToLower(parts[0])
+	}
+	templates, ok := catalogs[lang]
+	if !ok {
+		return nil, fmt.Errorf("unsupported %s \"%s\", supported locales are %s", localeEnv, locale, strings.Join(supportedLocales(), ", "))
+	}
+	c := messageCatalog{}
+	for reason, text := range templates {
+		t, err := template.New(string(reason)).Parse(text)
+		if err != nil {
+			return nil, err
+		}
+		c[reason] = t
+	}
+	return c, nil
+}
+
+func supportedLocales() []string {
+	l := make([]string, 0, len(catalogs))
+	for locale := range catalogs {
+		l = append(l, locale)
+	}
+	sort.Strings(l)
+	return l
+}
+
+// localize renders the message of the response with the template of its reason, if any
+func (c messageCatalog) localize(r admission.Response) admission.Response {
+	if r.Result == nil {
+		return r
+	}
+	t, ok := c[r.Result.Reason]
+	if !ok {
+		return r
+	}
+	b := &bytes.Buffer{}
+	if err := t.Execute(b, struct {
+		Reason  metav1.StatusReason
+		Message string
+	}{r.Result.Reason, r.Result.Message}); err != nil {
+		log.Error(err, "Unable to localize denial message", "reason", r.Result.Reason)
+		return r
+	}
+	r.Result.Message = b.String()
+	return r
+}
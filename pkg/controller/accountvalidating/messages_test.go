@@ -0,0 +1,56 @@
+package accountvalidating
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/armory/spinnaker-operator/pkg/controller/accountvalidating/accountvalidatingtest"
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestHandleLocalizedDenial(t *testing.T) {
+	api := newFakeKubernetesAPI(t)
+	acc := kubernetesAccount(t, "kube", api.URL, "{}")
+	t.Setenv(allowedAccountTypesEnv, "Docker,AWS")
+
+	cases := []struct {
+		name     string
+		locale   string
+		expected string
+	}{
+		{"default locale", "", "account type Kubernetes is not allowed in this cluster, allowed types are Docker, AWS"},
+		{"spanish", "es", "El tipo de cuenta no está permitido: account type Kubernetes is not allowed in this cluster, allowed types are Docker, AWS"},
+		{"spanish with region and encoding", "es_ES.UTF-8", "El tipo de cuenta no está permitido: account type Kubernetes is not allowed in this cluster, allowed types are Docker, AWS"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			t.Setenv(localeEnv, c.locale)
+			v := newTestController(t)
+			s, err := loadSettings()
+			if !assert.Nil(t, err) {
+				return
+			}
+			v.settings = s
+			r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountAdmissionRequest(acc, admissionv1.Create))
+			assert.False(t, r.Allowed)
+			assert.Equal(t, ReasonAccountTypeNotAllowed, r.Result.Reason)
+			assert.Equal(t, c.expected, r.Result.Message)
+		})
+	}
+}
+
+func TestLoadCatalog(t *testing.T) {
+	c, err := loadCatalog("es-MX")
+	if !assert.Nil(t, err) {
+		return
+	}
+	r := c.localize(invalid(errors.New("unknown failure")))
+	assert.Equal(t, metav1.StatusReasonInvalid, r.Result.Reason)
+	assert.Equal(t, "unknown failure", r.Result.Message, "reasons without a template keep the English message")
+
+	_, err = loadCatalog("xx")
+	assert.EqualError(t, err, "unsupported WEBHOOK_LOCALE \"xx\", supported locales are en, es")
+}
@@ -0,0 +1,31 @@
+package accountvalidating
+
+import (
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// reservedKeys returns the sorted labels and annotations of the object starting with one of the reserved prefixes
+func reservedKeys(obj metav1.Object, prefixes []string) []string {
+	keys := make([]string, 0)
+	for _, m := range []map[string]string{obj.GetLabels(), obj.GetAnnotations()} {
+		for k := range m {
+			if hasAnyPrefix(k, prefixes) {
+				keys = append(keys, k)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+package accountvalidating
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/armory/spinnaker-operator/pkg/controller/webhook"
+	"github.com/armory/spinnaker-operator/pkg/util"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// Namespace policies, see namespacePolicyEnv
+const (
+	// anyNamespacePolicy admits accounts in any namespace
+	anyNamespacePolicy = "any"
+	// operatorNamespacePolicy only admits accounts in the operator's namespace
+	operatorNamespacePolicy = "operator"
+	// allowlistNamespacePolicy only admits accounts in the namespaces of allowedNamespacesEnv
+	allowlistNamespacePolicy = "allowlist"
+)
+
+// namespacesFromPolicy returns the namespaces accounts can be created in under the policy read from the environment,
+// nil if accounts can be created in any namespace
+func namespacesFromPolicy() ([]string, error) {
+	allowed := util.ListFromEnv(allowedNamespacesEnv)
+	switch p := strings.ToLower(os.Getenv(namespacePolicyEnv)); p {
+	case "", anyNamespacePolicy, operatorNamespacePolicy:
+		if len(allowed) > 0 {
+			return nil, fmt.Errorf("%s can only be set when %s is %s", allowedNamespacesEnv, namespacePolicyEnv, allowlistNamespacePolicy)
+		}
+		if p != operatorNamespacePolicy {
+			return nil, nil
+		}
+		ns, err := webhook.OperatorNamespace()
+		if err != nil {
+			return nil, err
+		}
+		return []string{ns}, nil
+	case allowlistNamespacePolicy:
+		if len(allowed) == 0 {
+			return nil, fmt.Errorf("%s is required when %s is %s", allowedNamespacesEnv, namespacePolicyEnv, allowlistNamespacePolicy)
+		}
+		return allowed, nil
+	default:
+		return nil, fmt.Errorf("invalid %s \"%s\": expected %s, %s or %s", namespacePolicyEnv, p, anyNamespacePolicy, operatorNamespacePolicy, allowlistNamespacePolicy)
+	}
+}
+
+// checkNamespacePolicy returns an error if the policy doesn't allow accounts in the namespace of the request. Unlike
+// other checks, it can't be suspended with MaintenanceAnnotation.
+func (v *accountValidatingController) checkNamespacePolicy(req admission.Request) error {
+	if v.settings.allowedNamespaces == nil {
+		return nil
+	}
+	for _, ns := range v.settings.allowedNamespaces {
+		if ns == req.Namespace {
+			return nil
+		}
+	}
+	return rejected(ReasonNamespaceNotAllowed, fmt.Sprintf("%s %s can't be created in namespace %s, accounts can only be created in %s (%s). Create it in one of these namespaces",
+		strings.ToLower(req.Kind.Kind), req.Name, req.Namespace, strings.Join(v.settings.allowedNamespaces, ", "), namespacePolicyEnv))
+}
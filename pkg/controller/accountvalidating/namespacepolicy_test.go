@@ -0,0 +1,66 @@
+package accountvalidating
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/armory/spinnaker-operator/pkg/controller/accountvalidating/accountvalidatingtest"
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+)
+
+func TestHandleNamespacePolicy(t *testing.T) {
+	api := newFakeKubernetesAPI(t)
+	acc := kubernetesAccount(t, "kube", api.URL, "{}")
+
+	cases := []struct {
+		name    string
+		policy  string
+		allowed string
+		admit   bool
+	}{
+		{"any namespace", "", "", true},
+		{"operator namespace", "operator", "", true},
+		{"allowlisted namespace", "allowlist", "accounts,ns1", true},
+		{"outside of the operator namespace", "operator", "", false},
+		{"namespace not allowlisted", "allowlist", "accounts", false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			t.Setenv(namespacePolicyEnv, c.policy)
+			t.Setenv(allowedNamespacesEnv, c.allowed)
+			if c.admit {
+				t.Setenv("ADMISSION_PROXY_NAMESPACE", "ns1")
+			} else {
+				t.Setenv("ADMISSION_PROXY_NAMESPACE", "spinnaker-operator")
+			}
+			v := newTestController(t)
+			r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountAdmissionRequest(acc, admissionv1.Create))
+			assert.Equal(t, c.admit, r.Allowed)
+			if !c.admit {
+				assert.Equal(t, int32(http.StatusForbidden), r.Result.Code)
+				assert.Equal(t, ReasonNamespaceNotAllowed, r.Result.Reason)
+				assert.Contains(t, r.Result.Message, "spinnakeraccount kube can't be created in namespace ns1, accounts can only be created in ")
+			}
+		})
+	}
+}
+
+func TestNamespacesFromPolicyInvalid(t *testing.T) {
+	cases := []struct {
+		policy  string
+		allowed string
+	}{
+		{"allowlist", ""},
+		{"operator", "accounts"},
+		{"", "accounts"},
+		{"nowhere", ""},
+	}
+	for _, c := range cases {
+		t.Setenv(namespacePolicyEnv, c.policy)
+		t.Setenv(allowedNamespacesEnv, c.allowed)
+		_, err := namespacesFromPolicy()
+		assert.NotNil(t, err, c)
+	}
+}
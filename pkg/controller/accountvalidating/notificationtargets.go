@@ -0,0 +1,80 @@
+package accountvalidating
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/armory/spinnaker-operator/pkg/accounts/account"
+	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
+	"github.com/armory/spinnaker-operator/pkg/inspect"
+	"github.com/armory/spinnaker-operator/pkg/util"
+)
+
+type dryRunKey struct{}
+
+// withDryRun records that the request is a dry run, which must not have side effects on other systems
+func withDryRun(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dryRunKey{}, true)
+}
+
+func isDryRun(ctx context.Context) bool {
+	d, _ := ctx.Value(dryRunKey{}).(bool)
+	return d
+}
+
+// notificationTarget is a URL notified by Spinnaker for the account, e.g. a build trigger
+type notificationTarget struct {
+	setting string
+	url     string
+}
+
+// notificationTargets returns the URLs set in the given settings of spec.settings, each holding a URL or a list of URLs
+func notificationTargets(acc interfaces.SpinnakerAccount, names []string) []notificationTarget {
+	targets := make([]notificationTarget, 0)
+	settings := acc.GetSpec().Settings
+	for _, n := range names {
+		if u, err := inspect.GetRawObjectPropString(settings, n); err == nil && u != "" {
+			targets = append(targets, notificationTarget{setting: n, url: u})
+			continue
+		}
+		urls, _ := inspect.GetStringArray(settings, n)
+		for _, u := range urls {
+			targets = append(targets, notificationTarget{setting: n, url: u})
+		}
+	}
+	return targets
+}
+
+// checkNotificationTargets warns about the notification targets of the account not answering a HEAD request.
+// Targets aren't probed without connectivity or for dry runs.
+func (v *accountValidatingController) checkNotificationTargets(ctx context.Context, acc interfaces.SpinnakerAccount) {
+	targets := notificationTargets(acc, v.settings.notificationTargetSettings)
+	if len(targets) == 0 || isDryRun(ctx) || !account.ConnectivityEnabled(ctx) {
+		return
+	}
+	recordCheck(ctx, notificationTargetsCheck)
+	for _, t := range targets {
+		if err := pingTarget(ctx, t.url); err != nil {
+			account.Warn(ctx, "notification target %s of account %s (spec.settings.%s) is unreachable: %v", t.url, acc.GetName(), t.setting, err)
+		}
+	}
+}
+
+// pingTarget sends a HEAD request to the URL, failing if it gets no answer or a server error
+func pingTarget(ctx context.Context, url string) error {
+	svc := &util.HttpService{}
+	req, err := svc.Request(ctx, util.HEAD, url, nil, nil, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := svc.Execute(ctx, req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("%s returned %d", url, resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package accountvalidating
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/armory/spinnaker-operator/pkg/controller/accountvalidating/accountvalidatingtest"
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+)
+
+func TestHandleNotificationTargets(t *testing.T) {
+	api := newFakeKubernetesAPI(t)
+	pings := 0
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pings++
+		assert.Equal(t, http.MethodHead, r.Method)
+	}))
+	defer target.Close()
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+	t.Setenv(notificationTargetsEnv, "buildTrigger.url,notificationUrls")
+
+	t.Run("reachable target", func(t *testing.T) {
+		pings = 0
+		v := newTestController(t)
+		acc := kubernetesAccount(t, "kube", api.URL, fmt.Sprintf("{buildTrigger: {url: %s}}", target.URL))
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountAdmissionRequest(acc, admissionv1.Create))
+		assert.True(t, r.Allowed)
+		assert.Empty(t, r.Warnings)
+		assert.Equal(t, 1, pings)
+	})
+
+	t.Run("unreachable target", func(t *testing.T) {
+		v := newTestController(t)
+		acc := kubernetesAccount(t, "kube", api.URL, fmt.Sprintf("{notificationUrls: [%s, %s]}", target.URL, down.URL))
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountAdmissionRequest(acc, admissionv1.Create))
+		assert.True(t, r.Allowed)
+		if assert.Len(t, r.Warnings, 1) {
+			assert.Contains(t, r.Warnings[0], fmt.Sprintf("notification target %s of account kube (spec.settings.notificationUrls) is unreachable", down.URL))
+		}
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		pings = 0
+		v := newTestController(t)
+		acc := kubernetesAccount(t, "kube", api.URL, fmt.Sprintf("{notificationUrls: [%s, %s]}", target.URL, down.URL))
+		req := accountvalidatingtest.NewAccountAdmissionRequest(acc, admissionv1.Create)
+		dryRun := true
+		req.DryRun = &dryRun
+		r := v.Handle(context.TODO(), req)
+		assert.True(t, r.Allowed)
+		assert.Empty(t, r.Warnings)
+		assert.Equal(t, 0, pings)
+	})
+
+	t.Run("without connectivity", func(t *testing.T) {
+		t.Setenv(connectivityEnv, "false")
+		v := newTestController(t)
+		acc := kubernetesAccount(t, "kube", api.URL, fmt.Sprintf("{notificationUrls: [%s]}", down.URL))
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountAdmissionRequest(acc, admissionv1.Create))
+		assert.True(t, r.Allowed)
+		assert.Empty(t, r.Warnings)
+	})
+}
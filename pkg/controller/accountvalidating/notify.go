@@ -0,0 +1,160 @@
+package accountvalidating
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"text/template"
+	"time"
+
+	"github.com/armory/spinnaker-operator/pkg/util"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	defaultNotifyQueueSize   = 100
+	defaultNotifyMaxAttempts = 5
+	// notifyTimeout bounds the time spent on a single delivery attempt
+	notifyTimeout = 10 * time.Second
+)
+
+// notifyInitialBackoff is the wait before the first retry of a delivery, doubled on each retry
+var notifyInitialBackoff = time.Second
+
+// errPermanentDelivery is returned for deliveries rejected by the endpoint, which aren't retried
+var errPermanentDelivery = errors.New("delivery rejected")
+
+// notifyFuncs are the functions of notification templates, json quoting values for JSON payloads
+var notifyFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+// parseNotifyTemplate parses the payload template of notifications, the JSON decision record if empty
+func parseNotifyTemplate(t string) (*template.Template, error) {
+	if t == "" {
+		t = "{{json .}}"
+	}
+	return template.New("notification").Funcs(notifyFuncs).Parse(t)
+}
+
+// notifier delivers the decisions matching its reasons and outcomes to an HTTP endpoint in the background. Failed
+// deliveries are retried with an exponential backoff, decisions are dropped when the queue is full.
+type notifier struct {
+	url         string
+	tmpl        *template.Template
+	reasons     map[string]bool
+	outcomes    map[string]bool
+	maxAttempts int
+	queue       chan auditRecord
+}
+
+func newNotifier(s settings) *notifier {
+	if s.notifyURL == "" {
+		return nil
+	}
+	n := &notifier{
+		url:         s.notifyURL,
+		tmpl:        s.notifyTemplate,
+		reasons:     map[string]bool{},
+		outcomes:    map[string]bool{},
+		maxAttempts: s.notifyMaxAttempts,
+		queue:       make(chan auditRecord, defaultNotifyQueueSize),
+	}
+	for _, r := range s.notifyReasons {
+		n.reasons[r] = true
+	}
+	for _, o := range s.notifyOutcomes {
+		n.outcomes[o] = true
+	}
+	return n
+}
+
+// matches returns true for records with one of the outcomes and reasons notified, all reasons are notified if none
+// is configured
+func (n *notifier) matches(rec auditRecord) bool {
+	return n.outcomes[rec.Outcome] && (len(n.reasons) == 0 || n.reasons[rec.Reason])
+}
+
+// decided enqueues the decision if it's notified
+func (n *notifier) decided(req admission.Request, r admission.Response) {
+	rec := newAuditRecord(req, r)
+	if !n.matches(rec) {
+		return
+	}
+	select {
+	case n.queue <- rec:
+	default:
+		log.Info("Notification queue is full, dropping notification", "uid", req.UID, "namespace", req.Namespace, "name", req.Name)
+	}
+}
+
+// Start delivers queued notifications until the context is done
+func (n *notifier) Start(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case rec := <-n.queue:
+			if err := n.deliver(ctx, rec); err != nil {
+				log.Error(err, "Unable to deliver notification", "uid", rec.UID, "namespace", rec.Namespace, "name", rec.Name)
+			}
+		}
+	}
+}
+
+// NeedLeaderElection returns false, all replicas answer admission requests
+func (n *notifier) NeedLeaderElection() bool {
+	return false
+}
+
+// deliver sends the notification, retrying transient failures
+func (n *notifier) deliver(ctx context.Context, rec auditRecord) error {
+	var payload bytes.Buffer
+	if err := n.tmpl.Execute(&payload, rec); err != nil {
+		return fmt.Errorf("unable to render notification: %w", err)
+	}
+	backoff := notifyInitialBackoff
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = n.send(ctx, payload.Bytes()); err == nil || errors.Is(err, errPermanentDelivery) || attempt >= n.maxAttempts {
+			return err
+		}
+		log.V(2).Info("Retrying notification", "uid", rec.UID, "attempt", attempt, "error", err.Error())
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+func (n *notifier) send(ctx context.Context, payload []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, notifyTimeout)
+	defer cancel()
+	svc := &util.HttpService{}
+	req, err := svc.Request(ctx, util.POST, n.url, nil, map[string]string{"Content-Type": "application/json"}, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("%w: %v", errPermanentDelivery, err)
+	}
+	resp, err := svc.Execute(ctx, req)
+	if err != nil {
+		return err
+	}
+	if _, err := svc.ParseResponseBody(resp.Body); err != nil {
+		return err
+	}
+	switch {
+	case resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests:
+		return fmt.Errorf("%s returned %d", n.url, resp.StatusCode)
+	case resp.StatusCode >= http.StatusMultipleChoices:
+		return fmt.Errorf("%w: %s returned %d", errPermanentDelivery, n.url, resp.StatusCode)
+	}
+	return nil
+}
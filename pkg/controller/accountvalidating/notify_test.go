@@ -0,0 +1,77 @@
+package accountvalidating
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/armory/spinnaker-operator/pkg/controller/accountvalidating/accountvalidatingtest"
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+)
+
+func TestHandleNotify(t *testing.T) {
+	api := newFakeKubernetesAPI(t)
+	// the endpoint fails the first delivery
+	var calls int32
+	deliveries := make(chan string, 10)
+	endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		b, _ := ioutil.ReadAll(r.Body)
+		deliveries <- string(b)
+	}))
+	defer endpoint.Close()
+	backoff := notifyInitialBackoff
+	notifyInitialBackoff = time.Millisecond
+	defer func() { notifyInitialBackoff = backoff }()
+
+	t.Setenv(connectivityEnv, "false")
+	t.Setenv(allowedAccountTypesEnv, "Kubernetes")
+	t.Setenv(notifyURLEnv, endpoint.URL)
+	t.Setenv(notifyReasonsEnv, string(ReasonAccountTypeNotAllowed))
+	t.Setenv(notifyTemplateEnv, `{"text": {{json (printf "account %s/%s denied: %s" .Namespace .Name .Reason)}}}`)
+	v := newTestController(t)
+	n := newNotifier(v.settings)
+	v.hooks = []decisionHook{n}
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	go n.Start(ctx)
+
+	// allowed accounts aren't notified
+	r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountAdmissionRequest(kubernetesAccount(t, "kube", api.URL, "{}"), admissionv1.Create))
+	assert.True(t, r.Allowed)
+	assert.Len(t, n.queue, 0)
+
+	acc := kubernetesAccount(t, "aws", api.URL, "{}")
+	acc.GetSpec().Type = "AWS"
+	r = v.Handle(context.TODO(), accountvalidatingtest.NewAccountAdmissionRequest(acc, admissionv1.Create))
+	assert.False(t, r.Allowed)
+
+	select {
+	case d := <-deliveries:
+		assert.Equal(t, `{"text": "account ns1/aws denied: AccountTypeNotAllowed"}`, d)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	case <-time.After(5 * time.Second):
+		t.Fatal("no notification delivered")
+	}
+}
+
+func TestNotifierPermanentFailure(t *testing.T) {
+	var calls int32
+	endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer endpoint.Close()
+	t.Setenv(notifyURLEnv, endpoint.URL)
+	n := newNotifier(newTestController(t).settings)
+	assert.NotNil(t, n.deliver(context.TODO(), auditRecord{Outcome: "denied"}))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
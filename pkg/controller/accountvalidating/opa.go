@@ -0,0 +1,102 @@
+package accountvalidating
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/armory/spinnaker-operator/pkg/accounts/account"
+	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
+	"github.com/armory/spinnaker-operator/pkg/util"
+)
+
+// opaDecision is the result of an OPA query. Policies can return a boolean, a list of deny reasons,
+// or an object with an allow field and reasons.
+type opaDecision struct {
+	allowed bool
+	reasons []string
+}
+
+func (d *opaDecision) UnmarshalJSON(b []byte) error {
+	var allowed bool
+	if err := json.Unmarshal(b, &allowed); err == nil {
+		d.allowed = allowed
+		return nil
+	}
+	var reasons []string
+	if err := json.Unmarshal(b, &reasons); err == nil {
+		d.allowed = len(reasons) == 0
+		d.reasons = reasons
+		return nil
+	}
+	var obj struct {
+		Allow   *bool    `json:"allow"`
+		Reasons []string `json:"reasons"`
+	}
+	if err := json.Unmarshal(b, &obj); err != nil || obj.Allow == nil {
+		return fmt.Errorf("expected a boolean, a list of deny reasons or an object with an allow field, got %s", string(b))
+	}
+	d.allowed = *obj.Allow
+	d.reasons = obj.Reasons
+	return nil
+}
+
+// checkPolicy queries OPA with the account as input, denying the account if the policy doesn't allow it. OPA isn't
+// queried while its circuit breaker is open.
+func (v *accountValidatingController) checkPolicy(ctx context.Context, acc interfaces.SpinnakerAccount) error {
+	b := v.breakers.get(opaBackend, v.settings)
+	if !b.Allow() {
+		if v.settings.breakerFailOpen {
+			account.Warn(ctx, "OPA policy %s was not checked for account %s, OPA is failing", v.settings.opaQueryPath, acc.GetName())
+			return nil
+		}
+		return internalError(fmt.Errorf("unable to query OPA policy %s, OPA is failing: %w", v.settings.opaQueryPath, util.ErrCircuitOpen))
+	}
+	err := v.queryPolicy(ctx, acc)
+	b.Record(isTransient(err))
+	return err
+}
+
+func (v *accountValidatingController) queryPolicy(ctx context.Context, acc interfaces.SpinnakerAccount) error {
+	input, err := json.Marshal(map[string]interface{}{"input": acc})
+	if err != nil {
+		return internalError(err)
+	}
+	svc := &util.HttpService{}
+	url := strings.TrimSuffix(v.settings.opaURL, "/") + "/v1/data/" + v.settings.opaQueryPath
+	req, err := svc.Request(ctx, util.POST, url, nil, map[string]string{"Content-Type": "application/json"}, bytes.NewReader(input))
+	if err != nil {
+		return internalError(err)
+	}
+	resp, err := svc.Execute(ctx, req)
+	if err != nil {
+		return internalError(fmt.Errorf("unable to query OPA policy %s: %w", v.settings.opaQueryPath, err))
+	}
+	body, err := svc.ParseResponseBody(resp.Body)
+	if err != nil {
+		return internalError(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return internalError(fmt.Errorf("unable to query OPA policy %s: %s returned %d", v.settings.opaQueryPath, url, resp.StatusCode))
+	}
+	var r struct {
+		Result *opaDecision `json:"result"`
+	}
+	if err := json.Unmarshal(body, &r); err != nil {
+		return internalError(fmt.Errorf("invalid decision from OPA policy %s: %w", v.settings.opaQueryPath, err))
+	}
+	if r.Result == nil {
+		return internalError(fmt.Errorf("OPA policy %s is undefined", v.settings.opaQueryPath))
+	}
+	if r.Result.allowed {
+		return nil
+	}
+	msg := fmt.Sprintf("account %s is denied by policy %s", acc.GetName(), v.settings.opaQueryPath)
+	if len(r.Result.reasons) > 0 {
+		msg += ": " + strings.Join(r.Result.reasons, "; ")
+	}
+	return rejected(ReasonPolicyDenied, msg)
+}
@@ -0,0 +1,93 @@
+package accountvalidating
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/armory/spinnaker-operator/pkg/controller/accountvalidating/accountvalidatingtest"
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+)
+
+// newMockOPA returns an OPA server denying accounts without a team label
+func newMockOPA(t *testing.T) *httptest.Server {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/data/spinnaker/accounts/decision" {
+			http.NotFound(w, r)
+			return
+		}
+		var body struct {
+			Input struct {
+				Metadata struct {
+					Labels map[string]string `json:"labels"`
+				} `json:"metadata"`
+			} `json:"input"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if body.Input.Metadata.Labels["team"] == "" {
+			fmt.Fprint(w, `{"result":{"allow":false,"reasons":["accounts must have a team label","ask the platform team for one"]}}`)
+			return
+		}
+		fmt.Fprint(w, `{"result":{"allow":true}}`)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestHandlePolicy(t *testing.T) {
+	api := newFakeKubernetesAPI(t)
+	t.Setenv(opaURLEnv, newMockOPA(t).URL)
+
+	t.Run("allowed", func(t *testing.T) {
+		acc := kubernetesAccount(t, "kube", api.URL, "{}")
+		acc.SetLabels(map[string]string{"team": "platform"})
+		v := newTestController(t)
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountAdmissionRequest(acc, admissionv1.Create))
+		assert.True(t, r.Allowed)
+	})
+
+	t.Run("denied", func(t *testing.T) {
+		acc := kubernetesAccount(t, "kube", api.URL, "{}")
+		v := newTestController(t)
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountAdmissionRequest(acc, admissionv1.Create))
+		assert.False(t, r.Allowed)
+		assert.Equal(t, ReasonPolicyDenied, r.Result.Reason)
+		assert.Equal(t, "account kube is denied by policy spinnaker/accounts/decision: accounts must have a team label; ask the platform team for one", r.Result.Message)
+	})
+
+	t.Run("unknown policy", func(t *testing.T) {
+		t.Setenv(opaQueryPathEnv, "/missing/")
+		acc := kubernetesAccount(t, "kube", api.URL, "{}")
+		v := newTestController(t)
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountAdmissionRequest(acc, admissionv1.Create))
+		assert.False(t, r.Allowed)
+		assert.Equal(t, int32(http.StatusInternalServerError), r.Result.Code)
+	})
+}
+
+func TestOPADecision(t *testing.T) {
+	cases := []struct {
+		result   string
+		expected opaDecision
+	}{
+		{`true`, opaDecision{allowed: true}},
+		{`false`, opaDecision{allowed: false}},
+		{`[]`, opaDecision{allowed: true, reasons: []string{}}},
+		{`["no"]`, opaDecision{allowed: false, reasons: []string{"no"}}},
+		{`{"allow":false,"reasons":["no"]}`, opaDecision{allowed: false, reasons: []string{"no"}}},
+	}
+	for _, c := range cases {
+		var d opaDecision
+		assert.Nil(t, json.Unmarshal([]byte(c.result), &d), c.result)
+		assert.Equal(t, c.expected, d, c.result)
+	}
+	var d opaDecision
+	assert.NotNil(t, json.Unmarshal([]byte(`{"reasons":["no"]}`), &d))
+}
@@ -0,0 +1,41 @@
+package accountvalidating
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// privilegedSecretSharing returns a message for each secret key referenced by the account that a privileged account
+// of the namespace also references. Privileged accounts can share secrets among themselves.
+func (v *accountValidatingController) privilegedSecretSharing(ctx context.Context, acc interfaces.SpinnakerAccount) ([]string, error) {
+	refs := secretRefs(acc)
+	if len(refs) == 0 || v.settings.isPrivileged(acc.GetName()) {
+		return nil, nil
+	}
+	list := TypesFactory.NewAccountList()
+	if err := v.reader().List(ctx, list, client.InNamespace(acc.GetNamespace())); err != nil {
+		return nil, fmt.Errorf("unable to list accounts in namespace %s: %w", acc.GetNamespace(), err)
+	}
+	others := list.GetItems()
+	sort.Slice(others, func(i, j int) bool { return others[i].GetName() < others[j].GetName() })
+
+	msgs := make([]string, 0)
+	for _, o := range others {
+		if o.GetName() == acc.GetName() || !v.settings.isPrivileged(o.GetName()) {
+			continue
+		}
+		for _, r := range refs {
+			for _, or := range secretRefs(o) {
+				if or == r {
+					msgs = append(msgs, fmt.Sprintf("account %s uses key %s of secret %s, which holds the credentials of privileged account %s",
+						acc.GetName(), r.key, r.name, o.GetName()))
+				}
+			}
+		}
+	}
+	return msgs, nil
+}
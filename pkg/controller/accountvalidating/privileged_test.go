@@ -0,0 +1,55 @@
+package accountvalidating
+
+import (
+	"context"
+	"testing"
+
+	"github.com/armory/spinnaker-operator/pkg/accounts"
+	"github.com/armory/spinnaker-operator/pkg/controller/accountvalidating/accountvalidatingtest"
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+)
+
+func TestHandlePrivilegedSecretSharing(t *testing.T) {
+	admin := secretAccount(t, "admin", "kubeconfigs", "admin")
+	t.Setenv(accounts.AsyncValidationEnv, "true")
+	t.Setenv(privilegedAccountsEnv, "admin")
+	background := "account dev will be validated in the background, see its Validated condition"
+	shared := "account dev uses key admin of secret kubeconfigs, which holds the credentials of privileged account admin"
+
+	t.Run("shared with privileged account", func(t *testing.T) {
+		v := newTestController(t, admin)
+		acc := secretAccount(t, "dev", "kubeconfigs", "admin")
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountAdmissionRequest(acc, admissionv1.Create))
+		assert.True(t, r.Allowed)
+		assert.Equal(t, []string{shared, background}, r.Warnings)
+	})
+
+	t.Run("shared with privileged account in strict mode", func(t *testing.T) {
+		t.Setenv(strictEnv, "true")
+		v := newTestController(t, admin)
+		acc := secretAccount(t, "dev", "kubeconfigs", "admin")
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountAdmissionRequest(acc, admissionv1.Create))
+		assert.False(t, r.Allowed)
+		assert.Equal(t, ReasonPrivilegedSecretShared, r.Result.Reason)
+		assert.Equal(t, shared, r.Result.Message)
+	})
+
+	t.Run("clean", func(t *testing.T) {
+		t.Setenv(strictEnv, "true")
+		v := newTestController(t, admin)
+		acc := secretAccount(t, "dev", "kubeconfigs", "dev")
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountAdmissionRequest(acc, admissionv1.Create))
+		assert.True(t, r.Allowed)
+		assert.Equal(t, []string{background}, r.Warnings)
+	})
+
+	t.Run("privileged accounts share secrets", func(t *testing.T) {
+		t.Setenv(privilegedAccountsEnv, "admin,admin2")
+		v := newTestController(t, admin)
+		acc := secretAccount(t, "admin2", "kubeconfigs", "admin")
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountAdmissionRequest(acc, admissionv1.Create))
+		assert.True(t, r.Allowed)
+		assert.Len(t, r.Warnings, 1)
+	})
+}
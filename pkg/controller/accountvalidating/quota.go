@@ -0,0 +1,33 @@
+package accountvalidating
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// checkSoftLimit returns a warning if the account would exceed the soft limit of enabled accounts of its type
+// in the namespace
+func (v *accountValidatingController) checkSoftLimit(ctx context.Context, acc interfaces.SpinnakerAccount) (string, error) {
+	if !acc.GetSpec().Enabled {
+		return "", nil
+	}
+	list := TypesFactory.NewAccountList()
+	if err := v.reader().List(ctx, list, client.InNamespace(acc.GetNamespace())); err != nil {
+		return "", fmt.Errorf("unable to list accounts in namespace %s: %w", acc.GetNamespace(), err)
+	}
+	count := 1
+	for _, o := range list.GetItems() {
+		if o.GetName() != acc.GetName() && o.GetSpec().Enabled && strings.EqualFold(string(o.GetSpec().Type), string(acc.GetSpec().Type)) {
+			count++
+		}
+	}
+	if count <= v.settings.softLimit {
+		return "", nil
+	}
+	return fmt.Sprintf("namespace %s would have %d enabled %s accounts with account %s, exceeding the soft limit of %d (%s). Spinnaker may slow down with many accounts of the same type",
+		acc.GetNamespace(), count, acc.GetSpec().Type, acc.GetName(), v.settings.softLimit, softLimitEnv), nil
+}
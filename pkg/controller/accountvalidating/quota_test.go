@@ -0,0 +1,83 @@
+package accountvalidating
+
+import (
+	"context"
+	"testing"
+
+	"github.com/armory/spinnaker-operator/pkg/controller/accountvalidating/accountvalidatingtest"
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestHandleSoftLimit(t *testing.T) {
+	api := newFakeKubernetesAPI(t)
+	disabled := kubernetesAccount(t, "disabled", api.URL, "{}")
+	disabled.GetSpec().Enabled = false
+	existing := []client.Object{
+		kubernetesAccount(t, "kube1", api.URL, "{}"),
+		kubernetesAccount(t, "kube2", api.URL, "{}"),
+		disabled,
+	}
+	t.Setenv(connectivityEnv, "false")
+
+	t.Run("under limit", func(t *testing.T) {
+		t.Setenv(softLimitEnv, "3")
+		v := newTestController(t, existing...)
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountAdmissionRequest(kubernetesAccount(t, "kube3", api.URL, "{}"), admissionv1.Create))
+		assert.True(t, r.Allowed)
+		assert.Empty(t, r.Warnings)
+	})
+
+	t.Run("over limit", func(t *testing.T) {
+		t.Setenv(softLimitEnv, "2")
+		v := newTestController(t, existing...)
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountAdmissionRequest(kubernetesAccount(t, "kube3", api.URL, "{}"), admissionv1.Create))
+		assert.True(t, r.Allowed)
+		assert.Equal(t, []string{"namespace ns1 would have 3 enabled Kubernetes accounts with account kube3, exceeding the soft limit of 2 (ACCOUNT_SOFT_LIMIT). Spinnaker may slow down with many accounts of the same type"}, r.Warnings)
+	})
+
+	t.Run("update at limit", func(t *testing.T) {
+		t.Setenv(softLimitEnv, "2")
+		v := newTestController(t, existing...)
+		acc := kubernetesAccount(t, "kube1", api.URL, "cacheThreads: 2")
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountUpdateAdmissionRequest(acc, acc))
+		assert.True(t, r.Allowed)
+		assert.Empty(t, r.Warnings)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		t.Setenv(softLimitEnv, "0")
+		v := newTestController(t, existing...)
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountAdmissionRequest(kubernetesAccount(t, "kube3", api.URL, "{}"), admissionv1.Create))
+		assert.True(t, r.Allowed)
+		assert.Empty(t, r.Warnings)
+	})
+}
+
+func TestHandleUncachedReads(t *testing.T) {
+	api := newFakeKubernetesAPI(t)
+	kube1 := kubernetesAccount(t, "kube1", api.URL, "{}")
+	// kube2 was deleted but is still in the cache
+	stale := kubernetesAccount(t, "kube2", api.URL, "{}")
+	t.Setenv(connectivityEnv, "false")
+	t.Setenv(softLimitEnv, "2")
+	req := accountvalidatingtest.NewAccountAdmissionRequest(kubernetesAccount(t, "kube3", api.URL, "{}"), admissionv1.Create)
+
+	t.Run("cached", func(t *testing.T) {
+		v := newTestController(t, kube1, stale)
+		v.apiReader = newTestController(t, kube1).client
+		r := v.Handle(context.TODO(), req)
+		assert.True(t, r.Allowed)
+		assert.Len(t, r.Warnings, 1)
+	})
+
+	t.Run("uncached", func(t *testing.T) {
+		t.Setenv(uncachedReadsEnv, "true")
+		v := newTestController(t, kube1, stale)
+		v.apiReader = newTestController(t, kube1).client
+		r := v.Handle(context.TODO(), req)
+		assert.True(t, r.Allowed)
+		assert.Empty(t, r.Warnings)
+	})
+}
@@ -0,0 +1,20 @@
+package accountvalidating
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// newReadClient returns a client reading from the server of the given kubeconfig, e.g. a read replica or a caching
+// proxy of the API server
+func newReadClient(kubeconfig string, scheme *runtime.Scheme, mapper meta.RESTMapper) (client.Reader, error) {
+	cfg, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load %s \"%s\": %w", readKubeconfigEnv, kubeconfig, err)
+	}
+	return client.New(cfg, client.Options{Scheme: scheme, Mapper: mapper})
+}
@@ -0,0 +1,98 @@
+package accountvalidating
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/armory/spinnaker-operator/pkg/controller/accountvalidating/accountvalidatingtest"
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+func TestHandleReadClient(t *testing.T) {
+	api := newFakeKubernetesAPI(t)
+	t.Setenv(connectivityEnv, "false")
+	t.Setenv(softLimitEnv, "1")
+	req := accountvalidatingtest.NewAccountAdmissionRequest(kubernetesAccount(t, "kube2", api.URL, "{}"), admissionv1.Create)
+
+	var mu sync.Mutex
+	var paths []string
+	replica := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.Method+" "+r.URL.Path)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		kind := "SpinnakerAccountList"
+		if strings.HasSuffix(r.URL.Path, "/spinnakerservices") {
+			kind = "SpinnakerServiceList"
+		}
+		fmt.Fprintf(w, `{"kind":"%s","apiVersion":"spinnaker.io/v1alpha2","metadata":{},"items":[]}`, kind)
+	}))
+	defer replica.Close()
+	kubeconfig := filepath.Join(t.TempDir(), "kubeconfig")
+	if !assert.Nil(t, os.WriteFile(kubeconfig, []byte(fmt.Sprintf(`apiVersion: v1
+kind: Config
+current-context: replica
+clusters:
+- name: replica
+  cluster:
+    server: %s
+contexts:
+- name: replica
+  context:
+    cluster: replica
+    user: operator
+users:
+- name: operator
+  user:
+    token: token
+`, replica.URL)), 0600)) {
+		return
+	}
+
+	// the primary client has another account of the type, exceeding the soft limit
+	v := newTestController(t, kubernetesAccount(t, "kube1", api.URL, "{}"))
+	mapper := meta.NewDefaultRESTMapper(nil)
+	for _, o := range []client.Object{TypesFactory.NewAccount(), TypesFactory.NewService()} {
+		gvk, err := apiutil.GVKForObject(o, v.client.Scheme())
+		if !assert.Nil(t, err) {
+			return
+		}
+		mapper.Add(gvk, meta.RESTScopeNamespace)
+	}
+	var err error
+	if v.readClient, err = newReadClient(kubeconfig, v.client.Scheme(), mapper); !assert.Nil(t, err) {
+		return
+	}
+
+	r := v.Handle(context.TODO(), req)
+	assert.True(t, r.Allowed)
+	assert.Empty(t, r.Warnings)
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Contains(t, paths, "GET /apis/spinnaker.io/v1alpha2/namespaces/ns1/spinnakeraccounts")
+	assert.Subset(t, []string{
+		"GET /apis/spinnaker.io/v1alpha2/namespaces/ns1/spinnakeraccounts",
+		"GET /apis/spinnaker.io/v1alpha2/namespaces/ns1/spinnakerservices",
+	}, paths)
+}
+
+func TestReadKubeconfigSettings(t *testing.T) {
+	t.Setenv(readKubeconfigEnv, "/etc/replica/kubeconfig")
+	t.Setenv(uncachedReadsEnv, "true")
+	_, err := loadSettings()
+	assert.NotNil(t, err)
+
+	_, err = newReadClient(filepath.Join(t.TempDir(), "missing"), nil, nil)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,186 @@
+package accountvalidating
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/armory/spinnaker-operator/pkg/accounts"
+	"github.com/armory/spinnaker-operator/pkg/accounts/account"
+	"github.com/armory/spinnaker-operator/pkg/accounts/kubernetes"
+	"github.com/armory/spinnaker-operator/pkg/accounts/validator"
+	"github.com/armory/spinnaker-operator/pkg/secrets"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	ReasonSecretFileNotFound     metav1.StatusReason = "SecretFileNotFound"
+	ReasonSecretFileUnreadable   metav1.StatusReason = "SecretFileUnreadable"
+	ReasonIncompatibleVersion    metav1.StatusReason = "IncompatibleSpinnakerVersion"
+	ReasonAccountTypeNotAllowed  metav1.StatusReason = "AccountTypeNotAllowed"
+	ReasonInvalidEndpoint        metav1.StatusReason = "InvalidEndpoint"
+	ReasonReservedMetadataKey    metav1.StatusReason = "ReservedMetadataKey"
+	ReasonCredentialExpired      metav1.StatusReason = "CredentialExpired"
+	ReasonDuplicateAccountName   metav1.StatusReason = "DuplicateAccountName"
+	ReasonInvalidAccountGroup    metav1.StatusReason = "InvalidAccountGroup"
+	ReasonMalformedSecret        metav1.StatusReason = "MalformedSecret"
+	ReasonServiceAccountNotFound metav1.StatusReason = "ServiceAccountNotFound"
+	ReasonImmutableAccount       metav1.StatusReason = "ImmutableAccount"
+	ReasonAccountIdentityChanged metav1.StatusReason = "AccountIdentityChanged"
+	ReasonPrivilegedSecretShared metav1.StatusReason = "PrivilegedSecretShared"
+	ReasonNamespaceInUse         metav1.StatusReason = "NamespaceInUse"
+	ReasonMissingRequiredField   metav1.StatusReason = "MissingRequiredField"
+	ReasonNonCanonicalValue      metav1.StatusReason = "NonCanonicalValue"
+	ReasonObjectTooLarge         metav1.StatusReason = "ObjectTooLarge"
+	ReasonPolicyDenied           metav1.StatusReason = "PolicyDenied"
+	ReasonExecPluginNotAllowed   metav1.StatusReason = "ExecPluginNotAllowed"
+	ReasonCertificateSelfSigned  metav1.StatusReason = "EndpointCertificateSelfSigned"
+	ReasonCertificateExpired     metav1.StatusReason = "EndpointCertificateExpired"
+	ReasonCertificateHostname    metav1.StatusReason = "EndpointCertificateHostnameMismatch"
+	ReasonCertificateUnknownCA   metav1.StatusReason = "EndpointCertificateUnknownAuthority"
+	ReasonProxyUnreachable       metav1.StatusReason = "ProxyUnreachable"
+	ReasonEndpointUnreachable    metav1.StatusReason = "EndpointUnreachable"
+	ReasonSchemaViolation        metav1.StatusReason = "SchemaViolation"
+	ReasonAccountInUse           metav1.StatusReason = "AccountInUse"
+	ReasonValueOutOfRange        metav1.StatusReason = "ValueOutOfRange"
+	ReasonInvalidLabelSelector   metav1.StatusReason = "InvalidLabelSelector"
+	ReasonNamespaceNotAllowed    metav1.StatusReason = "NamespaceNotAllowed"
+	ReasonCredentialMismatch     metav1.StatusReason = "CredentialTypeMismatch"
+	ReasonEmptySecret            metav1.StatusReason = "EmptySecret"
+	ReasonAccountNotRegistered   metav1.StatusReason = "AccountNotRegistered"
+	ReasonConflictingSettings    metav1.StatusReason = "ConflictingSettings"
+	ReasonUpdateRateExceeded     metav1.StatusReason = "UpdateRateExceeded"
+	ReasonFeatureSunset          metav1.StatusReason = "FeatureSunset"
+	ReasonCrossNamespaceSecret   metav1.StatusReason = "CrossNamespaceSecret"
+)
+
+// reasonFor maps known validation errors to a stable denial reason
+func reasonFor(err error) metav1.StatusReason {
+	switch {
+	case errors.Is(err, secrets.ErrSecretFileNotFound):
+		return ReasonSecretFileNotFound
+	case errors.Is(err, secrets.ErrSecretFileUnreadable):
+		return ReasonSecretFileUnreadable
+	case errors.Is(err, secrets.ErrEmptySecret):
+		return ReasonEmptySecret
+	case errors.Is(err, secrets.ErrCredentialMismatch):
+		return ReasonCredentialMismatch
+	case errors.Is(err, secrets.ErrMalformedSecret):
+		return ReasonMalformedSecret
+	case errors.Is(err, accounts.ErrIncompatibleVersion):
+		return ReasonIncompatibleVersion
+	case errors.Is(err, accounts.ErrInvalidEndpoint):
+		return ReasonInvalidEndpoint
+	case errors.Is(err, accounts.ErrMissingRequiredField):
+		return ReasonMissingRequiredField
+	case errors.Is(err, accounts.ErrNonCanonicalValue):
+		return ReasonNonCanonicalValue
+	case errors.Is(err, accounts.ErrValueOutOfRange):
+		return ReasonValueOutOfRange
+	case errors.Is(err, accounts.ErrFeatureSunset):
+		return ReasonFeatureSunset
+	case errors.Is(err, accounts.ErrConflictingSettings):
+		return ReasonConflictingSettings
+	case errors.Is(err, accounts.ErrInvalidLabelSelector):
+		return ReasonInvalidLabelSelector
+	case errors.Is(err, accounts.ErrSchemaViolation):
+		return ReasonSchemaViolation
+	case errors.Is(err, account.ErrCredentialExpired):
+		return ReasonCredentialExpired
+	case errors.Is(err, kubernetes.ErrServiceAccountNotFound):
+		return ReasonServiceAccountNotFound
+	case errors.Is(err, kubernetes.ErrExecPluginNotAllowed):
+		return ReasonExecPluginNotAllowed
+	case errors.Is(err, kubernetes.ErrNamespaceInUse):
+		return ReasonNamespaceInUse
+	case errors.Is(err, account.ErrEndpointCertificateSelfSigned):
+		return ReasonCertificateSelfSigned
+	case errors.Is(err, account.ErrEndpointCertificateExpired):
+		return ReasonCertificateExpired
+	case errors.Is(err, account.ErrEndpointCertificateHostnameMismatch):
+		return ReasonCertificateHostname
+	case errors.Is(err, account.ErrEndpointCertificateUnknownAuthority):
+		return ReasonCertificateUnknownCA
+	case errors.Is(err, account.ErrProxyUnreachable):
+		return ReasonProxyUnreachable
+	case errors.Is(err, account.ErrEndpointUnreachable):
+		return ReasonEndpointUnreachable
+	}
+	return metav1.StatusReasonInvalid
+}
+
+// statusError is returned for accounts rejected before or outside of their type's validator
+type statusError struct {
+	code   int32
+	reason metav1.StatusReason
+	err    error
+	// retryAfter is when the client can retry, the retry hint grows with repeated failures if zero
+	retryAfter time.Duration
+}
+
+func (e *statusError) Error() string {
+	return e.err.Error()
+}
+
+func (e *statusError) Unwrap() error {
+	return e.err
+}
+
+// rejected returns an error for a policy enforced by the webhook itself
+func rejected(reason metav1.StatusReason, msg string) error {
+	return &statusError{code: http.StatusForbidden, reason: reason, err: errors.New(msg)}
+}
+
+// badRequest returns an error for an account that can't be read
+func badRequest(err error) error {
+	return &statusError{code: http.StatusBadRequest, err: err}
+}
+
+// internalError returns an error for a failure unrelated to the account
+func internalError(err error) error {
+	return &statusError{code: http.StatusInternalServerError, err: err}
+}
+
+// responseFor returns the admission response for an error returned by validate, with the fixes suggested for it
+func responseFor(err error) admission.Response {
+	r := statusResponseFor(err)
+	if s := account.SuggestionsFrom(err); len(s) > 0 && r.Result != nil {
+		r.Result.Message = fmt.Sprintf("%s\nsuggested fix (JSON patch): %s", r.Result.Message, account.FormatSuggestions(s))
+	}
+	return r
+}
+
+func statusResponseFor(err error) admission.Response {
+	var se *statusError
+	if !errors.As(err, &se) {
+		if errors.Is(err, validator.ErrUnparsable) {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		return invalid(err)
+	}
+	if se.code == http.StatusForbidden {
+		return denied(se.reason, se.Error())
+	}
+	r := admission.Errored(se.code, se.err)
+	if se.reason != "" {
+		r.Result.Reason = se.reason
+	}
+	return r
+}
+
+// invalid returns an error response for an account that failed validation
+func invalid(err error) admission.Response {
+	r := admission.Errored(http.StatusUnprocessableEntity, err)
+	r.Result.Reason = reasonFor(err)
+	return r
+}
+
+// denied returns a denial for a policy enforced by the webhook itself
+func denied(reason metav1.StatusReason, msg string) admission.Response {
+	r := admission.Denied("")
+	r.Result.Reason = reason
+	r.Result.Message = msg
+	return r
+}
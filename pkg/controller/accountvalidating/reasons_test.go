@@ -0,0 +1,100 @@
+package accountvalidating
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/armory/spinnaker-operator/pkg/accounts"
+	"github.com/armory/spinnaker-operator/pkg/accounts/account"
+	"github.com/armory/spinnaker-operator/pkg/accounts/kubernetes"
+	"github.com/armory/spinnaker-operator/pkg/secrets"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestInvalidReason(t *testing.T) {
+	cases := []struct {
+		name     string
+		err      error
+		expected metav1.StatusReason
+	}{
+		{
+			"missing secret file",
+			fmt.Errorf("error loading kubeconfigFile:\n  %w", secrets.CheckFile("/does/not/exist")),
+			ReasonSecretFileNotFound,
+		},
+		{
+			"unreadable secret file",
+			fmt.Errorf("%w at /tmp", secrets.ErrSecretFileUnreadable),
+			ReasonSecretFileUnreadable,
+		},
+		{
+			"empty secret",
+			secrets.CheckFormat("kube-secret/config", []byte("\n"), secrets.KubeconfigFormat),
+			ReasonEmptySecret,
+		},
+		{
+			"credential of another provider",
+			secrets.CheckFormat("kube-secret/config", []byte(`{"type": "service_account", "private_key": "key"}`), secrets.KubeconfigFormat),
+			ReasonCredentialMismatch,
+		},
+		{
+			"invalid endpoint",
+			accounts.ValidateURL("server", "mycluster.com", []string{"https"}),
+			ReasonInvalidEndpoint,
+		},
+		{
+			"non canonical value",
+			fmt.Errorf("%w: account \"kube\": spec.settings.providerVersion: Unsupported value: \"v3\"", accounts.ErrNonCanonicalValue),
+			ReasonNonCanonicalValue,
+		},
+		{
+			"invalid label selector",
+			fmt.Errorf("%w: account \"kube\": spec.settings.labelSelector: Invalid value: \"env in prod\"", accounts.ErrInvalidLabelSelector),
+			ReasonInvalidLabelSelector,
+		},
+		{
+			"feature past its sunset",
+			fmt.Errorf("%w: account kube uses settings past their sunset: liveManifestCalls", accounts.ErrFeatureSunset),
+			ReasonFeatureSunset,
+		},
+		{
+			"conflicting settings",
+			fmt.Errorf("%w: account \"kube\": spec.settings.omitKinds: Forbidden", accounts.ErrConflictingSettings),
+			ReasonConflictingSettings,
+		},
+		{
+			"unreachable proxy",
+			fmt.Errorf("error connecting to account \"kube\":\n  %w", account.CheckProxyReachable(context.TODO(), &url.URL{Scheme: "http", Host: "127.0.0.1:1"})),
+			ReasonProxyUnreachable,
+		},
+		{
+			"missing service account",
+			fmt.Errorf("%w: service account \"clouddriver\" doesn't exist in namespace \"spinnaker\"", kubernetes.ErrServiceAccountNotFound),
+			ReasonServiceAccountNotFound,
+		},
+		{
+			"expired endpoint certificate",
+			fmt.Errorf("error listing namespaces in account \"kube\":\n  %w", fmt.Errorf("%w: the certificate of the cluster expired", account.ErrEndpointCertificateExpired)),
+			ReasonCertificateExpired,
+		},
+		{
+			"other error",
+			errors.New("boom"),
+			metav1.StatusReasonInvalid,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := invalid(c.err)
+			assert.False(t, r.Allowed)
+			assert.Equal(t, int32(http.StatusUnprocessableEntity), r.Result.Code)
+			assert.Equal(t, c.expected, r.Result.Reason)
+			assert.Equal(t, c.err.Error(), r.Result.Message)
+		})
+	}
+}
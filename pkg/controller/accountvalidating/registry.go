@@ -0,0 +1,54 @@
+package accountvalidating
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/armory/spinnaker-operator/pkg/accounts"
+	"github.com/armory/spinnaker-operator/pkg/accounts/account"
+	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// AccountValidator validates an account once the checks common to all account types passed.
+// Warnings are recorded with account.Warn.
+type AccountValidator interface {
+	Validate(ctx context.Context, acc account.Account, spinSvc interfaces.SpinnakerService, c client.Client) error
+}
+
+var (
+	validatorsMu sync.RWMutex
+	validators   = map[string]func() AccountValidator{}
+)
+
+func init() {
+	for t := range accounts.Types {
+		RegisterValidator(string(t), func() AccountValidator { return &builtinValidator{} })
+	}
+}
+
+// RegisterValidator registers the validator of an account type, replacing any validator registered for that type.
+// The account type itself must be registered with accounts.Register.
+func RegisterValidator(accountType string, factory func() AccountValidator) {
+	validatorsMu.Lock()
+	defer validatorsMu.Unlock()
+	validators[strings.ToLower(accountType)] = factory
+}
+
+// validatorFor returns the validator registered for the account type, or nil if there's none
+func validatorFor(accountType interfaces.AccountType) AccountValidator {
+	validatorsMu.RLock()
+	defer validatorsMu.RUnlock()
+	if f, ok := validators[strings.ToLower(string(accountType))]; ok {
+		return f()
+	}
+	return nil
+}
+
+// builtinValidator runs the validator provided by the account type
+type builtinValidator struct{}
+
+func (b *builtinValidator) Validate(ctx context.Context, acc account.Account, spinSvc interfaces.SpinnakerService, c client.Client) error {
+	return acc.NewValidator().Validate(spinSvc, c, ctx, log)
+}
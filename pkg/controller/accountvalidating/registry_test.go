@@ -0,0 +1,105 @@
+package accountvalidating
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/armory/spinnaker-operator/pkg/accounts"
+	"github.com/armory/spinnaker-operator/pkg/accounts/account"
+	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
+	"github.com/armory/spinnaker-operator/pkg/controller/accountvalidating/accountvalidatingtest"
+	"github.com/armory/spinnaker-operator/pkg/test"
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const fakeType interfaces.AccountType = "Fake"
+
+type fakeAccountType struct{}
+
+func (f *fakeAccountType) GetType() interfaces.AccountType { return fakeType }
+func (f *fakeAccountType) FromCRD(a interfaces.SpinnakerAccount) (account.Account, error) {
+	return &fakeAccount{name: a.GetName(), settings: a.GetSpec().Settings}, nil
+}
+func (f *fakeAccountType) FromSpinnakerConfig(context.Context, map[string]interface{}) (account.Account, error) {
+	return nil, errors.New("not supported")
+}
+func (f *fakeAccountType) GetServices() []string        { return []string{"clouddriver"} }
+func (f *fakeAccountType) GetAccountsKey() string       { return "fake.accounts" }
+func (f *fakeAccountType) GetConfigAccountsKey() string { return "providers.fake.accounts" }
+func (f *fakeAccountType) GetValidationSettings(interfaces.SpinnakerService) *interfaces.ValidationSetting {
+	return nil
+}
+func (f *fakeAccountType) GetPrimaryAccountsKey() string { return "providers.fake.primaryAccount" }
+
+type fakeAccount struct {
+	account.BaseAccount
+	name     string
+	settings interfaces.FreeForm
+}
+
+func (f *fakeAccount) GetName() string                        { return f.name }
+func (f *fakeAccount) GetType() interfaces.AccountType        { return fakeType }
+func (f *fakeAccount) NewValidator() account.AccountValidator { return nil }
+func (f *fakeAccount) ToSpinnakerSettings(context.Context) (map[string]interface{}, error) {
+	return f.BaseToSpinnakerSettings(f), nil
+}
+func (f *fakeAccount) GetSettings() *interfaces.FreeForm { return &f.settings }
+
+// fakeValidator denies accounts without a region setting
+type fakeValidator struct {
+	calls *int
+}
+
+func (f *fakeValidator) Validate(_ context.Context, acc account.Account, _ interfaces.SpinnakerService, _ client.Client) error {
+	*f.calls++
+	if _, ok := (*acc.GetSettings())["region"]; !ok {
+		return errors.New("fake accounts need a region")
+	}
+	return nil
+}
+
+func TestHandleRegisteredValidator(t *testing.T) {
+	accounts.Register(&fakeAccountType{})
+	calls := 0
+	RegisterValidator(string(fakeType), func() AccountValidator { return &fakeValidator{calls: &calls} })
+	defer func() {
+		delete(accounts.Types, fakeType)
+		validatorsMu.Lock()
+		delete(validators, "fake")
+		validatorsMu.Unlock()
+	}()
+
+	newFakeAccount := func(settings string) interfaces.SpinnakerAccount {
+		acc := test.TypesFactory.NewAccount()
+		test.ReadYamlString([]byte(`
+apiVersion: spinnaker.io/v1alpha2
+kind: SpinnakerAccount
+metadata:
+  name: fake
+  namespace: ns1
+spec:
+  enabled: true
+  type: Fake
+  settings:
+    `+settings), acc, t)
+		return acc
+	}
+
+	v := newTestController(t)
+	r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountAdmissionRequest(newFakeAccount("region: us-east-1"), admissionv1.Create))
+	assert.True(t, r.Allowed)
+	assert.Equal(t, 1, calls)
+
+	r = v.Handle(context.TODO(), accountvalidatingtest.NewAccountAdmissionRequest(newFakeAccount("{}"), admissionv1.Create))
+	assert.False(t, r.Allowed)
+	assert.Equal(t, "fake accounts need a region", r.Result.Message)
+	assert.Equal(t, 2, calls)
+}
+
+func TestBuiltinValidatorsRegistered(t *testing.T) {
+	assert.IsType(t, &builtinValidator{}, validatorFor(interfaces.KubernetesAccountType))
+	assert.Nil(t, validatorFor("Unknown"))
+}
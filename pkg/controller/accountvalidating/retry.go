@@ -0,0 +1,128 @@
+package accountvalidating
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	initialRetryAfter = time.Second
+	maxRetryAfter     = 5 * time.Minute
+	// retryStateTTL is how long the failures of an object are remembered
+	retryStateTTL = 2 * maxRetryAfter
+)
+
+// retryTracker computes growing retry hints for objects repeatedly denied for transient reasons
+type retryTracker struct {
+	mu       sync.Mutex
+	failures map[string]retryState
+	// swept is when expired failures were last evicted
+	swept time.Time
+}
+
+type retryState struct {
+	count int
+	last  time.Time
+}
+
+// next records a transient failure for the key at now and returns how long the client should wait before retrying
+func (t *retryTracker) next(key string, now time.Time) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.failures == nil {
+		t.failures = map[string]retryState{}
+	}
+	t.evict(now)
+	s := t.failures[key]
+	// Start over if the object hasn't failed for a while
+	if now.Sub(s.last) > retryStateTTL {
+		s.count = 0
+	}
+	s.count++
+	s.last = now
+	t.failures[key] = s
+
+	d := initialRetryAfter
+	for i := 1; i < s.count && d < maxRetryAfter; i++ {
+		d *= 2
+	}
+	if d > maxRetryAfter {
+		d = maxRetryAfter
+	}
+	return d
+}
+
+// reset forgets the failures of the key
+func (t *retryTracker) reset(key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.failures, key)
+}
+
+// evict forgets the failures of objects that haven't failed within the TTL, e.g. deleted objects, must be called
+// with the lock held
+func (t *retryTracker) evict(now time.Time) {
+	if now.Sub(t.swept) < retryStateTTL {
+		return
+	}
+	t.swept = now
+	for k, s := range t.failures {
+		if now.Sub(s.last) > retryStateTTL {
+			delete(t.failures, k)
+		}
+	}
+}
+
+// isTransient returns true for errors caused by the infrastructure rather than the account, retrying later may succeed
+func isTransient(err error) bool {
+	var se *statusError
+	if errors.As(err, &se) && (se.code == http.StatusInternalServerError || se.code == http.StatusServiceUnavailable) {
+		return true
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return apierrors.IsServerTimeout(err) || apierrors.IsTimeout(err) || apierrors.IsTooManyRequests(err) ||
+		apierrors.IsServiceUnavailable(err) || apierrors.IsInternalError(err)
+}
+
+// respond returns the admission response for the outcome of a validation. Transient denials carry a retry hint
+// growing with repeated failures of the same object.
+func (v *accountValidatingController) respond(req admission.Request, warnings []string, err error) admission.Response {
+	key := req.Kind.Kind + "/" + req.Namespace + "/" + req.Name
+	if err == nil || !isTransient(err) {
+		v.retries.reset(key)
+	}
+	if err == nil {
+		return admission.ValidationResponse(true, "").WithWarnings(warnings...)
+	}
+	r := v.settings.messages.localize(responseFor(err))
+	var se *statusError
+	if errors.As(err, &se) && se.retryAfter > 0 {
+		r.Result.Details = &metav1.StatusDetails{
+			Name:              req.Name,
+			Kind:              req.Kind.Kind,
+			RetryAfterSeconds: int32(math.Ceil(se.retryAfter.Seconds())),
+		}
+	} else if isTransient(err) {
+		r.Result.Details = &metav1.StatusDetails{
+			Name:              req.Name,
+			Kind:              req.Kind.Kind,
+			RetryAfterSeconds: int32(v.retries.next(key, time.Now()) / time.Second),
+		}
+	}
+	return r
+}
@@ -0,0 +1,110 @@
+package accountvalidating
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/armory/spinnaker-operator/pkg/controller/accountvalidating/accountvalidatingtest"
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// failingListClient fails to list objects until fixed
+type failingListClient struct {
+	client.Client
+	fixed bool
+}
+
+func (c *failingListClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	if c.fixed {
+		return c.Client.List(ctx, list, opts...)
+	}
+	return apierrors.NewServiceUnavailable("etcd is down")
+}
+
+func TestHandleTransientRetryAfter(t *testing.T) {
+	api := newFakeKubernetesAPI(t)
+	acc := kubernetesAccount(t, "kube", api.URL, "{}")
+	v := newTestController(t)
+	c := &failingListClient{Client: v.client}
+	v.client = c
+	req := accountvalidatingtest.NewAccountAdmissionRequest(acc, admissionv1.Create)
+
+	for _, expected := range []int32{1, 2, 4, 8} {
+		r := v.Handle(context.TODO(), req)
+		assert.False(t, r.Allowed)
+		if assert.NotNil(t, r.Result.Details) {
+			assert.Equal(t, expected, r.Result.Details.RetryAfterSeconds)
+		}
+	}
+
+	// Other objects are tracked separately
+	r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountAdmissionRequest(kubernetesAccount(t, "other", api.URL, "{}"), admissionv1.Create))
+	if assert.NotNil(t, r.Result.Details) {
+		assert.Equal(t, int32(1), r.Result.Details.RetryAfterSeconds)
+	}
+
+	// A successful validation resets the backoff
+	c.fixed = true
+	assert.True(t, v.Handle(context.TODO(), req).Allowed)
+	c.fixed = false
+	r = v.Handle(context.TODO(), req)
+	if assert.NotNil(t, r.Result.Details) {
+		assert.Equal(t, int32(1), r.Result.Details.RetryAfterSeconds)
+	}
+}
+
+func TestHandleUserErrorHasNoRetryAfter(t *testing.T) {
+	api := newFakeKubernetesAPI(t)
+	acc := kubernetesAccount(t, "kube", api.URL, "{namespaces: [ns1], omitNamespaces: [ns2]}")
+	r := newTestController(t).Handle(context.TODO(), accountvalidatingtest.NewAccountAdmissionRequest(acc, admissionv1.Create))
+	assert.False(t, r.Allowed)
+	assert.Nil(t, r.Result.Details)
+}
+
+func TestRetryTrackerCap(t *testing.T) {
+	tr := retryTracker{}
+	now := time.Now()
+	for i := 0; i < 20; i++ {
+		tr.next("key", now)
+	}
+	assert.Equal(t, maxRetryAfter, tr.next("key", now))
+}
+
+func TestRetryTrackerEviction(t *testing.T) {
+	tr := retryTracker{}
+	start := time.Now()
+	tr.next("a", start)
+	assert.Equal(t, 2*initialRetryAfter, tr.next("a", start))
+	tr.next("b", start.Add(retryStateTTL+time.Second))
+	assert.NotContains(t, tr.failures, "a")
+	assert.Contains(t, tr.failures, "b")
+
+	tr.reset("b")
+	assert.Empty(t, tr.failures)
+}
+
+func TestIsTransient(t *testing.T) {
+	cases := []struct {
+		err       error
+		transient bool
+	}{
+		{internalError(errors.New("boom")), true},
+		{fmt.Errorf("error listing namespaces: %w", context.DeadlineExceeded), true},
+		{apierrors.NewTooManyRequests("slow down", 1), true},
+		{apierrors.NewForbidden(schema.GroupResource{Resource: "namespaces"}, "", errors.New("forbidden")), false},
+		{badRequest(errors.New("bad")), false},
+		{errors.New("invalid settings"), false},
+	}
+	for _, c := range cases {
+		t.Run(c.err.Error(), func(t *testing.T) {
+			assert.Equal(t, c.transient, isTransient(c.err))
+		})
+	}
+}
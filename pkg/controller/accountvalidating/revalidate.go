@@ -0,0 +1,60 @@
+package accountvalidating
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// AccountStatus is the outcome of validating an account
+type AccountStatus string
+
+const (
+	AccountPassed AccountStatus = "pass"
+	AccountWarned AccountStatus = "warn"
+	AccountFailed AccountStatus = "fail"
+)
+
+// AccountResult reports the validation of an existing account
+type AccountResult struct {
+	Namespace string
+	Name      string
+	Status    AccountStatus
+	Warnings  []string
+	Error     string
+}
+
+// RevalidateAll runs the validations of the admission webhook on all accounts of the cluster and reports their
+// results. Accounts aren't updated.
+func RevalidateAll(ctx context.Context, c client.Client, restConfig *rest.Config) ([]AccountResult, error) {
+	s, err := loadSettings()
+	if err != nil {
+		return nil, err
+	}
+	v := &accountValidatingController{client: c, restConfig: restConfig, settings: s}
+	return v.revalidateAll(ctx)
+}
+
+func (v *accountValidatingController) revalidateAll(ctx context.Context) ([]AccountResult, error) {
+	list := TypesFactory.NewAccountList()
+	if err := v.client.List(ctx, list); err != nil {
+		return nil, fmt.Errorf("unable to list accounts: %w", err)
+	}
+	results := make([]AccountResult, 0)
+	for _, acc := range list.GetItems() {
+		r := AccountResult{Namespace: acc.GetNamespace(), Name: acc.GetName(), Status: AccountPassed}
+		warnings, err := v.validate(ctx, acc)
+		switch {
+		case err != nil:
+			r.Status = AccountFailed
+			r.Error = err.Error()
+		case len(warnings) > 0:
+			r.Status = AccountWarned
+			r.Warnings = warnings
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
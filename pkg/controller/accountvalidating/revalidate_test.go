@@ -0,0 +1,28 @@
+package accountvalidating
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRevalidateAll(t *testing.T) {
+	api := newFakeKubernetesAPI(t)
+	valid := kubernetesAccount(t, "kube-a", api.URL, "{}")
+	warned := kubernetesAccount(t, "kube-b", api.URL, "{}")
+	warned.SetAnnotations(map[string]string{"spinnaker.io/managed": "true"})
+	invalid := kubernetesAccount(t, "kube-c", "mycluster.com", "{}")
+
+	v := newTestController(t, valid, warned, invalid)
+	results, err := v.revalidateAll(context.TODO())
+	if !assert.Nil(t, err) || !assert.Len(t, results, 3) {
+		return
+	}
+	assert.Equal(t, AccountResult{Namespace: "ns1", Name: "kube-a", Status: AccountPassed}, results[0])
+	assert.Equal(t, AccountResult{Namespace: "ns1", Name: "kube-b", Status: AccountWarned,
+		Warnings: []string{"account kube-b uses keys reserved by Spinnaker: spinnaker.io/managed"}}, results[1])
+	assert.Equal(t, "kube-c", results[2].Name)
+	assert.Equal(t, AccountFailed, results[2].Status)
+	assert.Contains(t, results[2].Error, "invalid endpoint")
+}
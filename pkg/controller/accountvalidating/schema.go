@@ -0,0 +1,44 @@
+package accountvalidating
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/armory/spinnaker-operator/pkg/accounts"
+	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
+	v1 "k8s.io/api/core/v1"
+)
+
+// checkSchema checks the account against the JSON Schema of its type in the schema ConfigMap, if any
+func (v *accountValidatingController) checkSchema(ctx context.Context, acc interfaces.SpinnakerAccount) error {
+	if v.settings.schemaConfigMap == nil {
+		return nil
+	}
+	recordCheck(ctx, schemaCheck)
+	cm := &v1.ConfigMap{}
+	if err := v.client.Get(ctx, *v.settings.schemaConfigMap, cm); err != nil {
+		return internalError(fmt.Errorf("unable to get account schemas from ConfigMap %s: %w", v.settings.schemaConfigMap, err))
+	}
+	s, err := schemaFor(cm, string(acc.GetSpec().Type))
+	if err != nil || s == nil {
+		return err
+	}
+	return accounts.CheckSchema(s, acc)
+}
+
+// schemaFor returns the schema of the given account type, found under the type name with an optional .json
+// extension, or nil if the type has none
+func schemaFor(cm *v1.ConfigMap, accountType string) (*accounts.Schema, error) {
+	for k, d := range cm.Data {
+		if !strings.EqualFold(strings.TrimSuffix(k, ".json"), accountType) {
+			continue
+		}
+		s, err := accounts.ParseSchema([]byte(d))
+		if err != nil {
+			return nil, internalError(fmt.Errorf("invalid schema %s in ConfigMap %s/%s: %w", k, cm.Namespace, cm.Name, err))
+		}
+		return s, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,67 @@
+package accountvalidating
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/armory/spinnaker-operator/pkg/accounts"
+	"github.com/armory/spinnaker-operator/pkg/controller/accountvalidating/accountvalidatingtest"
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestHandleSchema(t *testing.T) {
+	t.Setenv(accounts.AsyncValidationEnv, "true")
+	t.Setenv(schemaConfigMapEnv, "operator/account-schemas")
+	schemas := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "account-schemas", Namespace: "operator"},
+		Data: map[string]string{
+			"kubernetes.json": `{
+  "type": "object",
+  "properties": {
+    "spec": {
+      "properties": {
+        "settings": {"type": "object", "required": ["team"], "properties": {"team": {"type": "string", "pattern": "^[a-z]+$"}}}
+      }
+    }
+  }
+}`,
+		},
+	}
+
+	t.Run("missing custom field", func(t *testing.T) {
+		v := newTestController(t, schemas)
+		acc := kubernetesAccount(t, "kube", "https://kube.example.com", "cacheAllApplicationRelationships: true")
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountAdmissionRequest(acc, admissionv1.Create))
+		assert.False(t, r.Allowed)
+		assert.Equal(t, int32(http.StatusUnprocessableEntity), r.Result.Code)
+		assert.Equal(t, ReasonSchemaViolation, r.Result.Reason)
+		assert.Equal(t, `schema violation: account "kube": spec.settings.team: Required value`, r.Result.Message)
+	})
+
+	t.Run("invalid custom field", func(t *testing.T) {
+		v := newTestController(t, schemas)
+		acc := kubernetesAccount(t, "kube", "https://kube.example.com", "team: Platform")
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountAdmissionRequest(acc, admissionv1.Create))
+		assert.False(t, r.Allowed)
+		assert.Equal(t, `schema violation: account "kube": spec.settings.team: Invalid value: "Platform": must match ^[a-z]+$`, r.Result.Message)
+	})
+
+	t.Run("valid", func(t *testing.T) {
+		v := newTestController(t, schemas)
+		acc := kubernetesAccount(t, "kube", "https://kube.example.com", "team: platform")
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountAdmissionRequest(acc, admissionv1.Create))
+		assert.True(t, r.Allowed, r.Result.Message)
+	})
+
+	t.Run("missing ConfigMap", func(t *testing.T) {
+		v := newTestController(t)
+		acc := kubernetesAccount(t, "kube", "https://kube.example.com", "team: platform")
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountAdmissionRequest(acc, admissionv1.Create))
+		assert.False(t, r.Allowed)
+		assert.Equal(t, int32(http.StatusInternalServerError), r.Result.Code)
+	})
+}
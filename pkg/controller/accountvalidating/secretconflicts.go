@@ -0,0 +1,114 @@
+package accountvalidating
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
+	"github.com/armory/spinnaker-operator/pkg/secrets"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// k8sSecretPrefixes are the prefixes of values encrypted with the Kubernetes secret engine
+var k8sSecretPrefixes = []string{"encryptedFile:k8s!", "encrypted:k8s!"}
+
+// secretRef is a key of a Kubernetes secret in the account's namespace
+type secretRef struct {
+	name string
+	key  string
+}
+
+// secretRefs returns the Kubernetes secret keys referenced by the account
+func secretRefs(acc interfaces.SpinnakerAccount) []secretRef {
+	auth := acc.GetSpec().Kubernetes
+	if auth == nil {
+		return nil
+	}
+	refs := make([]secretRef, 0)
+	if auth.KubeconfigSecret != nil && auth.KubeconfigSecret.Name != "" {
+		refs = append(refs, secretRef{name: auth.KubeconfigSecret.Name, key: auth.KubeconfigSecret.Key})
+	}
+	for _, p := range k8sSecretPrefixes {
+		if strings.HasPrefix(auth.KubeconfigFile, p) {
+			if n, k, err := secrets.ParseKubernetesSecretParams(strings.TrimPrefix(auth.KubeconfigFile, p)); err == nil {
+				refs = append(refs, secretRef{name: n, key: k})
+			}
+		}
+	}
+	return refs
+}
+
+// secretConflicts returns a warning for each other account of the namespace using a secret referenced by the account
+// with a different value, e.g. another key of the same secret.
+func (v *accountValidatingController) secretConflicts(ctx context.Context, acc interfaces.SpinnakerAccount) ([]string, error) {
+	refs := secretRefs(acc)
+	if len(refs) == 0 {
+		return nil, nil
+	}
+	list := TypesFactory.NewAccountList()
+	if err := v.reader().List(ctx, list, client.InNamespace(acc.GetNamespace())); err != nil {
+		return nil, fmt.Errorf("unable to list accounts in namespace %s: %w", acc.GetNamespace(), err)
+	}
+	others := list.GetItems()
+	sort.Slice(others, func(i, j int) bool { return others[i].GetName() < others[j].GetName() })
+
+	secretsByName := map[string]*v1.Secret{}
+	value := func(r secretRef) ([]byte, bool, error) {
+		s, ok := secretsByName[r.name]
+		if !ok {
+			s = &v1.Secret{}
+			if err := v.getSecret(ctx, acc.GetNamespace(), r.name, s); err != nil {
+				return nil, false, client.IgnoreNotFound(err)
+			}
+			secretsByName[r.name] = s
+		}
+		d, ok := s.Data[r.key]
+		return d, ok, nil
+	}
+
+	warnings := make([]string, 0)
+	for _, r := range refs {
+		val, found, err := value(r)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			continue
+		}
+		for _, o := range others {
+			if o.GetName() == acc.GetName() {
+				continue
+			}
+			for _, or := range secretRefs(o) {
+				if or.name != r.name || or.key == r.key {
+					continue
+				}
+				oval, found, err := value(or)
+				if err != nil {
+					return nil, err
+				}
+				if found && string(oval) != string(val) {
+					warnings = append(warnings, fmt.Sprintf("account %s uses key %s of secret %s while account %s uses key %s with a different value",
+						acc.GetName(), r.key, r.name, o.GetName(), or.key))
+				}
+			}
+		}
+	}
+	return warnings, nil
+}
+
+// getSecret reads a secret referenced by an account of the given namespace, from the secret lookup namespace first
+// if one is configured
+func (v *accountValidatingController) getSecret(ctx context.Context, namespace, name string, s *v1.Secret) error {
+	if ns := v.settings.secretNamespace; ns != "" && ns != namespace {
+		err := v.reader().Get(ctx, client.ObjectKey{Namespace: ns, Name: name}, s)
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return v.reader().Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, s)
+}
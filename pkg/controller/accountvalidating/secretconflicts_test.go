@@ -0,0 +1,111 @@
+package accountvalidating
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/armory/spinnaker-operator/pkg/accounts"
+	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
+	"github.com/armory/spinnaker-operator/pkg/controller/accountvalidating/accountvalidatingtest"
+	"github.com/armory/spinnaker-operator/pkg/test"
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func secretAccount(t *testing.T, name, secret, key string) interfaces.SpinnakerAccount {
+	acc := test.TypesFactory.NewAccount()
+	test.ReadYamlString([]byte(fmt.Sprintf(`
+apiVersion: spinnaker.io/v1alpha2
+kind: SpinnakerAccount
+metadata:
+  name: %s
+  namespace: ns1
+spec:
+  enabled: true
+  type: Kubernetes
+  kubernetes:
+    kubeconfigSecret:
+      name: %s
+      key: %s
+`, name, secret, key)), acc, t)
+	return acc
+}
+
+func TestHandleSecretConflicts(t *testing.T) {
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "kubeconfigs", Namespace: "ns1"},
+		Data: map[string][]byte{
+			"prod":    []byte("prod-kubeconfig"),
+			"staging": []byte("staging-kubeconfig"),
+			"copy":    []byte("prod-kubeconfig"),
+		},
+	}
+	existing := secretAccount(t, "prod", "kubeconfigs", "prod")
+	t.Setenv(accounts.AsyncValidationEnv, "true")
+	background := "account %s will be validated in the background, see its Validated condition"
+
+	t.Run("differing values", func(t *testing.T) {
+		t.Setenv(secretConflictsEnv, "true")
+		v := newTestController(t, secret, existing)
+		acc := secretAccount(t, "staging", "kubeconfigs", "staging")
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountAdmissionRequest(acc, admissionv1.Create))
+		assert.True(t, r.Allowed)
+		assert.Equal(t, []string{
+			"account staging uses key staging of secret kubeconfigs while account prod uses key prod with a different value",
+			fmt.Sprintf(background, "staging"),
+		}, r.Warnings)
+	})
+
+	t.Run("same values", func(t *testing.T) {
+		t.Setenv(secretConflictsEnv, "true")
+		v := newTestController(t, secret, existing)
+		acc := secretAccount(t, "copy", "kubeconfigs", "copy")
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountAdmissionRequest(acc, admissionv1.Create))
+		assert.True(t, r.Allowed)
+		assert.Equal(t, []string{fmt.Sprintf(background, "copy")}, r.Warnings)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		v := newTestController(t, secret, existing)
+		acc := secretAccount(t, "staging", "kubeconfigs", "staging")
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountAdmissionRequest(acc, admissionv1.Create))
+		assert.True(t, r.Allowed)
+		assert.Equal(t, []string{fmt.Sprintf(background, "staging")}, r.Warnings)
+	})
+}
+
+func TestGetSecretLookupNamespace(t *testing.T) {
+	shared := &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "kubeconfigs", Namespace: "secrets"}, Data: map[string][]byte{"prod": []byte("shared")}}
+	local := &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "kubeconfigs", Namespace: "ns1"}, Data: map[string][]byte{"prod": []byte("local")}}
+	other := &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "ns1"}, Data: map[string][]byte{"prod": []byte("other")}}
+
+	t.Run("configured namespace", func(t *testing.T) {
+		t.Setenv(secretNamespaceEnv, "secrets")
+		v := newTestController(t, shared, local, other)
+		s := &v1.Secret{}
+		if assert.Nil(t, v.getSecret(context.TODO(), "ns1", "kubeconfigs", s)) {
+			assert.Equal(t, "shared", string(s.Data["prod"]))
+		}
+		s = &v1.Secret{}
+		if assert.Nil(t, v.getSecret(context.TODO(), "ns1", "other", s)) {
+			assert.Equal(t, "other", string(s.Data["prod"]), "falls back on the account's namespace")
+		}
+	})
+
+	t.Run("account namespace", func(t *testing.T) {
+		v := newTestController(t, shared, local)
+		s := &v1.Secret{}
+		if assert.Nil(t, v.getSecret(context.TODO(), "ns1", "kubeconfigs", s)) {
+			assert.Equal(t, "local", string(s.Data["prod"]))
+		}
+	})
+
+	t.Run("invalid namespace", func(t *testing.T) {
+		t.Setenv(secretNamespaceEnv, "Not_A_Namespace")
+		_, err := loadSettings()
+		assert.NotNil(t, err)
+	})
+}
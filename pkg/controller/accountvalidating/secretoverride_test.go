@@ -0,0 +1,50 @@
+package accountvalidating
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateWithSecretOverride(t *testing.T) {
+	api := newFakeKubernetesAPI(t)
+	acc := secretAccount(t, "kube", "kubeconfigs", "next")
+	kubeconfig := fmt.Sprintf(`apiVersion: v1
+kind: Config
+current-context: ctx
+clusters:
+- name: cluster
+  cluster:
+    server: %s
+contexts:
+- name: ctx
+  context:
+    cluster: cluster
+    user: user
+users:
+- name: user
+  user:
+    token: new-token
+`, api.URL)
+	v := newTestController(t)
+
+	t.Run("valid new credentials", func(t *testing.T) {
+		_, err := ValidateWithSecretOverride(context.TODO(), v.client, nil, acc, map[string][]byte{"kubeconfigs/next": []byte(kubeconfig)})
+		assert.Nil(t, err)
+	})
+
+	t.Run("invalid new credentials", func(t *testing.T) {
+		_, err := ValidateWithSecretOverride(context.TODO(), v.client, nil, acc, map[string][]byte{"kubeconfigs/next": []byte("token: new-token")})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("empty new credentials", func(t *testing.T) {
+		_, err := ValidateWithSecretOverride(context.TODO(), v.client, nil, acc, map[string][]byte{"kubeconfigs/next": []byte(" \n")})
+		if assert.NotNil(t, err) {
+			assert.Equal(t, ReasonEmptySecret, reasonFor(err))
+			assert.Contains(t, err.Error(), "empty secret kubeconfigs/next")
+		}
+	})
+}
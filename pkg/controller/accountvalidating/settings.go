@@ -0,0 +1,445 @@
+package accountvalidating
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/armory/spinnaker-operator/pkg/accounts"
+	"github.com/armory/spinnaker-operator/pkg/accounts/account"
+	"github.com/armory/spinnaker-operator/pkg/util"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	allowedAccountTypesEnv      = "ALLOWED_ACCOUNT_TYPES"
+	connectivityEnv             = "VALIDATE_ACCOUNT_CONNECTIVITY"
+	timeoutEnv                  = "ACCOUNT_VALIDATION_TIMEOUT"
+	strictEnv                   = accounts.StrictValidationEnv
+	reservedPrefixesEnv         = "RESERVED_METADATA_PREFIXES"
+	expiryWarningWindowEnv      = "CREDENTIAL_EXPIRY_WARNING_WINDOW"
+	immutableEnv                = "ACCOUNT_IMMUTABLE"
+	maxObjectSizeEnv            = "ACCOUNT_MAX_OBJECT_SIZE"
+	secretConflictsEnv          = "ACCOUNT_SECRET_CONFLICT_CHECK"
+	softLimitEnv                = "ACCOUNT_SOFT_LIMIT"
+	duplicateTargetsEnv         = "ACCOUNT_DUPLICATE_TARGET_CHECK"
+	opaURLEnv                   = "OPA_URL"
+	opaQueryPathEnv             = "OPA_QUERY_PATH"
+	enforcementCutoffEnv        = "ACCOUNT_ENFORCEMENT_CUTOFF"
+	caseInsensitiveEnv          = "ACCOUNT_NAMES_CASE_INSENSITIVE"
+	privilegedAccountsEnv       = "PRIVILEGED_ACCOUNTS"
+	localeEnv                   = "WEBHOOK_LOCALE"
+	breakerThresholdEnv         = "CIRCUIT_BREAKER_THRESHOLD"
+	breakerCooldownEnv          = "CIRCUIT_BREAKER_COOLDOWN"
+	breakerFailOpenEnv          = "CIRCUIT_BREAKER_FAIL_OPEN"
+	identityGroupCheckEnv       = "IDENTITY_GROUP_CHECK"
+	identityGroupsURLEnv        = "IDENTITY_GROUPS_URL"
+	inventoryURLEnv             = "INVENTORY_URL"
+	auditSinkURLEnv             = "AUDIT_SINK_URL"
+	auditKafkaTopicEnv          = "AUDIT_SINK_KAFKA_TOPIC"
+	auditQueueSizeEnv           = "AUDIT_SINK_QUEUE_SIZE"
+	updateRateLimitEnv          = "ACCOUNT_UPDATE_RATE_LIMIT"
+	updateRateWindowEnv         = "ACCOUNT_UPDATE_RATE_WINDOW"
+	notifyURLEnv                = "NOTIFY_URL"
+	notifyTemplateEnv           = "NOTIFY_TEMPLATE"
+	notifyReasonsEnv            = "NOTIFY_REASONS"
+	notifyOutcomesEnv           = "NOTIFY_OUTCOMES"
+	notifyMaxAttemptsEnv        = "NOTIFY_MAX_ATTEMPTS"
+	secretNamespaceEnv          = "SECRET_LOOKUP_NAMESPACE"
+	secretNamespaceAllowlistEnv = "ACCOUNT_SECRET_NAMESPACE_ALLOWLIST"
+	schemaConfigMapEnv          = "ACCOUNT_SCHEMA_CONFIGMAP"
+	severityConfigMapEnv        = "ACCOUNT_SEVERITY_CONFIGMAP"
+	deprecationConfigMapEnv     = "ACCOUNT_DEPRECATION_CONFIGMAP"
+	uncachedReadsEnv            = "ACCOUNT_UNCACHED_READS"
+	namespacePolicyEnv          = "ACCOUNT_NAMESPACE_POLICY"
+	allowedNamespacesEnv        = "ACCOUNT_ALLOWED_NAMESPACES"
+	readKubeconfigEnv           = "ACCOUNT_READ_KUBECONFIG"
+	referencingKindsEnv         = accounts.ReferencingKindsEnv
+	typesWaitTimeoutEnv         = "ACCOUNT_TYPES_WAIT_TIMEOUT"
+	notificationTargetsEnv      = "ACCOUNT_NOTIFICATION_TARGET_SETTINGS"
+	groupConsistentSettingsEnv  = "ACCOUNT_GROUP_CONSISTENT_SETTINGS"
+
+	// defaultTimeout leaves room to answer before the API server's default webhook timeout of 10s
+	defaultTimeout = 8 * time.Second
+	// defaultOPAQueryPath is the package queried for account decisions
+	defaultOPAQueryPath = "spinnaker/accounts/decision"
+	// defaultMaxObjectSize leaves room below etcd's default request size limit of 1.5MiB
+	defaultMaxObjectSize = 1024 * 1024
+	// defaultBreakerThreshold is the number of consecutive failures of a backend before it's no longer called
+	defaultBreakerThreshold = 5
+	defaultBreakerCooldown  = 30 * time.Second
+	// defaultTypesWaitTimeout bounds the wait for account types to be registered at startup
+	defaultTypesWaitTimeout = 30 * time.Second
+)
+
+// defaultReservedPrefixes are label and annotation prefixes used internally by Spinnaker
+var defaultReservedPrefixes = []string{"spinnaker.io/", "moniker.spinnaker.io/", "artifact.spinnaker.io/", "strategy.spinnaker.io/", "traffic.spinnaker.io/"}
+
+// defaultGroupConsistentSettings are the settings members of a group must agree on, keyed by lower case account type
+var defaultGroupConsistentSettings = map[string][]string{"kubernetes": {"providerVersion"}}
+
+// settings holds the account validation settings read from the operator environment
+type settings struct {
+	// allowedTypes restricts the account types that can be admitted, all types are allowed if empty
+	allowedTypes []string
+	// connectivity enables validations connecting to the account's endpoints
+	connectivity bool
+	// providerConnectivity overrides connectivity for some providers
+	providerConnectivity map[string]bool
+	// timeout bounds the time spent validating a single account
+	timeout time.Duration
+	// strict denies accounts that would otherwise only get a warning
+	strict bool
+	// reservedPrefixes are label and annotation prefixes accounts cannot use
+	reservedPrefixes []string
+	// expiryWarningWindow is how long before their expiry credentials raise a warning
+	expiryWarningWindow time.Duration
+	// async leaves the validations connecting to the account to the account controller
+	async bool
+	// immutable denies updates changing the spec of an account
+	immutable bool
+	// maxObjectSize is the maximum size in bytes of serialized accounts and account groups
+	maxObjectSize int
+	// secretConflicts warns about secrets used by other accounts with a different value, it lists and reads
+	// the accounts and secrets of the namespace on each request
+	secretConflicts bool
+	// softLimit is the number of enabled accounts of a type per namespace above which a warning is raised,
+	// accounts aren't counted if zero
+	softLimit int
+	// duplicateTargets warns about accounts of the namespace with the same target, e.g. the same cluster, it lists
+	// the accounts of the namespace on each request
+	duplicateTargets bool
+	// opaURL is the OPA server consulted after the built-in validations, no policy is checked if empty
+	opaURL string
+	// opaQueryPath is the path of the OPA decision under /v1/data
+	opaQueryPath string
+	// enforcementCutoff is the creation time before which accounts are only warned about on update,
+	// all accounts are enforced if zero
+	enforcementCutoff time.Time
+	// caseInsensitiveNames compares account names case-insensitively when checking they're unique, as Spinnaker
+	// does for some account identifiers
+	caseInsensitiveNames bool
+	// privilegedAccounts are the names of accounts whose credentials other accounts can't use
+	privilegedAccounts []string
+	// messages renders denial messages in the configured locale
+	messages messageCatalog
+	// breakerThreshold is the number of consecutive failures of an external backend after which it isn't called
+	// for breakerCooldown
+	breakerThreshold int
+	breakerCooldown  time.Duration
+	// breakerFailOpen admits accounts with a warning instead of denying them while a backend isn't called
+	breakerFailOpen bool
+	// identityGroupsURL is the endpoint the roles of account permissions are looked up at, roles aren't checked if empty
+	identityGroupsURL string
+	// inventoryURL is the endpoint accounts are looked up at, accounts aren't looked up if empty
+	inventoryURL string
+	// auditSinkURL is the endpoint validation decisions are published to, decisions aren't published if empty
+	auditSinkURL string
+	// auditKafkaTopic publishes decisions to the topic of a Kafka REST proxy at auditSinkURL
+	auditKafkaTopic string
+	// auditQueueSize is the number of decisions waiting to be published above which new decisions are dropped
+	auditQueueSize int
+	// updateRateLimit is the number of updates of an account allowed within updateRateWindow, updates aren't
+	// throttled if zero
+	updateRateLimit  int
+	updateRateWindow time.Duration
+	// notifyURL is the endpoint decisions are notified to, decisions aren't notified if empty
+	notifyURL string
+	// notifyTemplate renders the payload of notifications from the decision record
+	notifyTemplate *template.Template
+	// notifyReasons and notifyOutcomes select the decisions notified, any reason is notified if notifyReasons is empty
+	notifyReasons  []string
+	notifyOutcomes []string
+	// notifyMaxAttempts is the number of attempts to deliver a notification
+	notifyMaxAttempts int
+	// secretNamespace is where the Kubernetes secrets of accounts are looked up before the account's namespace,
+	// secrets are only looked up in the account's namespace if empty
+	secretNamespace string
+	// secretNamespaceAllowlist are the pairs of namespaces whose accounts can reference secrets of the other namespace
+	secretNamespaceAllowlist namespaceAllowlist
+	// schemaConfigMap holds a JSON Schema per account type that accounts of the type are checked against,
+	// no schema is checked if nil
+	schemaConfigMap *types.NamespacedName
+	// severityConfigMap maps denial reasons to the severity they're reported with, denials are left as is if nil
+	severityConfigMap *types.NamespacedName
+	// deprecationConfigMap holds the schedule of settings being removed, no setting is deprecated if nil
+	deprecationConfigMap *types.NamespacedName
+	// uncachedReads reads the accounts, secrets and SpinnakerServices accounts are compared with from the API server
+	// instead of the manager's cache, which may be stale
+	uncachedReads bool
+	// readKubeconfig is the kubeconfig of the server these checks read from instead, to offload the API server
+	readKubeconfig string
+	// conflicts are the settings of account types that can't be enabled together, on top of the ones declared by types
+	conflicts accounts.SettingConflicts
+	// bounds override the range of numeric settings declared by account types
+	bounds []account.NumericBound
+	// referencingKinds are the kinds of manifests checked for references to accounts being deleted or disabled
+	referencingKinds []accounts.ReferencingKind
+	// stages are the validation stages run for each account, in order
+	stages []string
+	// allowedNamespaces are the namespaces accounts can be created in, nil if accounts can be created in any namespace
+	allowedNamespaces []string
+	// typesWaitTimeout is how long the webhook waits for account types to be registered at startup
+	typesWaitTimeout time.Duration
+	// notificationTargetSettings are the settings of spec.settings holding URLs Spinnaker notifies for the account,
+	// probed when connectivity is enabled
+	notificationTargetSettings []string
+	// groupConsistentSettings are the settings members of a group of the same type must agree on, keyed by lower case
+	// account type
+	groupConsistentSettings map[string][]string
+}
+
+func loadSettings() (settings, error) {
+	s := settings{
+		allowedTypes:               util.ListFromEnv(allowedAccountTypesEnv),
+		privilegedAccounts:         util.ListFromEnv(privilegedAccountsEnv),
+		notificationTargetSettings: util.ListFromEnv(notificationTargetsEnv),
+	}
+	var err error
+	if s.connectivity, err = util.BoolFromEnv(connectivityEnv, true); err != nil {
+		return s, err
+	}
+	if s.providerConnectivity, err = accounts.ProviderConnectivityFromEnv(); err != nil {
+		return s, err
+	}
+	if s.timeout, err = util.DurationFromEnv(timeoutEnv, defaultTimeout); err != nil {
+		return s, err
+	}
+	if s.typesWaitTimeout, err = util.DurationFromEnv(typesWaitTimeoutEnv, defaultTypesWaitTimeout); err != nil {
+		return s, err
+	}
+	if s.expiryWarningWindow, err = util.DurationFromEnv(expiryWarningWindowEnv, account.DefaultExpiryWarningWindow); err != nil {
+		return s, err
+	}
+	if s.strict, err = util.BoolFromEnv(strictEnv, false); err != nil {
+		return s, err
+	}
+	if s.immutable, err = util.BoolFromEnv(immutableEnv, false); err != nil {
+		return s, err
+	}
+	if s.maxObjectSize, err = util.IntFromEnv(maxObjectSizeEnv, defaultMaxObjectSize); err != nil {
+		return s, err
+	}
+	if s.secretConflicts, err = util.BoolFromEnv(secretConflictsEnv, false); err != nil {
+		return s, err
+	}
+	if s.softLimit, err = util.NonNegativeIntFromEnv(softLimitEnv, 0); err != nil {
+		return s, err
+	}
+	if s.duplicateTargets, err = util.BoolFromEnv(duplicateTargetsEnv, false); err != nil {
+		return s, err
+	}
+	if s.opaURL = os.Getenv(opaURLEnv); s.opaURL != "" {
+		if err = accounts.ValidateURL(opaURLEnv, s.opaURL, []string{"https", "http"}); err != nil {
+			return s, err
+		}
+	}
+	if s.opaQueryPath = strings.Trim(os.Getenv(opaQueryPathEnv), "/"); s.opaQueryPath == "" {
+		s.opaQueryPath = defaultOPAQueryPath
+	}
+	if s.inventoryURL = os.Getenv(inventoryURLEnv); s.inventoryURL != "" {
+		if err = accounts.ValidateURL(inventoryURLEnv, s.inventoryURL, []string{"https", "http"}); err != nil {
+			return s, err
+		}
+	}
+	if s.auditSinkURL = os.Getenv(auditSinkURLEnv); s.auditSinkURL != "" {
+		if err = accounts.ValidateURL(auditSinkURLEnv, s.auditSinkURL, []string{"https", "http"}); err != nil {
+			return s, err
+		}
+	}
+	s.auditKafkaTopic = os.Getenv(auditKafkaTopicEnv)
+	if s.auditQueueSize, err = util.IntFromEnv(auditQueueSizeEnv, defaultAuditQueueSize); err != nil {
+		return s, err
+	}
+	if s.auditQueueSize < 1 {
+		return s, fmt.Errorf("%s must be positive", auditQueueSizeEnv)
+	}
+	if s.updateRateLimit, err = util.NonNegativeIntFromEnv(updateRateLimitEnv, 0); err != nil {
+		return s, err
+	}
+	if s.updateRateWindow, err = util.DurationFromEnv(updateRateWindowEnv, defaultUpdateRateWindow); err != nil {
+		return s, err
+	}
+	if s.notifyURL = os.Getenv(notifyURLEnv); s.notifyURL != "" {
+		if err = accounts.ValidateURL(notifyURLEnv, s.notifyURL, []string{"https", "http"}); err != nil {
+			return s, err
+		}
+	}
+	if s.notifyTemplate, err = parseNotifyTemplate(os.Getenv(notifyTemplateEnv)); err != nil {
+		return s, fmt.Errorf("invalid %s: %w", notifyTemplateEnv, err)
+	}
+	s.notifyReasons = util.ListFromEnv(notifyReasonsEnv)
+	if s.notifyOutcomes = util.ListFromEnv(notifyOutcomesEnv); len(s.notifyOutcomes) == 0 {
+		s.notifyOutcomes = []string{"denied"}
+	}
+	if s.notifyMaxAttempts, err = util.IntFromEnv(notifyMaxAttemptsEnv, defaultNotifyMaxAttempts); err != nil {
+		return s, err
+	}
+	if s.notifyMaxAttempts < 1 {
+		return s, fmt.Errorf("%s must be positive", notifyMaxAttemptsEnv)
+	}
+	if s.caseInsensitiveNames, err = util.BoolFromEnv(caseInsensitiveEnv, false); err != nil {
+		return s, err
+	}
+	if s.enforcementCutoff, err = cutoffFromEnv(enforcementCutoffEnv, time.Now()); err != nil {
+		return s, err
+	}
+	if s.async, err = accounts.AsyncValidationEnabled(); err != nil {
+		return s, err
+	}
+	if s.reservedPrefixes = util.ListFromEnv(reservedPrefixesEnv); len(s.reservedPrefixes) == 0 {
+		s.reservedPrefixes = defaultReservedPrefixes
+	}
+	if s.messages, err = loadCatalog(os.Getenv(localeEnv)); err != nil {
+		return s, err
+	}
+	if s.breakerThreshold, err = util.IntFromEnv(breakerThresholdEnv, defaultBreakerThreshold); err != nil {
+		return s, err
+	}
+	if s.breakerCooldown, err = util.DurationFromEnv(breakerCooldownEnv, defaultBreakerCooldown); err != nil {
+		return s, err
+	}
+	if s.breakerFailOpen, err = util.BoolFromEnv(breakerFailOpenEnv, false); err != nil {
+		return s, err
+	}
+	groupCheck, err := util.BoolFromEnv(identityGroupCheckEnv, false)
+	if err != nil {
+		return s, err
+	}
+	if groupCheck {
+		if s.identityGroupsURL = os.Getenv(identityGroupsURLEnv); s.identityGroupsURL == "" {
+			return s, fmt.Errorf("%s is required when %s is enabled", identityGroupsURLEnv, identityGroupCheckEnv)
+		}
+		if err = accounts.ValidateURL(identityGroupsURLEnv, s.identityGroupsURL, []string{"https", "http"}); err != nil {
+			return s, err
+		}
+	}
+	if s.secretNamespace = os.Getenv(secretNamespaceEnv); s.secretNamespace != "" {
+		if errs := validation.IsDNS1123Label(s.secretNamespace); len(errs) > 0 {
+			return s, fmt.Errorf("invalid %s \"%s\": %s", secretNamespaceEnv, s.secretNamespace, strings.Join(errs, ", "))
+		}
+	}
+	if s.secretNamespaceAllowlist, err = parseNamespaceAllowlist(util.ListFromEnv(secretNamespaceAllowlistEnv)); err != nil {
+		return s, fmt.Errorf("invalid %s: %w", secretNamespaceAllowlistEnv, err)
+	}
+	if s.bounds, err = accounts.BoundsFromEnv(); err != nil {
+		return s, err
+	}
+	if s.conflicts, err = accounts.SettingConflictsFromEnv(); err != nil {
+		return s, err
+	}
+	if s.groupConsistentSettings, err = groupConsistentSettingsFromEnv(); err != nil {
+		return s, err
+	}
+	if s.uncachedReads, err = util.BoolFromEnv(uncachedReadsEnv, false); err != nil {
+		return s, err
+	}
+	if s.readKubeconfig = os.Getenv(readKubeconfigEnv); s.readKubeconfig != "" && s.uncachedReads {
+		return s, fmt.Errorf("%s and %s can't be set together", readKubeconfigEnv, uncachedReadsEnv)
+	}
+	if s.allowedNamespaces, err = namespacesFromPolicy(); err != nil {
+		return s, err
+	}
+	if s.referencingKinds, err = accounts.ReferencingKindsFromEnv(); err != nil {
+		return s, err
+	}
+	if s.stages, err = stagesFromEnv(); err != nil {
+		return s, err
+	}
+	if s.schemaConfigMap, err = configMapFromEnv(schemaConfigMapEnv); err != nil {
+		return s, err
+	}
+	if s.severityConfigMap, err = configMapFromEnv(severityConfigMapEnv); err != nil {
+		return s, err
+	}
+	if s.deprecationConfigMap, err = configMapFromEnv(deprecationConfigMapEnv); err != nil {
+		return s, err
+	}
+	return s, nil
+}
+
+// groupConsistentSettingsFromEnv parses type=setting entries, e.g. "Kubernetes=providerVersion"
+func groupConsistentSettingsFromEnv() (map[string][]string, error) {
+	entries := util.ListFromEnv(groupConsistentSettingsEnv)
+	if len(entries) == 0 {
+		return defaultGroupConsistentSettings, nil
+	}
+	m := map[string][]string{}
+	for _, e := range entries {
+		kv := strings.SplitN(e, "=", 2)
+		if len(kv) != 2 || kv[0] == "" || kv[1] == "" {
+			return nil, fmt.Errorf("invalid %s entry \"%s\": expected type=setting", groupConsistentSettingsEnv, e)
+		}
+		tp := strings.ToLower(kv[0])
+		m[tp] = append(m[tp], kv[1])
+	}
+	return m, nil
+}
+
+// configMapFromEnv reads the namespace/name of a ConfigMap from the given environment variable, nil if not set
+func configMapFromEnv(env string) (*types.NamespacedName, error) {
+	v := os.Getenv(env)
+	if v == "" {
+		return nil, nil
+	}
+	parts := strings.Split(v, "/")
+	if len(parts) != 2 || len(validation.IsDNS1123Label(parts[0])) > 0 || len(validation.IsDNS1123Subdomain(parts[1])) > 0 {
+		return nil, fmt.Errorf("invalid %s \"%s\": expected namespace/name", env, v)
+	}
+	return &types.NamespacedName{Namespace: parts[0], Name: parts[1]}, nil
+}
+
+// cutoffFromEnv reads a RFC3339 timestamp, or a duration counted back from start, from the given environment variable
+func cutoffFromEnv(env string, start time.Time) (time.Time, error) {
+	v := os.Getenv(env)
+	if v == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		return time.Time{}, fmt.Errorf("invalid %s \"%s\": expected a RFC3339 timestamp (e.g. 2022-01-31T00:00:00Z) or a duration before the operator start (e.g. 0s)", env, v)
+	}
+	return start.Add(-d), nil
+}
+
+// validationOptions returns the options of account validators
+func (s settings) validationOptions() account.ValidationOptions {
+	return account.ValidationOptions{
+		Connectivity:         s.connectivity,
+		ProviderConnectivity: s.providerConnectivity,
+		ExpiryWarningWindow:  s.expiryWarningWindow,
+		Strict:               s.strict,
+	}
+}
+
+// isGrandfathered returns true for accounts created before the enforcement cutoff
+func (s settings) isGrandfathered(created time.Time) bool {
+	return !s.enforcementCutoff.IsZero() && created.Before(s.enforcementCutoff)
+}
+
+func (s settings) isTypeAllowed(t string) bool {
+	if len(s.allowedTypes) == 0 {
+		return true
+	}
+	for _, a := range s.allowedTypes {
+		if strings.EqualFold(a, t) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s settings) isPrivileged(name string) bool {
+	for _, p := range s.privilegedAccounts {
+		if p == name {
+			return true
+		}
+	}
+	return false
+}
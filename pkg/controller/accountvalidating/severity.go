@@ -0,0 +1,114 @@
+package accountvalidating
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/armory/spinnaker-operator/pkg/accounts/account"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// Severities of denial reasons in the severity ConfigMap
+const (
+	severityDeny   = "deny"
+	severityWarn   = "warn"
+	severityIgnore = "ignore"
+)
+
+// severitiesKey is the context key of the severities read for a request
+type severitiesKey struct{}
+
+// requestSeverities holds the severities read for a request
+type requestSeverities struct {
+	once       sync.Once
+	severities map[metav1.StatusReason]string
+	err        error
+}
+
+// withSeverities lets the severity ConfigMap be read at most once while handling a request
+func withSeverities(ctx context.Context) context.Context {
+	return context.WithValue(ctx, severitiesKey{}, &requestSeverities{})
+}
+
+// severities returns the severity of each reason in the severity ConfigMap, keyed by reason, reading the ConfigMap
+// once per request
+func (v *accountValidatingController) severities(ctx context.Context) (map[metav1.StatusReason]string, error) {
+	rs, ok := ctx.Value(severitiesKey{}).(*requestSeverities)
+	if !ok {
+		return v.readSeverities(ctx)
+	}
+	rs.once.Do(func() {
+		rs.severities, rs.err = v.readSeverities(ctx)
+	})
+	return rs.severities, rs.err
+}
+
+// readSeverities reads the severity ConfigMap. Unknown severities are logged and left out.
+func (v *accountValidatingController) readSeverities(ctx context.Context) (map[metav1.StatusReason]string, error) {
+	cm := &v1.ConfigMap{}
+	if err := v.client.Get(ctx, *v.settings.severityConfigMap, cm); err != nil {
+		return nil, fmt.Errorf("unable to get severities from ConfigMap %s: %w", v.settings.severityConfigMap, err)
+	}
+	s := map[metav1.StatusReason]string{}
+	for reason, severity := range cm.Data {
+		switch severity = strings.ToLower(strings.TrimSpace(severity)); severity {
+		case severityDeny, severityWarn, severityIgnore:
+			s[metav1.StatusReason(reason)] = severity
+		default:
+			log.Info("Ignoring unknown severity", "configMap", v.settings.severityConfigMap.String(), "reason", reason, "severity", severity)
+		}
+	}
+	return s, nil
+}
+
+// withSeverity returns nil for errors whose reason is configured as a warning, or ignored, in the severity
+// ConfigMap, recording a warning for the former, so that the following stages still run. Errors are left as is if
+// the ConfigMap can't be read.
+func (v *accountValidatingController) withSeverity(ctx context.Context, err error) error {
+	if err == nil || v.settings.severityConfigMap == nil {
+		return err
+	}
+	reason := responseFor(err).Result.Reason
+	if reason == "" {
+		return err
+	}
+	s, serr := v.severities(ctx)
+	if serr != nil {
+		log.Error(serr, "Unable to apply severities")
+		return err
+	}
+	switch s[reason] {
+	case severityWarn:
+		account.Warn(ctx, "%s (%s is configured as a warning)", err.Error(), reason)
+		return nil
+	case severityIgnore:
+		return nil
+	}
+	return err
+}
+
+// responseWithSeverity admits denied objects whose reason is configured as a warning, or ignored, in the severity
+// ConfigMap, for the denials returned outside of the validation stages. Denials are left as is if the ConfigMap
+// can't be read.
+func (v *accountValidatingController) responseWithSeverity(ctx context.Context, r admission.Response) admission.Response {
+	if v.settings.severityConfigMap == nil || r.Allowed || r.Result == nil || r.Result.Reason == "" {
+		return r
+	}
+	s, err := v.severities(ctx)
+	if err != nil {
+		log.Error(err, "Unable to apply severities")
+		return r
+	}
+	switch s[r.Result.Reason] {
+	case severityWarn:
+		msg := fmt.Sprintf("%s (%s is configured as a warning)", r.Result.Message, r.Result.Reason)
+		return admission.ValidationResponse(true, "").WithWarnings(append(r.Warnings, msg)...)
+	case severityIgnore:
+		return admission.ValidationResponse(true, "").WithWarnings(r.Warnings...)
+	}
+	return r
+}
@@ -0,0 +1,93 @@
+package accountvalidating
+
+import (
+	"context"
+	"testing"
+
+	"github.com/armory/spinnaker-operator/pkg/accounts"
+	"github.com/armory/spinnaker-operator/pkg/controller/accountvalidating/accountvalidatingtest"
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestHandleSeverity(t *testing.T) {
+	api := newFakeKubernetesAPI(t)
+	t.Setenv(connectivityEnv, "false")
+	t.Setenv(allowedAccountTypesEnv, "AWS")
+	t.Setenv(severityConfigMapEnv, "operator/severities")
+	req := accountvalidatingtest.NewAccountAdmissionRequest(kubernetesAccount(t, "kube", api.URL, "{}"), admissionv1.Create)
+	severities := func(data map[string]string) *v1.ConfigMap {
+		return &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "operator", Name: "severities"}, Data: data}
+	}
+
+	t.Run("downgraded to a warning", func(t *testing.T) {
+		v := newTestController(t, severities(map[string]string{string(ReasonAccountTypeNotAllowed): "warn"}))
+		r := v.Handle(context.TODO(), req)
+		assert.True(t, r.Allowed)
+		assert.Equal(t, []string{"account type Kubernetes is not allowed in this cluster, allowed types are AWS (AccountTypeNotAllowed is configured as a warning)"}, r.Warnings)
+	})
+
+	t.Run("ignored", func(t *testing.T) {
+		v := newTestController(t, severities(map[string]string{string(ReasonAccountTypeNotAllowed): "ignore"}))
+		r := v.Handle(context.TODO(), req)
+		assert.True(t, r.Allowed)
+		assert.Empty(t, r.Warnings)
+	})
+
+	t.Run("denied", func(t *testing.T) {
+		v := newTestController(t, severities(map[string]string{string(ReasonAccountTypeNotAllowed): "deny", string(ReasonPolicyDenied): "warn"}))
+		r := v.Handle(context.TODO(), req)
+		assert.False(t, r.Allowed)
+		assert.Equal(t, ReasonAccountTypeNotAllowed, r.Result.Reason)
+	})
+
+	t.Run("downgraded check continues validation", func(t *testing.T) {
+		t.Setenv(allowedAccountTypesEnv, "")
+		t.Setenv(strictEnv, "true")
+		t.Setenv(accounts.AsyncValidationEnv, "true")
+		reserved := kubernetesAccount(t, "kube", api.URL, "{}")
+		reserved.SetAnnotations(map[string]string{"spinnaker.io/managed": "true"})
+		v := newTestController(t, severities(map[string]string{string(ReasonReservedMetadataKey): "warn"}))
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountAdmissionRequest(reserved, admissionv1.Create))
+		assert.True(t, r.Allowed)
+		assert.Equal(t, []string{
+			"account kube uses keys reserved by Spinnaker: spinnaker.io/managed (ReservedMetadataKey is configured as a warning)",
+			"account kube will be validated in the background, see its Validated condition",
+		}, r.Warnings)
+	})
+
+	t.Run("ConfigMap read once", func(t *testing.T) {
+		t.Setenv(allowedAccountTypesEnv, "")
+		t.Setenv(strictEnv, "true")
+		reserved := kubernetesAccount(t, "kube", api.URL, "{}")
+		reserved.SetAnnotations(map[string]string{"spinnaker.io/managed": "true"})
+		v := newTestController(t, severities(map[string]string{string(ReasonReservedMetadataKey): "deny"}))
+		c := &countingGetClient{Client: v.client}
+		v.client = c
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountAdmissionRequest(reserved, admissionv1.Create))
+		assert.False(t, r.Allowed)
+		assert.Equal(t, ReasonReservedMetadataKey, r.Result.Reason)
+		assert.Equal(t, 1, c.configMapGets)
+	})
+
+	t.Run("missing ConfigMap", func(t *testing.T) {
+		r := newTestController(t).Handle(context.TODO(), req)
+		assert.False(t, r.Allowed)
+	})
+}
+
+// countingGetClient counts the ConfigMaps read
+type countingGetClient struct {
+	client.Client
+	configMapGets int
+}
+
+func (c *countingGetClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	if _, ok := obj.(*v1.ConfigMap); ok {
+		c.configMapGets++
+	}
+	return c.Client.Get(ctx, key, obj)
+}
@@ -0,0 +1,33 @@
+package accountvalidating
+
+import (
+	"fmt"
+	"net/http"
+
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// checkObjectSize rejects objects whose serialized size exceeds the configured limit, before the API server
+// rejects them with a less helpful error when storing them
+func (v *accountValidatingController) checkObjectSize(req admission.Request) error {
+	size := len(req.Object.Raw)
+	if size <= v.settings.maxObjectSize {
+		return nil
+	}
+	return &statusError{
+		code:   http.StatusUnprocessableEntity,
+		reason: ReasonObjectTooLarge,
+		err: fmt.Errorf("%s %s is %s serialized, exceeding the limit of %s (%s). Move large values such as certificate chains to secrets",
+			req.Kind.Kind, req.Name, formatSize(size), formatSize(v.settings.maxObjectSize), maxObjectSizeEnv),
+	}
+}
+
+func formatSize(b int) string {
+	switch {
+	case b >= 1024*1024:
+		return fmt.Sprintf("%.1fMiB", float64(b)/(1024*1024))
+	case b >= 1024:
+		return fmt.Sprintf("%.1fKiB", float64(b)/1024)
+	}
+	return fmt.Sprintf("%dB", b)
+}
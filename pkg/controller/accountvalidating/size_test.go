@@ -0,0 +1,46 @@
+package accountvalidating
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/armory/spinnaker-operator/pkg/controller/accountvalidating/accountvalidatingtest"
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+)
+
+func TestHandleObjectSize(t *testing.T) {
+	api := newFakeKubernetesAPI(t)
+	acc := kubernetesAccount(t, "kube", api.URL, "{}")
+	req := accountvalidatingtest.NewAccountAdmissionRequest(acc, admissionv1.Create)
+	size := len(req.Object.Raw)
+
+	t.Run("default limit", func(t *testing.T) {
+		v := newTestController(t)
+		assert.True(t, v.Handle(context.TODO(), req).Allowed)
+	})
+
+	t.Run("at the limit", func(t *testing.T) {
+		t.Setenv(maxObjectSizeEnv, strconv.Itoa(size))
+		v := newTestController(t)
+		assert.True(t, v.Handle(context.TODO(), req).Allowed)
+	})
+
+	t.Run("over the limit", func(t *testing.T) {
+		t.Setenv(maxObjectSizeEnv, strconv.Itoa(size-1))
+		v := newTestController(t)
+		r := v.Handle(context.TODO(), req)
+		assert.False(t, r.Allowed)
+		assert.Equal(t, ReasonObjectTooLarge, r.Result.Reason)
+		assert.Equal(t, fmt.Sprintf("SpinnakerAccount kube is %s serialized, exceeding the limit of %s (ACCOUNT_MAX_OBJECT_SIZE). Move large values such as certificate chains to secrets", formatSize(size), formatSize(size-1)), r.Result.Message)
+	})
+}
+
+func TestFormatSize(t *testing.T) {
+	assert.Equal(t, "512B", formatSize(512))
+	assert.Equal(t, "1.5KiB", formatSize(1536))
+	assert.Equal(t, "1.0MiB", formatSize(defaultMaxObjectSize))
+	assert.Equal(t, "1.5MiB", formatSize(1536*1024))
+}
@@ -0,0 +1,256 @@
+package accountvalidating
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/armory/spinnaker-operator/pkg/accounts"
+	"github.com/armory/spinnaker-operator/pkg/accounts/account"
+	"github.com/armory/spinnaker-operator/pkg/accounts/validator"
+	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
+	"github.com/armory/spinnaker-operator/pkg/util"
+)
+
+const validationStagesEnv = "ACCOUNT_VALIDATION_STAGES"
+
+// Validation stages, run in the order of ACCOUNT_VALIDATION_STAGES
+const (
+	// structuralStage checks the account is well-formed without reading other objects
+	structuralStage = "structural"
+	// secretsStage compares the secrets of the account with the secrets of other accounts
+	secretsStage = "secrets"
+	// uniquenessStage compares the account with the other accounts of its namespace
+	uniquenessStage = "uniqueness"
+	// referencesStage checks the account against its SpinnakerService and the manifests referencing it
+	referencesStage = "references"
+	// connectivityStage runs the validator of the account's provider, connecting to the account if enabled
+	connectivityStage = "connectivity"
+	// policyStage checks the account against external policies
+	policyStage = "policy"
+	// inventoryStage checks the account is registered in the inventory
+	inventoryStage = "inventory"
+)
+
+// defaultStages are cheap structural checks first, and checks calling out to other services last
+var defaultStages = []string{structuralStage, secretsStage, uniquenessStage, referencesStage, connectivityStage, policyStage, inventoryStage}
+
+type validationStage func(v *accountValidatingController, ctx context.Context, r *validationRun) error
+
+var validationStages = map[string]validationStage{
+	structuralStage:   (*accountValidatingController).checkStructure,
+	secretsStage:      (*accountValidatingController).checkSecrets,
+	uniquenessStage:   (*accountValidatingController).checkUniqueness,
+	referencesStage:   (*accountValidatingController).checkReferences,
+	connectivityStage: (*accountValidatingController).checkProvider,
+	policyStage:       (*accountValidatingController).checkPolicies,
+	inventoryStage:    (*accountValidatingController).checkRegistered,
+}
+
+// stagesFromEnv returns the validation stages to run in order, stages not listed are skipped
+func stagesFromEnv() ([]string, error) {
+	stages := util.ListFromEnv(validationStagesEnv)
+	if len(stages) == 0 {
+		return defaultStages, nil
+	}
+	seen := map[string]bool{}
+	for _, s := range stages {
+		if _, ok := validationStages[s]; !ok {
+			return nil, fmt.Errorf("unknown validation stage %s in %s, valid stages are %s", s, validationStagesEnv, strings.Join(defaultStages, ", "))
+		}
+		if seen[s] {
+			return nil, fmt.Errorf("validation stage %s is listed more than once in %s", s, validationStagesEnv)
+		}
+		seen[s] = true
+	}
+	return stages, nil
+}
+
+// validationRun holds what the stages validating an account share
+type validationRun struct {
+	*validator.Run
+	// spinSvc is resolved on first use
+	spinSvc     interfaces.SpinnakerService
+	svcResolved bool
+}
+
+func (v *accountValidatingController) service(ctx context.Context, r *validationRun) (interfaces.SpinnakerService, error) {
+	if !r.svcResolved {
+		spinSvc, err := v.resolveService(ctx, r.Account)
+		if err != nil {
+			return nil, internalError(err)
+		}
+		r.spinSvc, r.svcResolved = spinSvc, true
+	}
+	return r.spinSvc, nil
+}
+
+func (v *accountValidatingController) checkStructure(ctx context.Context, r *validationRun) error {
+	recordCheck(ctx, structuralCheck)
+	acc := r.Account
+	for _, c := range validator.StructuralChecks(v.settings.conflicts) {
+		if err := c(ctx, r.Run); err != nil {
+			return err
+		}
+	}
+	if err := v.checkSchema(ctx, acc); err != nil {
+		return err
+	}
+
+	recordCheck(ctx, reservedKeysCheck)
+	if keys := reservedKeys(acc, v.settings.reservedPrefixes); len(keys) > 0 {
+		msg := fmt.Sprintf("account %s uses keys reserved by Spinnaker: %s", acc.GetName(), strings.Join(keys, ", "))
+		if v.settings.strict {
+			return rejected(ReasonReservedMetadataKey, msg)
+		}
+		account.Warn(ctx, msg)
+	}
+
+	if err := accounts.CheckBounds(r.Type, acc, v.settings.bounds); err != nil {
+		if v.settings.strict {
+			return err
+		}
+		account.Warn(ctx, err.Error())
+	}
+	return v.checkDeprecations(ctx, acc)
+}
+
+func (v *accountValidatingController) checkSecrets(ctx context.Context, r *validationRun) error {
+	if err := v.checkSecretNamespaces(ctx, r.Account); err != nil {
+		return err
+	}
+
+	if v.settings.secretConflicts {
+		recordCheck(ctx, secretConflictsCheck)
+		w, err := v.secretConflicts(ctx, r.Account)
+		if err != nil {
+			return internalError(err)
+		}
+		for _, msg := range w {
+			account.Warn(ctx, msg)
+		}
+	}
+
+	if len(v.settings.privilegedAccounts) > 0 {
+		recordCheck(ctx, privilegedCheck)
+		msgs, err := v.privilegedSecretSharing(ctx, r.Account)
+		if err != nil {
+			return internalError(err)
+		}
+		if len(msgs) > 0 && v.settings.strict {
+			return rejected(ReasonPrivilegedSecretShared, strings.Join(msgs, "; "))
+		}
+		for _, msg := range msgs {
+			account.Warn(ctx, msg)
+		}
+	}
+	return nil
+}
+
+func (v *accountValidatingController) checkUniqueness(ctx context.Context, r *validationRun) error {
+	if v.settings.softLimit > 0 {
+		recordCheck(ctx, softLimitCheck)
+		msg, err := v.checkSoftLimit(ctx, r.Account)
+		if err != nil {
+			return internalError(err)
+		}
+		if msg != "" {
+			account.Warn(ctx, msg)
+		}
+	}
+
+	if v.settings.duplicateTargets {
+		recordCheck(ctx, duplicateTargetsCheck)
+		w, err := v.duplicateTargets(ctx, r.Type, r.Account)
+		if err != nil {
+			return internalError(err)
+		}
+		for _, msg := range w {
+			account.Warn(ctx, msg)
+		}
+	}
+	return nil
+}
+
+func (v *accountValidatingController) checkReferences(ctx context.Context, r *validationRun) error {
+	acc := r.Account
+	spinSvc, err := v.service(ctx, r)
+	if err != nil {
+		return err
+	}
+	if spinSvc != nil {
+		spinAccount, err := r.Parsed()
+		if err != nil {
+			return err
+		}
+		recordCheck(ctx, compatibilityCheck)
+		w, err := accounts.CheckCompatibility(spinAccount, getSpinnakerVersion(ctx, spinSvc))
+		if err != nil {
+			return err
+		}
+		for _, msg := range w {
+			account.Warn(ctx, msg)
+		}
+		for _, msg := range accounts.CheckPermissions(acc, spinSvc) {
+			account.Warn(ctx, msg)
+		}
+		for _, msg := range accounts.CheckReferences(ctx, acc, spinSvc) {
+			account.Warn(ctx, msg)
+		}
+		if err := v.checkDisable(ctx, acc, spinSvc); err != nil {
+			return err
+		}
+	}
+
+	if old, ok := validator.PreviousFrom(ctx); ok && old.GetSpec().Enabled && !acc.GetSpec().Enabled {
+		return v.checkManifestReferences(ctx, acc, "disabled")
+	}
+	return nil
+}
+
+func (v *accountValidatingController) checkProvider(ctx context.Context, r *validationRun) error {
+	spinAccount, err := r.Parsed()
+	if err != nil {
+		return err
+	}
+	if v.settings.async {
+		account.Warn(ctx, "account %s will be validated in the background, see its %s condition", r.Account.GetName(), interfaces.AccountValidatedCondition)
+		return nil
+	}
+	v.checkNotificationTargets(ctx, r.Account)
+	av := validatorFor(spinAccount.GetType())
+	if av == nil {
+		log.Info("No validator registered for account type", "type", spinAccount.GetType())
+		return nil
+	}
+	spinSvc, err := v.service(ctx, r)
+	if err != nil {
+		return err
+	}
+	recordCheck(ctx, providerCheck)
+	start := time.Now()
+	err = av.Validate(ctx, spinAccount, spinSvc, v.client)
+	log.V(2).Info("Validated account", "account", r.Account.GetName(), "type", spinAccount.GetType(), "duration", time.Since(start).String())
+	return err
+}
+
+func (v *accountValidatingController) checkPolicies(ctx context.Context, r *validationRun) error {
+	if v.settings.identityGroupsURL != "" {
+		recordCheck(ctx, identityGroupsCheck)
+		v.checkIdentityGroups(ctx, r.Account)
+	}
+	if v.settings.opaURL != "" {
+		recordCheck(ctx, policyCheck)
+		return v.checkPolicy(ctx, r.Account)
+	}
+	return nil
+}
+
+func (v *accountValidatingController) checkRegistered(ctx context.Context, r *validationRun) error {
+	if v.settings.inventoryURL == "" {
+		return nil
+	}
+	recordCheck(ctx, inventoryCheck)
+	return v.checkInventory(ctx, r.Account)
+}
@@ -0,0 +1,56 @@
+package accountvalidating
+
+import (
+	"context"
+	"testing"
+
+	"github.com/armory/spinnaker-operator/pkg/controller/accountvalidating/accountvalidatingtest"
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+)
+
+func TestHandleStageOrder(t *testing.T) {
+	// the account has an invalid label selector and its server can't be reached
+	acc := kubernetesAccount(t, "kube1", "https://127.0.0.1:1", `labelSelector: "env in prod"`)
+	req := accountvalidatingtest.NewAccountAdmissionRequest(acc, admissionv1.Create)
+
+	t.Run("structural first", func(t *testing.T) {
+		t.Setenv(validationStagesEnv, "structural,connectivity")
+		r := newTestController(t).Handle(context.TODO(), req)
+		assert.False(t, r.Allowed)
+		assert.Contains(t, r.Result.Message, "invalid label selector")
+	})
+
+	t.Run("connectivity first", func(t *testing.T) {
+		t.Setenv(validationStagesEnv, "connectivity,structural")
+		r := newTestController(t).Handle(context.TODO(), req)
+		assert.False(t, r.Allowed)
+		assert.NotContains(t, r.Result.Message, "invalid label selector")
+		assert.Contains(t, r.Result.Message, "127.0.0.1:1")
+	})
+
+	t.Run("structural disabled", func(t *testing.T) {
+		t.Setenv(validationStagesEnv, "secrets,policy")
+		r := newTestController(t).Handle(context.TODO(), req)
+		assert.True(t, r.Allowed)
+	})
+}
+
+func TestStagesFromEnv(t *testing.T) {
+	s, err := stagesFromEnv()
+	assert.Nil(t, err)
+	assert.Equal(t, defaultStages, s)
+
+	t.Setenv(validationStagesEnv, "policy, structural")
+	s, err = stagesFromEnv()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"policy", "structural"}, s)
+
+	t.Setenv(validationStagesEnv, "structural,lint")
+	_, err = stagesFromEnv()
+	assert.EqualError(t, err, "unknown validation stage lint in ACCOUNT_VALIDATION_STAGES, valid stages are structural, secrets, uniqueness, references, connectivity, policy, inventory")
+
+	t.Setenv(validationStagesEnv, "policy,policy")
+	_, err = stagesFromEnv()
+	assert.EqualError(t, err, "validation stage policy is listed more than once in ACCOUNT_VALIDATION_STAGES")
+}
@@ -0,0 +1,47 @@
+package accountvalidating
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// cacheSyncCheckTimeout bounds the time spent checking whether the manager's cache is synced
+const cacheSyncCheckTimeout = 50 * time.Millisecond
+
+var errCacheNotSynced = errors.New("the operator is starting and its cache of accounts and services is not synced yet, retry later")
+
+// cacheSyncer is the part of the manager's cache telling whether it's synced
+type cacheSyncer interface {
+	WaitForCacheSync(ctx context.Context) bool
+}
+
+// syncTracker remembers once the manager's cache is synced, so it's only checked while the operator starts
+type syncTracker struct {
+	mu     sync.Mutex
+	synced bool
+}
+
+// cacheSynced returns false while the cache the client reads from isn't synced. Validations listing accounts,
+// secrets or services would otherwise see partial lists and wrongly deny accounts.
+func (v *accountValidatingController) cacheSynced(ctx context.Context) bool {
+	if v.cache == nil {
+		return true
+	}
+	v.sync.mu.Lock()
+	defer v.sync.mu.Unlock()
+	if v.sync.synced {
+		return true
+	}
+	ctx, cancel := context.WithTimeout(ctx, cacheSyncCheckTimeout)
+	defer cancel()
+	v.sync.synced = v.cache.WaitForCacheSync(ctx)
+	return v.sync.synced
+}
+
+// unavailable returns an error for a request that can't be validated yet
+func unavailable(err error) error {
+	return &statusError{code: http.StatusServiceUnavailable, err: err}
+}
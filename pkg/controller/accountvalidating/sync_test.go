@@ -0,0 +1,43 @@
+package accountvalidating
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/armory/spinnaker-operator/pkg/controller/accountvalidating/accountvalidatingtest"
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+)
+
+type fakeCacheSyncer struct {
+	synced bool
+	calls  int
+}
+
+func (f *fakeCacheSyncer) WaitForCacheSync(ctx context.Context) bool {
+	f.calls++
+	return f.synced
+}
+
+func TestHandleUnsyncedCache(t *testing.T) {
+	api := newFakeKubernetesAPI(t)
+	acc := kubernetesAccount(t, "kube", api.URL, "{}")
+	req := accountvalidatingtest.NewAccountAdmissionRequest(acc, admissionv1.Create)
+	c := &fakeCacheSyncer{}
+	v := newTestController(t)
+	v.cache = c
+
+	r := v.Handle(context.TODO(), req)
+	assert.False(t, r.Allowed)
+	assert.Equal(t, int32(http.StatusServiceUnavailable), r.Result.Code)
+	assert.Equal(t, errCacheNotSynced.Error(), r.Result.Message)
+	if assert.NotNil(t, r.Result.Details) {
+		assert.Equal(t, int32(1), r.Result.Details.RetryAfterSeconds)
+	}
+
+	c.synced = true
+	assert.True(t, v.Handle(context.TODO(), req).Allowed)
+	assert.True(t, v.Handle(context.TODO(), req).Allowed)
+	assert.Equal(t, 2, c.calls, "the cache isn't checked once synced")
+}
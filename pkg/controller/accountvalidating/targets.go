@@ -0,0 +1,23 @@
+package accountvalidating
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/armory/spinnaker-operator/pkg/accounts"
+	"github.com/armory/spinnaker-operator/pkg/accounts/account"
+	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// duplicateTargets returns a warning for each enabled account of the namespace with the same target as the account
+func (v *accountValidatingController) duplicateTargets(ctx context.Context, t account.SpinnakerAccountType, acc interfaces.SpinnakerAccount) ([]string, error) {
+	if !acc.GetSpec().Enabled {
+		return nil, nil
+	}
+	list := TypesFactory.NewAccountList()
+	if err := v.reader().List(ctx, list, client.InNamespace(acc.GetNamespace())); err != nil {
+		return nil, fmt.Errorf("unable to list accounts in namespace %s: %w", acc.GetNamespace(), err)
+	}
+	return accounts.FindDuplicateTargets(t, acc, list.GetItems()), nil
+}
@@ -0,0 +1,34 @@
+package accountvalidating
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/armory/spinnaker-operator/pkg/controller/accountvalidating/accountvalidatingtest"
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+)
+
+func TestHandleDuplicateTargets(t *testing.T) {
+	api := newFakeKubernetesAPI(t)
+	existing := kubernetesAccount(t, "prod", api.URL, "{}")
+	t.Setenv(connectivityEnv, "false")
+	t.Setenv(duplicateTargetsEnv, "true")
+
+	t.Run("duplicate target", func(t *testing.T) {
+		v := newTestController(t, existing)
+		acc := kubernetesAccount(t, "prod2", strings.ToUpper(api.URL)+"/", "{}")
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountAdmissionRequest(acc, admissionv1.Create))
+		assert.True(t, r.Allowed)
+		assert.Equal(t, []string{"account prod2 has the same target as account prod: " + api.URL}, r.Warnings)
+	})
+
+	t.Run("unique target", func(t *testing.T) {
+		v := newTestController(t, existing)
+		acc := kubernetesAccount(t, "staging", strings.Replace(api.URL, "127.0.0.1", "localhost", 1), "{}")
+		r := v.Handle(context.TODO(), accountvalidatingtest.NewAccountAdmissionRequest(acc, admissionv1.Create))
+		assert.True(t, r.Allowed)
+		assert.Empty(t, r.Warnings)
+	})
+}
@@ -0,0 +1,52 @@
+package accountvalidating
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// typesPollInterval is how often Add checks whether account types are registered
+var typesPollInterval = 100 * time.Millisecond
+
+// waitForAccountTypes waits for TypesFactory to return account types known to the scheme, returning the GVKs of
+// accounts and account groups, or an error if they aren't registered within the timeout
+func waitForAccountTypes(scheme *runtime.Scheme, timeout time.Duration) (schema.GroupVersionKind, schema.GroupVersionKind, error) {
+	var gvk, groupGvk schema.GroupVersionKind
+	var lastErr error
+	err := wait.PollImmediate(typesPollInterval, timeout, func() (bool, error) {
+		gvk, groupGvk, lastErr = accountGVKs(scheme)
+		return lastErr == nil, nil
+	})
+	if err != nil {
+		return gvk, groupGvk, fmt.Errorf("account types not registered after %s (%s): %v", timeout, typesWaitTimeoutEnv, lastErr)
+	}
+	return gvk, groupGvk, nil
+}
+
+func accountGVKs(scheme *runtime.Scheme) (gvk schema.GroupVersionKind, groupGvk schema.GroupVersionKind, err error) {
+	// factories panic until the version they delegate to is registered
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("types factory not ready: %v", r)
+		}
+	}()
+	if TypesFactory == nil {
+		return gvk, groupGvk, errors.New("no types factory set")
+	}
+	acc, group := TypesFactory.NewAccount(), TypesFactory.NewAccountGroup()
+	if interfaces.IsNil(acc) || interfaces.IsNil(group) {
+		return gvk, groupGvk, errors.New("types factory returned no account type")
+	}
+	if gvk, err = apiutil.GVKForObject(acc, scheme); err != nil {
+		return gvk, groupGvk, err
+	}
+	groupGvk, err = apiutil.GVKForObject(group, scheme)
+	return gvk, groupGvk, err
+}
@@ -0,0 +1,63 @@
+package accountvalidating
+
+import (
+	"testing"
+	"time"
+
+	"github.com/armory/spinnaker-operator/pkg/apis"
+	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
+	"github.com/armory/spinnaker-operator/pkg/test"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// lateTypesFactory returns no account until it's been called more than after times
+type lateTypesFactory struct {
+	interfaces.TypesFactory
+	after int
+	calls int
+}
+
+func (f *lateTypesFactory) NewAccount() interfaces.SpinnakerAccount {
+	f.calls++
+	if f.calls <= f.after {
+		return nil
+	}
+	return f.TypesFactory.NewAccount()
+}
+
+func TestWaitForAccountTypes(t *testing.T) {
+	defer func(f interfaces.TypesFactory, i time.Duration) {
+		TypesFactory, typesPollInterval = f, i
+	}(TypesFactory, typesPollInterval)
+	typesPollInterval = time.Millisecond
+	s := runtime.NewScheme()
+	if err := apis.AddToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("registered after a while", func(t *testing.T) {
+		f := &lateTypesFactory{TypesFactory: test.TypesFactory, after: 3}
+		TypesFactory = f
+		gvk, groupGvk, err := waitForAccountTypes(s, time.Second)
+		if assert.Nil(t, err) {
+			assert.Equal(t, "SpinnakerAccount", gvk.Kind)
+			assert.Equal(t, "SpinnakerAccountGroup", groupGvk.Kind)
+			assert.Equal(t, 4, f.calls)
+		}
+	})
+
+	t.Run("never registered", func(t *testing.T) {
+		TypesFactory = &lateTypesFactory{TypesFactory: test.TypesFactory, after: 1 << 30}
+		_, _, err := waitForAccountTypes(s, 20*time.Millisecond)
+		if assert.NotNil(t, err) {
+			assert.Equal(t, "account types not registered after 20ms (ACCOUNT_TYPES_WAIT_TIMEOUT): types factory returned no account type", err.Error())
+		}
+	})
+
+	t.Run("unset factory", func(t *testing.T) {
+		TypesFactory = nil
+		_, _, err := waitForAccountTypes(s, 5*time.Millisecond)
+		assert.NotNil(t, err)
+	})
+}
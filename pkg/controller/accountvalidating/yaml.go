@@ -0,0 +1,100 @@
+package accountvalidating
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	k8syaml "sigs.k8s.io/yaml"
+)
+
+const accountKind = "SpinnakerAccount"
+
+// DocumentResult reports the validation of the account defined in a YAML document
+type DocumentResult struct {
+	// Document is the index of the document among the non-empty documents of the YAML stream, starting at 0
+	Document int
+	AccountResult
+}
+
+// ValidateAccountsYAML runs the validations of the admission webhook on each account of a multi-document YAML stream,
+// also checking account names are unique within each namespace across documents. Empty documents are skipped and accounts without
+// a namespace are validated in the given namespace.
+func ValidateAccountsYAML(ctx context.Context, c client.Client, restConfig *rest.Config, data []byte, namespace string) ([]DocumentResult, error) {
+	s, err := loadSettings()
+	if err != nil {
+		return nil, err
+	}
+	v := &accountValidatingController{client: c, restConfig: restConfig, settings: s}
+	return v.validateAccountsYAML(ctx, data, namespace)
+}
+
+func (v *accountValidatingController) validateAccountsYAML(ctx context.Context, data []byte, namespace string) ([]DocumentResult, error) {
+	reader := yaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(data)))
+	results := make([]DocumentResult, 0)
+	// names maps namespaced account names to the first document defining them
+	names := map[string]int{}
+	for {
+		doc, err := reader.Read()
+		if err == io.EOF {
+			return results, nil
+		}
+		i := len(results)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read YAML document %d: %w", i, err)
+		}
+		if len(bytes.TrimSpace(doc)) == 0 {
+			continue
+		}
+		acc, err := decodeAccount(doc, namespace)
+		if err != nil {
+			results = append(results, DocumentResult{Document: i, AccountResult: AccountResult{Status: AccountFailed, Error: err.Error()}})
+			continue
+		}
+		r := AccountResult{Namespace: acc.GetNamespace(), Name: acc.GetName(), Status: AccountPassed}
+		name := acc.GetName()
+		if v.settings.caseInsensitiveNames {
+			name = strings.ToLower(name)
+		}
+		key := acc.GetNamespace() + "/" + name
+		if first, ok := names[key]; ok {
+			r.Status = AccountFailed
+			r.Error = fmt.Sprintf("account name %s is already used in namespace %s in document %d", acc.GetName(), acc.GetNamespace(), first)
+			results = append(results, DocumentResult{Document: i, AccountResult: r})
+			continue
+		}
+		names[key] = i
+
+		warnings, err := v.validate(ctx, acc)
+		switch {
+		case err != nil:
+			r.Status = AccountFailed
+			r.Error = err.Error()
+		case len(warnings) > 0:
+			r.Status = AccountWarned
+			r.Warnings = warnings
+		}
+		results = append(results, DocumentResult{Document: i, AccountResult: r})
+	}
+}
+
+func decodeAccount(doc []byte, namespace string) (interfaces.SpinnakerAccount, error) {
+	acc := TypesFactory.NewAccount()
+	if err := k8syaml.UnmarshalStrict(doc, acc); err != nil {
+		return nil, fmt.Errorf("unable to decode account: %w", err)
+	}
+	if kind := acc.GetObjectKind().GroupVersionKind().Kind; kind != accountKind {
+		return nil, fmt.Errorf("expected kind %s, found \"%s\"", accountKind, kind)
+	}
+	if acc.GetNamespace() == "" {
+		acc.SetNamespace(namespace)
+	}
+	return acc, nil
+}
@@ -0,0 +1,66 @@
+package accountvalidating
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/yaml"
+)
+
+func TestValidateAccountsYAML(t *testing.T) {
+	api := newFakeKubernetesAPI(t)
+	docs := make([]string, 0)
+	for _, acc := range []interfaces.SpinnakerAccount{
+		kubernetesAccount(t, "kube-a", api.URL, "{}"),
+		kubernetesAccount(t, "kube-b", "mycluster.com", "{}"),
+	} {
+		acc.SetNamespace("")
+		b, err := yaml.Marshal(acc)
+		if !assert.Nil(t, err) {
+			return
+		}
+		docs = append(docs, string(b))
+	}
+
+	v := newTestController(t)
+	results, err := v.validateAccountsYAML(context.TODO(), []byte(strings.Join(docs, "---\n")), "ns1")
+	if !assert.Nil(t, err) || !assert.Len(t, results, 2) {
+		return
+	}
+	assert.Equal(t, DocumentResult{Document: 0, AccountResult: AccountResult{Namespace: "ns1", Name: "kube-a", Status: AccountPassed}}, results[0])
+	assert.Equal(t, 1, results[1].Document)
+	assert.Equal(t, "kube-b", results[1].Name)
+	assert.Equal(t, AccountFailed, results[1].Status)
+	assert.Contains(t, results[1].Error, "invalid endpoint")
+
+	t.Run("cross-document checks", func(t *testing.T) {
+		data := strings.Join([]string{docs[0], "", docs[0], "apiVersion: v1\nkind: ConfigMap\n"}, "---\n")
+		results, err := v.validateAccountsYAML(context.TODO(), []byte(data), "ns1")
+		if !assert.Nil(t, err) || !assert.Len(t, results, 3) {
+			return
+		}
+		assert.Equal(t, AccountPassed, results[0].Status)
+		assert.Equal(t, 1, results[1].Document)
+		assert.Equal(t, "account name kube-a is already used in namespace ns1 in document 0", results[1].Error)
+		assert.Equal(t, 2, results[2].Document)
+		assert.Equal(t, `expected kind SpinnakerAccount, found "ConfigMap"`, results[2].Error)
+	})
+
+	t.Run("same name in other namespace", func(t *testing.T) {
+		acc := kubernetesAccount(t, "kube-a", api.URL, "{}")
+		acc.SetNamespace("ns2")
+		other, err := yaml.Marshal(acc)
+		if !assert.Nil(t, err) {
+			return
+		}
+		results, err := v.validateAccountsYAML(context.TODO(), []byte(docs[0]+"---\n"+string(other)), "ns1")
+		if !assert.Nil(t, err) || !assert.Len(t, results, 2) {
+			return
+		}
+		assert.Equal(t, "ns2", results[1].Namespace)
+		assert.Empty(t, results[1].Error)
+	})
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"github.com/armory/spinnaker-operator/pkg/accounts"
 	"github.com/armory/spinnaker-operator/pkg/accounts/account"
+	"github.com/armory/spinnaker-operator/pkg/accounts/validator"
 	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
+	"github.com/armory/spinnaker-operator/pkg/controller/accountvalidating"
 	"github.com/armory/spinnaker-operator/pkg/secrets"
 	"github.com/armory/spinnaker-operator/pkg/util"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -27,15 +29,28 @@ var TypesFactory interfaces.TypesFactory
 // Add creates a new SpinnakerService Controller and adds it to the Manager. The Manager will set fields on the Controller
 // and Start it when the Manager is Started.
 func Add(mgr manager.Manager) error {
-	return add(mgr, newReconciler(mgr))
+	async, err := accounts.AsyncValidationEnabled()
+	if err != nil {
+		return err
+	}
+	var opts validator.Options
+	if async {
+		if opts, err = accountvalidating.BackgroundOptions(); err != nil {
+			return err
+		}
+		opts.Validation.APIReader = mgr.GetAPIReader()
+	}
+	return add(mgr, newReconciler(mgr, async, opts))
 }
 
 // newReconciler returns a new reconcile.Reconciler
-func newReconciler(mgr manager.Manager) reconcile.Reconciler {
+func newReconciler(mgr manager.Manager, async bool, opts validator.Options) reconcile.Reconciler {
 	return &ReconcileSpinnakerAccount{
-		client:     mgr.GetClient(),
-		restConfig: mgr.GetConfig(),
-		scheme:     mgr.GetScheme(),
+		client:            mgr.GetClient(),
+		restConfig:        mgr.GetConfig(),
+		scheme:            mgr.GetScheme(),
+		async:             async,
+		validationOptions: opts,
 	}
 }
 
@@ -70,6 +85,10 @@ type ReconcileSpinnakerAccount struct {
 	client     client.Client
 	restConfig *rest.Config
 	scheme     *runtime.Scheme
+	// async validates accounts before deploying them
+	async bool
+	// validationOptions are the options of the webhook for the validations it leaves to the controller
+	validationOptions validator.Options
 }
 
 // Reconcile reads that state of the cluster for a SpinnakerService object and makes changes based on the state read
@@ -104,6 +123,11 @@ func (r *ReconcileSpinnakerAccount) Reconcile(ctx context.Context, request recon
 	if err != nil {
 		return reconcile.Result{}, err
 	}
+	if r.async {
+		if err = r.validate(ctx, instance); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
 	cpInstance := instance.DeepCopyInterface()
 	err = r.deploy(ctx, cpInstance, aType)
 	return reconcile.Result{}, err
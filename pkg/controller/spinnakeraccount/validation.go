@@ -0,0 +1,80 @@
+package spinnakeraccount
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/armory/spinnaker-operator/pkg/accounts/validator"
+	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
+	"github.com/armory/spinnaker-operator/pkg/util"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	ReasonValidating       = "Validating"
+	ReasonValid            = "Valid"
+	ReasonValidationFailed = "ValidationFailed"
+
+	// backgroundValidationTimeout bounds validations that are not limited by the admission webhook timeout
+	backgroundValidationTimeout = 2 * time.Minute
+)
+
+// validate runs the account validations skipped by the admission webhook in async mode and reports the
+// outcome in the Validated condition. Validation failures are not returned as errors.
+func (r *ReconcileSpinnakerAccount) validate(ctx context.Context, instance interfaces.SpinnakerAccount) error {
+	c := meta.FindStatusCondition(instance.GetStatus().Conditions, interfaces.AccountValidatedCondition)
+	if c != nil && c.ObservedGeneration == instance.GetGeneration() && c.Status != metav1.ConditionUnknown {
+		return nil
+	}
+	if err := r.setValidated(ctx, instance, metav1.ConditionUnknown, ReasonValidating, "Validation in progress"); err != nil {
+		return err
+	}
+
+	warnings, err := r.runValidation(ctx, instance)
+	if err != nil {
+		log.Info("Account failed validation", "metadata.name", instance.GetName(), "error", err.Error())
+		return r.setValidated(ctx, instance, metav1.ConditionFalse, ReasonValidationFailed, err.Error())
+	}
+	msg := "Account is valid"
+	if len(warnings) > 0 {
+		msg = fmt.Sprintf("%s with warnings: %s", msg, strings.Join(warnings, "; "))
+	}
+	return r.setValidated(ctx, instance, metav1.ConditionTrue, ReasonValid, msg)
+}
+
+func (r *ReconcileSpinnakerAccount) runValidation(ctx context.Context, instance interfaces.SpinnakerAccount) ([]string, error) {
+	spinSvc, err := util.FindSpinnakerService(r.client, instance.GetNamespace(), TypesFactory)
+	if err != nil {
+		return nil, err
+	}
+	opts := r.validationOptions
+	opts.RestConfig, opts.Client, opts.Service = r.restConfig, r.client, spinSvc
+	opts.Timeout = backgroundValidationTimeout
+	opts.Checks = []validator.Check{validator.ProviderCheck(spinSvc, r.client, log)}
+	opts.Log = log
+	res, err := validator.New(opts).Validate(ctx, instance)
+	return res.Warnings, err
+}
+
+func (r *ReconcileSpinnakerAccount) setValidated(ctx context.Context, instance interfaces.SpinnakerAccount, status metav1.ConditionStatus, reason, msg string) error {
+	st := instance.GetStatus()
+	meta.SetStatusCondition(&st.Conditions, metav1.Condition{
+		Type:               interfaces.AccountValidatedCondition,
+		Status:             status,
+		ObservedGeneration: instance.GetGeneration(),
+		Reason:             reason,
+		Message:            msg,
+	})
+	if status != metav1.ConditionUnknown {
+		now := time.Now()
+		st.LastValidatedAt = &metav1.Timestamp{Seconds: now.Unix(), Nanos: int32(now.Nanosecond())}
+		st.InvalidReason = ""
+		if status == metav1.ConditionFalse {
+			st.InvalidReason = msg
+		}
+	}
+	return r.client.Status().Update(ctx, instance)
+}
@@ -0,0 +1,127 @@
+package spinnakeraccount
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/armory/spinnaker-operator/pkg/apis"
+	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
+	"github.com/armory/spinnaker-operator/pkg/test"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func init() {
+	TypesFactory = test.TypesFactory
+}
+
+func TestBackgroundValidation(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, "/selfsubjectaccessreviews") {
+			fmt.Fprint(w, `{"kind":"SelfSubjectAccessReview","apiVersion":"authorization.k8s.io/v1","status":{"allowed":true}}`)
+			return
+		}
+		fmt.Fprint(w, `{"kind":"List","apiVersion":"v1","metadata":{},"items":[]}`)
+	}))
+	defer api.Close()
+
+	cases := []struct {
+		name     string
+		settings string
+		status   metav1.ConditionStatus
+		reason   string
+	}{
+		{"valid account", "namespaces: [ns1]", metav1.ConditionTrue, ReasonValid},
+		{"invalid account", "{namespaces: [ns1], omitNamespaces: [ns2]}", metav1.ConditionFalse, ReasonValidationFailed},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			acc := test.TypesFactory.NewAccount()
+			test.ReadYamlString([]byte(fmt.Sprintf(`
+apiVersion: spinnaker.io/v1alpha2
+kind: SpinnakerAccount
+metadata:
+  name: kube
+  namespace: ns1
+  generation: 1
+spec:
+  enabled: true
+  type: Kubernetes
+  kubernetes:
+    kubeconfig:
+      apiVersion: v1
+      kind: Config
+      current-context: ctx
+      clusters:
+      - name: cluster
+        cluster:
+          server: %s
+      contexts:
+      - name: ctx
+        context:
+          cluster: cluster
+          user: user
+      users:
+      - name: user
+        user:
+          token: token
+  settings:
+    %s
+`, api.URL, c.settings)), acc, t)
+
+			s := runtime.NewScheme()
+			assert.Nil(t, clientgoscheme.AddToScheme(s))
+			assert.Nil(t, apis.AddToScheme(s))
+			r := &ReconcileSpinnakerAccount{
+				client: fake.NewClientBuilder().WithScheme(s).WithObjects(acc).Build(),
+				scheme: s,
+				async:  true,
+			}
+
+			key := types.NamespacedName{Namespace: "ns1", Name: "kube"}
+			_, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: key})
+			if !assert.Nil(t, err) {
+				return
+			}
+			updated := test.TypesFactory.NewAccount()
+			if !assert.Nil(t, r.client.Get(context.TODO(), key, updated)) {
+				return
+			}
+			cond := meta.FindStatusCondition(updated.GetStatus().Conditions, interfaces.AccountValidatedCondition)
+			if assert.NotNil(t, cond) {
+				assert.Equal(t, c.status, cond.Status)
+				assert.Equal(t, c.reason, cond.Reason)
+				assert.Equal(t, int64(1), cond.ObservedGeneration)
+			}
+			assert.NotNil(t, updated.GetStatus().LastValidatedAt)
+			assert.Equal(t, c.status == metav1.ConditionFalse, updated.GetStatus().InvalidReason != "")
+		})
+	}
+}
+
+func TestBackgroundValidationSkipsValidatedGeneration(t *testing.T) {
+	acc := test.TypesFactory.NewAccount()
+	acc.SetName("kube")
+	acc.SetNamespace("ns1")
+	acc.SetGeneration(2)
+	acc.GetStatus().Conditions = []metav1.Condition{{
+		Type:               interfaces.AccountValidatedCondition,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: 2,
+		Reason:             ReasonValid,
+	}}
+	// No client: any status update would panic
+	r := &ReconcileSpinnakerAccount{async: true}
+	assert.Nil(t, r.validate(context.TODO(), acc))
+}
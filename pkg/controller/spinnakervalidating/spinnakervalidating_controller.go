@@ -9,10 +9,13 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/armory/spinnaker-operator/pkg/accounts/account"
 	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
+	"github.com/armory/spinnaker-operator/pkg/controller/accountvalidating"
 	"github.com/armory/spinnaker-operator/pkg/controller/webhook"
 	"github.com/armory/spinnaker-operator/pkg/halyard"
 	"github.com/armory/spinnaker-operator/pkg/secrets"
+	"github.com/armory/spinnaker-operator/pkg/util"
 	"github.com/armory/spinnaker-operator/pkg/validate"
 	"gomodules.xyz/jsonpatch/v2"
 	v1 "k8s.io/api/admission/v1"
@@ -39,6 +42,8 @@ type spinnakerValidatingController struct {
 	client     client.Client
 	decoder    *admission.Decoder
 	restConfig *rest.Config
+	// validationOptions let validators connect to the accounts of the SpinnakerService and collect their warnings
+	validationOptions account.ValidationOptions
 }
 
 // TypesFactory instantiates the type we're going to validate
@@ -49,7 +54,7 @@ var _ admission.Handler = &spinnakerValidatingController{}
 var _ inject.Config = &spinnakerValidatingController{}
 var _ inject.Client = &spinnakerValidatingController{}
 var _ admission.DecoderInjector = &spinnakerValidatingController{}
-var log = logf.Log.WithName("spinvalidate")
+var log = util.VerboseLogger(logf.Log.WithName("spinvalidate"))
 
 // Add adds the validating admission controller
 func Add(m manager.Manager) error {
@@ -58,13 +63,18 @@ func Add(m manager.Manager) error {
 	if err != nil {
 		return err
 	}
-	webhook.Register(gvk, "spinnakerservices", &spinnakerValidatingController{})
+	opts, err := accountvalidating.ValidationOptions()
+	if err != nil {
+		return err
+	}
+	webhook.Register(gvk, "spinnakerservices", &spinnakerValidatingController{validationOptions: opts})
 	return nil
 }
 
 // Handle is the entry point for spinnaker preflight validations
 func (v *spinnakerValidatingController) Handle(ctx context.Context, req admission.Request) admission.Response {
 	log.Info(fmt.Sprintf("Handling admission request for: %s", req.AdmissionRequest.Kind.Kind))
+	log.V(2).Info("Admission request", "uid", req.UID, "operation", req.Operation, "object", util.RedactJSON(req.Object.Raw))
 	svc, err := v.getSpinnakerService(req)
 	if err != nil {
 		log.Error(err, "Unable to retrieve Spinnaker service from request")
@@ -83,7 +93,7 @@ func (v *spinnakerValidatingController) Handle(ctx context.Context, req admissio
 	}
 
 	opts := validate.Options{
-		Ctx:          secrets.NewContext(ctx, v.restConfig, req.Namespace),
+		Ctx:          account.NewValidationContext(secrets.NewContext(ctx, v.restConfig, req.Namespace), v.validationOptions),
 		Client:       v.client,
 		Req:          req,
 		Log:          log,
@@ -93,7 +103,9 @@ func (v *spinnakerValidatingController) Handle(ctx context.Context, req admissio
 	defer secrets.Cleanup(opts.Ctx)
 
 	log.Info("Validating SpinnakerService", "metadata.name", svc.GetName())
+	start := time.Now()
 	validationResult := validate.ValidateAll(svc, opts)
+	log.V(2).Info("Validated SpinnakerService", "metadata.name", svc.GetName(), "duration", time.Since(start).String())
 	if validationResult.HasErrors() {
 		errorMsg := validationResult.GetErrorMessage()
 		err := fmt.Errorf(errorMsg)
@@ -111,7 +123,11 @@ func (v *spinnakerValidatingController) Handle(ctx context.Context, req admissio
 		}
 	}
 	log.Info("SpinnakerService is valid", "metadata.name", svc.GetName())
-	return admission.ValidationResponse(true, "")
+	resp := admission.ValidationResponse(true, "")
+	if vc, ok := account.ValidationContextFrom(opts.Ctx); ok {
+		resp = resp.WithWarnings(vc.Warnings()...)
+	}
+	return resp
 }
 
 func (v *spinnakerValidatingController) NeedsValidation(lastValid metav1.Time) bool {
@@ -0,0 +1,155 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/armory/spinnaker-operator/pkg/util"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// TLSSecretEnv names a kubernetes.io/tls secret ("name" or "namespace/name") holding the webhook certificates
+	TLSSecretEnv = "WEBHOOK_TLS_SECRET"
+	// CertManagerCertificateEnv names the cert-manager Certificate ("name" or "namespace/name") whose secret is mounted in the certs dir
+	CertManagerCertificateEnv = "WEBHOOK_CERT_MANAGER_CERTIFICATE"
+	// CertMountDirEnv is a directory where tls.crt, tls.key and optionally ca.crt are mounted
+	CertMountDirEnv = "WEBHOOK_CERT_MOUNT_DIR"
+
+	certManagerInjectAnnotation = "cert-manager.io/inject-ca-from"
+)
+
+var log = util.VerboseLogger(logf.Log.WithName("webhook"))
+
+// caSource loads the webhook certificates, returning nil when the source is not configured
+type caSource struct {
+	name string
+	load func(ctx context.Context, c kubernetes.Interface, ns, svc string) (*certContext, error)
+}
+
+// caSources in order of precedence
+var caSources = []caSource{
+	{"external secret", loadFromSecret},
+	{"cert-manager", loadFromCertManager},
+	{"mounted file", loadFromMountedFiles},
+	{"self-signed", loadSelfSigned},
+}
+
+// resolveCertContext returns the certificates of the first configured CA source
+func resolveCertContext(ctx context.Context, c kubernetes.Interface, ns, svc string) (*certContext, error) {
+	for _, s := range caSources {
+		cc, err := s.load(ctx, c, ns, svc)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load webhook certificates from %s: %w", s.name, err)
+		}
+		if cc != nil {
+			log.Info("Using webhook certificates", "source", s.name)
+			recordCertificate(cc.cert)
+			return cc, nil
+		}
+	}
+	return nil, errors.New("no webhook certificate source available")
+}
+
+func loadFromSecret(ctx context.Context, c kubernetes.Interface, ns, _ string) (*certContext, error) {
+	v := os.Getenv(TLSSecretEnv)
+	if v == "" {
+		return nil, nil
+	}
+	secretNs, name := splitNamespacedName(v, ns)
+	s, err := c.CoreV1().Secrets(secretNs).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return writeSecretCerts(s)
+}
+
+// writeSecretCerts writes the certificates of a kubernetes.io/tls secret to the certs dir
+func writeSecretCerts(s *v1.Secret) (*certContext, error) {
+	crt, key := s.Data[certName], s.Data[keyName]
+	if len(crt) == 0 || len(key) == 0 {
+		return nil, fmt.Errorf("secret %s/%s must have %s and %s keys", s.Namespace, s.Name, certName, keyName)
+	}
+	ca := s.Data[caName]
+	if len(ca) == 0 {
+		ca = crt
+	}
+	if err := os.MkdirAll(CertsDir, 0700); err != nil {
+		return nil, err
+	}
+	for f, b := range map[string][]byte{certName: crt, keyName: key, caName: ca} {
+		if err := ioutil.WriteFile(filepath.Join(CertsDir, f), b, 0600); err != nil {
+			return nil, err
+		}
+	}
+	return &certContext{cert: crt, key: key, signingCert: ca, certDir: CertsDir}, nil
+}
+
+func loadFromCertManager(_ context.Context, _ kubernetes.Interface, ns, _ string) (*certContext, error) {
+	v := os.Getenv(CertManagerCertificateEnv)
+	if v == "" {
+		return nil, nil
+	}
+	crt, key, _, err := readCertFiles(CertsDir)
+	if err != nil {
+		return nil, err
+	}
+	certNs, name := splitNamespacedName(v, ns)
+	// The CA bundle is injected by cert-manager's cainjector
+	return &certContext{cert: crt, key: key, certDir: CertsDir, injectCAFrom: certNs + "/" + name}, nil
+}
+
+func loadFromMountedFiles(_ context.Context, _ kubernetes.Interface, _, _ string) (*certContext, error) {
+	dir := os.Getenv(CertMountDirEnv)
+	if dir == "" {
+		return nil, nil
+	}
+	crt, key, ca, err := readCertFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(ca) == 0 {
+		ca = crt
+	}
+	return &certContext{cert: crt, key: key, signingCert: ca, certDir: dir}, nil
+}
+
+func loadSelfSigned(_ context.Context, _ kubernetes.Interface, ns, svc string) (*certContext, error) {
+	// The certificate must also be valid for the host the API server calls when the service is skipped
+	var hosts []string
+	if h, _, err := net.SplitHostPort(os.Getenv(HostEnv)); err == nil {
+		hosts = append(hosts, h)
+	}
+	return getCertContext(ns, svc, hosts...)
+}
+
+// readCertFiles reads tls.crt and tls.key, and ca.crt if present, from the given directory
+func readCertFiles(dir string) (crt, key, ca []byte, err error) {
+	if crt, err = ioutil.ReadFile(filepath.Join(dir, certName)); err != nil {
+		return
+	}
+	if key, err = ioutil.ReadFile(filepath.Join(dir, keyName)); err != nil {
+		return
+	}
+	ca, err = ioutil.ReadFile(filepath.Join(dir, caName))
+	if os.IsNotExist(err) {
+		err = nil
+	}
+	return
+}
+
+func splitNamespacedName(v, defaultNs string) (string, string) {
+	if i := strings.Index(v, "/"); i >= 0 {
+		return v[:i], v[i+1:]
+	}
+	return defaultNs, v
+}
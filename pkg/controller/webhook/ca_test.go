@@ -0,0 +1,95 @@
+package webhook
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestResolveCertContextPrecedence(t *testing.T) {
+	defer func(d string) { CertsDir = d }(CertsDir)
+	CertsDir = filepath.Join(t.TempDir(), "certs")
+
+	mountDir := t.TempDir()
+	writeFile(t, filepath.Join(mountDir, certName), "mounted-crt")
+	writeFile(t, filepath.Join(mountDir, keyName), "mounted-key")
+
+	client := fake.NewSimpleClientset(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "webhook-tls", Namespace: "operator"},
+		Data: map[string][]byte{
+			certName: []byte("secret-crt"),
+			keyName:  []byte("secret-key"),
+			caName:   []byte("secret-ca"),
+		},
+	})
+
+	t.Setenv(CertMountDirEnv, mountDir)
+	t.Setenv(TLSSecretEnv, "webhook-tls")
+
+	// external secret wins over mounted files
+	c, err := resolveCertContext(context.TODO(), client, "operator", "spinnaker-operator")
+	if assert.Nil(t, err) {
+		assert.Equal(t, "secret-ca", string(c.signingCert))
+		assert.Equal(t, CertsDir, c.certDir)
+		b, _ := ioutil.ReadFile(filepath.Join(CertsDir, keyName))
+		assert.Equal(t, "secret-key", string(b))
+	}
+
+	// cert-manager wins over mounted files
+	t.Setenv(TLSSecretEnv, "")
+	t.Setenv(CertManagerCertificateEnv, "webhook-cert")
+	c, err = resolveCertContext(context.TODO(), client, "operator", "spinnaker-operator")
+	if assert.Nil(t, err) {
+		assert.Equal(t, "operator/webhook-cert", c.injectCAFrom)
+		assert.Nil(t, c.signingCert)
+	}
+
+	// mounted files, CA defaults to the certificate
+	t.Setenv(CertManagerCertificateEnv, "")
+	c, err = resolveCertContext(context.TODO(), client, "operator", "spinnaker-operator")
+	if assert.Nil(t, err) {
+		assert.Equal(t, mountDir, c.certDir)
+		assert.Equal(t, "mounted-crt", string(c.signingCert))
+	}
+
+	// self-signed when nothing is configured
+	t.Setenv(CertMountDirEnv, "")
+	c, err = resolveCertContext(context.TODO(), client, "operator", "spinnaker-operator")
+	if assert.Nil(t, err) {
+		assert.Equal(t, CertsDir, c.certDir)
+		assert.Contains(t, string(c.signingCert), "BEGIN CERTIFICATE")
+	}
+}
+
+func TestLoadFromSecretMissingKeys(t *testing.T) {
+	client := fake.NewSimpleClientset(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "webhook-tls", Namespace: "other"},
+		Data:       map[string][]byte{certName: []byte("crt")},
+	})
+	t.Setenv(TLSSecretEnv, "other/webhook-tls")
+	_, err := resolveCertContext(context.TODO(), client, "operator", "spinnaker-operator")
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "unable to load webhook certificates from external secret: secret other/webhook-tls must have tls.crt and tls.key keys", err.Error())
+	}
+}
+
+func TestLoadFromMountedFilesMissingKey(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, certName), "crt")
+	t.Setenv(CertMountDirEnv, dir)
+	c, err := loadFromMountedFiles(context.TODO(), nil, "operator", "spinnaker-operator")
+	assert.Nil(t, c)
+	assert.NotNil(t, err)
+}
+
+func writeFile(t *testing.T, path, content string) {
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,85 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// tlsSecretResync is how often the informer replays the TLS secret in case an update was missed
+const tlsSecretResync = 10 * time.Minute
+
+// watchTLSSecret reloads the webhook certificates when the given TLS secret changes, until the context is done.
+// The webhook server picks up the new files from the certs dir, the CA bundle of the webhook configuration is patched.
+func watchTLSSecret(ctx context.Context, c kubernetes.Interface, secretNs, name string, current *certContext) error {
+	factory := informers.NewSharedInformerFactoryWithOptions(c, tlsSecretResync,
+		informers.WithNamespace(secretNs),
+		informers.WithTweakListOptions(func(o *metav1.ListOptions) {
+			o.FieldSelector = fields.OneTermEqualSelector("metadata.name", name).String()
+		}))
+	r := &tlsSecretReloader{client: c, cert: current.cert, ca: current.signingCert}
+	informer := factory.Core().V1().Secrets().Informer()
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { r.reload(ctx, obj) },
+		UpdateFunc: func(_, obj interface{}) { r.reload(ctx, obj) },
+	})
+	factory.Start(ctx.Done())
+	<-ctx.Done()
+	return nil
+}
+
+// tlsSecretReloader applies the certificates of the TLS secret, skipping secrets whose certificates are unchanged
+type tlsSecretReloader struct {
+	client kubernetes.Interface
+	ca     []byte
+	cert   []byte
+}
+
+func (r *tlsSecretReloader) reload(ctx context.Context, obj interface{}) {
+	s, ok := obj.(*v1.Secret)
+	if !ok {
+		return
+	}
+	ca := s.Data[caName]
+	if len(ca) == 0 {
+		ca = s.Data[certName]
+	}
+	if bytes.Equal(r.cert, s.Data[certName]) && bytes.Equal(r.ca, ca) {
+		return
+	}
+	cc, err := writeSecretCerts(s)
+	if err != nil {
+		log.Error(err, "Unable to reload webhook certificates", "secret", s.Namespace+"/"+s.Name)
+		return
+	}
+	if err := patchCABundle(ctx, r.client, cc.signingCert); err != nil {
+		log.Error(err, "Unable to update the CA bundle of the webhook configuration")
+		return
+	}
+	r.cert, r.ca = cc.cert, cc.signingCert
+	recordCertificate(cc.cert)
+	certRotations.Inc()
+	log.Info("Reloaded webhook certificates", "secret", s.Namespace+"/"+s.Name)
+}
+
+// patchCABundle sets the CA bundle of every webhook of the validating webhook configuration
+func patchCABundle(ctx context.Context, c kubernetes.Interface, ca []byte) error {
+	configs := c.AdmissionregistrationV1().ValidatingWebhookConfigurations()
+	cfg, err := configs.Get(ctx, webhookConfigName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to get validating webhook configuration %s: %w", webhookConfigName, err)
+	}
+	for i := range cfg.Webhooks {
+		cfg.Webhooks[i].ClientConfig.CABundle = ca
+	}
+	_, err = configs.Update(ctx, cfg, metav1.UpdateOptions{})
+	return err
+}
@@ -0,0 +1,80 @@
+package webhook
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestWatchTLSSecretReloadsOnUpdate(t *testing.T) {
+	defer func(d string) { CertsDir = d }(CertsDir)
+	CertsDir = filepath.Join(t.TempDir(), "certs")
+
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "webhook-tls", Namespace: "operator"},
+		Data: map[string][]byte{
+			certName: []byte("crt-1"),
+			keyName:  []byte("key-1"),
+			caName:   []byte("ca-1"),
+		},
+	}
+	client := fake.NewSimpleClientset(secret, &admissionregistrationv1.ValidatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: webhookConfigName},
+		Webhooks: []admissionregistrationv1.ValidatingWebhook{
+			{Name: "webhook-spinnakerservices", ClientConfig: admissionregistrationv1.WebhookClientConfig{CABundle: []byte("ca-1")}},
+			{Name: "webhook-spinnakeraccounts", ClientConfig: admissionregistrationv1.WebhookClientConfig{CABundle: []byte("ca-1")}},
+		},
+	})
+	current, err := writeSecretCerts(secret)
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	done := make(chan struct{})
+	go func() {
+		_ = watchTLSSecret(ctx, client, "operator", "webhook-tls", current)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	rotated := secret.DeepCopy()
+	rotated.Data = map[string][]byte{
+		certName: []byte("crt-2"),
+		keyName:  []byte("key-2"),
+		caName:   []byte("ca-2"),
+	}
+	if _, err := client.CoreV1().Secrets("operator").Update(context.TODO(), rotated, metav1.UpdateOptions{}); !assert.Nil(t, err) {
+		return
+	}
+
+	err = wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		cfg, err := client.AdmissionregistrationV1().ValidatingWebhookConfigurations().Get(context.TODO(), webhookConfigName, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		for _, w := range cfg.Webhooks {
+			if string(w.ClientConfig.CABundle) != "ca-2" {
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+	assert.Nil(t, err)
+	b, _ := ioutil.ReadFile(filepath.Join(CertsDir, certName))
+	assert.Equal(t, "crt-2", string(b))
+	b, _ = ioutil.ReadFile(filepath.Join(CertsDir, keyName))
+	assert.Equal(t, "key-2", string(b))
+}
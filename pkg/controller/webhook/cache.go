@@ -0,0 +1,59 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	admissionv1 "k8s.io/api/admission/v1"
+)
+
+// CacheableAnnotation is the audit annotation handlers set to "true" on responses that only depend on the request,
+// and to "false" on responses of validations connecting to external endpoints.
+const CacheableAnnotation = "cacheable"
+
+// cacheHeaderHandler sets the Cache-Control header of admission responses from their cacheable annotation,
+// so that proxies in front of the webhook can coalesce identical requests. Responses without the annotation
+// are left untouched.
+type cacheHeaderHandler struct {
+	maxAge time.Duration
+	next   http.Handler
+}
+
+func (h *cacheHeaderHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b := &bufferedResponse{header: w.Header(), code: http.StatusOK}
+	h.next.ServeHTTP(b, r)
+
+	review := &admissionv1.AdmissionReview{}
+	if err := json.Unmarshal(b.body.Bytes(), review); err == nil && review.Response != nil {
+		switch review.Response.AuditAnnotations[CacheableAnnotation] {
+		case "true":
+			w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(h.maxAge/time.Second)))
+		case "false":
+			w.Header().Set("Cache-Control", "no-store")
+		}
+	}
+	w.WriteHeader(b.code)
+	_, _ = w.Write(b.body.Bytes())
+}
+
+// bufferedResponse holds the response until headers derived from its body are set
+type bufferedResponse struct {
+	header http.Header
+	code   int
+	body   bytes.Buffer
+}
+
+func (b *bufferedResponse) Header() http.Header {
+	return b.header
+}
+
+func (b *bufferedResponse) WriteHeader(code int) {
+	b.code = code
+}
+
+func (b *bufferedResponse) Write(p []byte) (int, error) {
+	return b.body.Write(p)
+}
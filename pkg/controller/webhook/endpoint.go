@@ -0,0 +1,77 @@
+package webhook
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+
+	"github.com/armory/spinnaker-operator/pkg/util"
+	apiAdmissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+)
+
+const (
+	// SkipServiceEnv disables the creation of the webhook service, the API server then calls the webhook at HostEnv
+	SkipServiceEnv = "WEBHOOK_SKIP_SERVICE"
+	// HostEnv is the host:port the API server reaches the webhook at when the service is skipped
+	HostEnv = "WEBHOOK_HOST"
+)
+
+// endpointSettings define how the API server reaches the webhook: through a service or at a fixed URL
+type endpointSettings struct {
+	skipService bool
+	host        string
+}
+
+func loadEndpointSettings() (endpointSettings, error) {
+	e := endpointSettings{host: os.Getenv(HostEnv)}
+	var err error
+	if e.skipService, err = util.BoolFromEnv(SkipServiceEnv, false); err != nil {
+		return e, err
+	}
+	if !e.skipService {
+		if e.host != "" {
+			return e, fmt.Errorf("%s can only be set when %s is true", HostEnv, SkipServiceEnv)
+		}
+		return e, nil
+	}
+	if e.host == "" {
+		return e, fmt.Errorf("%s is required when %s is true", HostEnv, SkipServiceEnv)
+	}
+	h, p, err := net.SplitHostPort(e.host)
+	if err != nil || h == "" {
+		return e, fmt.Errorf("invalid %s \"%s\": expected host:port", HostEnv, e.host)
+	}
+	if port, err := strconv.Atoi(p); err != nil || port < 1 || port > 65535 {
+		return e, fmt.Errorf("invalid %s \"%s\": port must be between 1 and 65535", HostEnv, e.host)
+	}
+	return e, nil
+}
+
+// clientConfig returns how the API server calls the webhook served at the given path
+func (e endpointSettings) clientConfig(ns, svcName, path string, caBundle []byte) apiAdmissionregistrationv1.WebhookClientConfig {
+	if e.skipService {
+		url := fmt.Sprintf("https://%s%s", e.host, path)
+		return apiAdmissionregistrationv1.WebhookClientConfig{
+			URL:      &url,
+			CABundle: caBundle,
+		}
+	}
+	return apiAdmissionregistrationv1.WebhookClientConfig{
+		Service: &apiAdmissionregistrationv1.ServiceReference{
+			Namespace: ns,
+			Name:      svcName,
+			Path:      &path,
+		},
+		CABundle: caBundle,
+	}
+}
+
+// serverNames returns the names the API server verifies the webhook certificate against
+func (e endpointSettings) serverNames(ns, svcName string) []string {
+	if e.skipService {
+		h, _, _ := net.SplitHostPort(e.host)
+		return []string{h}
+	}
+	return []string{svcName + "." + ns + ".svc"}
+}
@@ -0,0 +1,63 @@
+package webhook
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadEndpointSettings(t *testing.T) {
+	cases := []struct {
+		name        string
+		skipService string
+		host        string
+		err         string
+	}{
+		{"service mode", "", "", ""},
+		{"url mode", "true", "10.0.0.12:9876", ""},
+		{"url mode with hostname", "true", "operator.example.com:443", ""},
+		{"url mode without host", "true", "", "WEBHOOK_HOST is required when WEBHOOK_SKIP_SERVICE is true"},
+		{"host in service mode", "false", "10.0.0.12:9876", "WEBHOOK_HOST can only be set when WEBHOOK_SKIP_SERVICE is true"},
+		{"host without port", "true", "10.0.0.12", "invalid WEBHOOK_HOST \"10.0.0.12\": expected host:port"},
+		{"invalid port", "true", "10.0.0.12:99999", "invalid WEBHOOK_HOST \"10.0.0.12:99999\": port must be between 1 and 65535"},
+		{"invalid flag", "yes please", "", "invalid WEBHOOK_SKIP_SERVICE \"yes please\": expected true or false"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			t.Setenv(SkipServiceEnv, c.skipService)
+			t.Setenv(HostEnv, c.host)
+			_, err := loadEndpointSettings()
+			if c.err == "" {
+				assert.Nil(t, err)
+			} else if assert.NotNil(t, err) {
+				assert.Equal(t, c.err, err.Error())
+			}
+		})
+	}
+}
+
+func TestEndpointClientConfig(t *testing.T) {
+	ca := []byte("ca")
+
+	t.Run("url mode", func(t *testing.T) {
+		e := endpointSettings{skipService: true, host: "10.0.0.12:9876"}
+		c := e.clientConfig("operator", "spinnaker-operator", "/validate-spinnaker-io-v1alpha2-spinnakerservice", ca)
+		assert.Nil(t, c.Service)
+		if assert.NotNil(t, c.URL) {
+			assert.Equal(t, "https://10.0.0.12:9876/validate-spinnaker-io-v1alpha2-spinnakerservice", *c.URL)
+		}
+		assert.Equal(t, ca, c.CABundle)
+	})
+
+	t.Run("service mode", func(t *testing.T) {
+		e := endpointSettings{}
+		c := e.clientConfig("operator", "spinnaker-operator", "/validate-spinnaker-io-v1alpha2-spinnakerservice", ca)
+		assert.Nil(t, c.URL)
+		if assert.NotNil(t, c.Service) {
+			assert.Equal(t, "operator", c.Service.Namespace)
+			assert.Equal(t, "spinnaker-operator", c.Service.Name)
+			assert.Equal(t, "/validate-spinnaker-io-v1alpha2-spinnakerservice", *c.Service.Path)
+		}
+		assert.Equal(t, ca, c.CABundle)
+	})
+}
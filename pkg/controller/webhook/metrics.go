@@ -0,0 +1,56 @@
+package webhook
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	certutil "k8s.io/client-go/util/cert"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// certExpiry holds the expiry of the serving certificate in use
+var certExpiry struct {
+	mu       sync.RWMutex
+	notAfter time.Time
+}
+
+var (
+	certExpirySeconds = prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "spinnaker_operator_webhook_certificate_expiry_seconds",
+		Help: "Seconds until the webhook serving certificate expires, negative once expired",
+	}, func() float64 {
+		certExpiry.mu.RLock()
+		defer certExpiry.mu.RUnlock()
+		if certExpiry.notAfter.IsZero() {
+			return 0
+		}
+		return time.Until(certExpiry.notAfter).Seconds()
+	})
+	certRotations = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "spinnaker_operator_webhook_certificate_rotations_total",
+		Help: "Number of times the webhook certificates were reloaded after a rotation",
+	})
+)
+
+func init() {
+	metrics.Registry.MustRegister(certExpirySeconds, certRotations)
+}
+
+// recordCertificate updates the certificate expiry with the earliest expiry of the given PEM certificates
+func recordCertificate(pemData []byte) {
+	certs, err := certutil.ParseCertsPEM(pemData)
+	if err != nil || len(certs) == 0 {
+		log.Info("Unable to parse the webhook certificate, its expiry won't be reported")
+		return
+	}
+	notAfter := certs[0].NotAfter
+	for _, c := range certs[1:] {
+		if c.NotAfter.Before(notAfter) {
+			notAfter = c.NotAfter
+		}
+	}
+	certExpiry.mu.Lock()
+	defer certExpiry.mu.Unlock()
+	certExpiry.notAfter = notAfter
+}
@@ -0,0 +1,96 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+const (
+	// NameTemplateEnv overrides the template of the webhook names
+	NameTemplateEnv = "WEBHOOK_NAME_TEMPLATE"
+	// DefaultNameTemplate gives names such as webhook-spinnakerservices-v1alpha2.spinnaker.io
+	DefaultNameTemplate = "webhook-{{.Resource}}-{{.Version}}.{{.Group}}"
+
+	hashLength = 8
+)
+
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9.-]+`)
+
+// nameParams are the fields available to the webhook name template
+type nameParams struct {
+	Resource string
+	Group    string
+	Version  string
+	Kind     string
+}
+
+// WebhookName renders the name of the webhook validating the given resource. Names are lowercased, invalid
+// characters replaced and labels exceeding 63 characters or names exceeding 253 characters truncated with a hash
+// of the untruncated value so that truncated names remain unique.
+func WebhookName(tmpl, resource string, gvk schema.GroupVersionKind) (string, error) {
+	t, err := template.New("webhook").Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("invalid webhook name template %q: %w", tmpl, err)
+	}
+	b := &bytes.Buffer{}
+	if err := t.Execute(b, nameParams{Resource: resource, Group: gvk.Group, Version: gvk.Version, Kind: gvk.Kind}); err != nil {
+		return "", fmt.Errorf("invalid webhook name template %q: %w", tmpl, err)
+	}
+
+	labels := strings.Split(invalidNameChars.ReplaceAllString(strings.ToLower(b.String()), "-"), ".")
+	for i := range labels {
+		labels[i] = truncate(strings.Trim(labels[i], "-"), validation.DNS1123LabelMaxLength)
+	}
+	name := strings.Join(labels, ".")
+	if len(name) > validation.DNS1123SubdomainMaxLength {
+		name = shorten(name, labels)
+	}
+
+	if errs := validation.IsFullyQualifiedName(field.NewPath("name"), name); len(errs) > 0 {
+		return "", fmt.Errorf("invalid webhook name: %s", errs.ToAggregate().Error())
+	}
+	return name, nil
+}
+
+// shorten keeps the end of the name, dropping leading group labels if needed, and shortens the first label
+// with a hash of the whole name
+func shorten(name string, labels []string) string {
+	const minFirstLabel = 2 * hashLength
+	rest := labels[1:]
+	for len(rest) > 2 && len(strings.Join(rest, "."))+1+minFirstLabel > validation.DNS1123SubdomainMaxLength {
+		rest = rest[1:]
+	}
+	suffix := strings.Join(rest, ".")
+	avail := validation.DNS1123SubdomainMaxLength - len(suffix) - 1
+	if avail > validation.DNS1123LabelMaxLength {
+		avail = validation.DNS1123LabelMaxLength
+	}
+	if avail <= hashLength+1 {
+		return name
+	}
+	h := sha256.Sum256([]byte(name))
+	prefix := labels[0]
+	if len(prefix) > avail-hashLength-1 {
+		prefix = prefix[:avail-hashLength-1]
+	}
+	return strings.TrimRight(prefix, "-") + "-" + hex.EncodeToString(h[:])[:hashLength] + "." + suffix
+}
+
+// truncate shortens s to max characters, replacing the end with a hash of s
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	h := sha256.Sum256([]byte(s))
+	prefix := strings.TrimRight(s[:max-hashLength-1], "-")
+	return prefix + "-" + hex.EncodeToString(h[:])[:hashLength]
+}
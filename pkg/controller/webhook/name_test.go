@@ -0,0 +1,55 @@
+package webhook
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestWebhookName(t *testing.T) {
+	gvk := schema.GroupVersionKind{Group: "spinnaker.io", Version: "v1alpha2", Kind: "SpinnakerService"}
+
+	n, err := WebhookName(DefaultNameTemplate, "spinnakerservices", gvk)
+	assert.Nil(t, err)
+	assert.Equal(t, "webhook-spinnakerservices-v1alpha2.spinnaker.io", n)
+
+	n, err = WebhookName("{{.Kind}}_{{.Version}}.validate.{{.Group}}", "spinnakerservices", gvk)
+	assert.Nil(t, err)
+	assert.Equal(t, "spinnakerservice-v1alpha2.validate.spinnaker.io", n)
+
+	_, err = WebhookName("{{.Missing}}", "spinnakerservices", gvk)
+	assert.NotNil(t, err)
+	_, err = WebhookName("{{.Resource}}", "spinnakerservices", gvk)
+	assert.NotNil(t, err, "names must be fully qualified")
+}
+
+func TestWebhookNameLongGroup(t *testing.T) {
+	group := strings.Repeat("verylonggroupname", 5) + "." + strings.Repeat("subdomain.", 20) + "example.com"
+	resources := []string{
+		strings.Repeat("spinnakeraccount", 4) + "s",
+		strings.Repeat("spinnakeraccount", 4) + "groups",
+	}
+
+	names := map[string]bool{}
+	for _, r := range resources {
+		gvk := schema.GroupVersionKind{Group: group, Version: "v1alpha2"}
+		n, err := WebhookName(DefaultNameTemplate, r, gvk)
+		if !assert.Nil(t, err) {
+			return
+		}
+		assert.LessOrEqual(t, len(n), validation.DNS1123SubdomainMaxLength)
+		assert.Empty(t, validation.IsFullyQualifiedName(field.NewPath("name"), n))
+		for _, l := range strings.Split(n, ".") {
+			assert.LessOrEqual(t, len(l), validation.DNS1123LabelMaxLength)
+		}
+		// Truncation is deterministic
+		again, _ := WebhookName(DefaultNameTemplate, r, gvk)
+		assert.Equal(t, n, again)
+		names[n] = true
+	}
+	assert.Len(t, names, len(resources), "truncated names must remain unique")
+}
@@ -0,0 +1,42 @@
+package webhook
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	apiAdmissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// FailurePolicyEnv sets the failure policy of the webhooks registered without one, Fail by default
+const FailurePolicyEnv = "WEBHOOK_FAILURE_POLICY"
+
+// RegisterWithFailurePolicy registers a handler whose webhook uses the given failure policy regardless of
+// FailurePolicyEnv
+func RegisterWithFailurePolicy(kind schema.GroupVersionKind, resources string, h admission.Handler, policy apiAdmissionregistrationv1.FailurePolicyType) {
+	Register(kind, resources, h)
+	registrations[len(registrations)-1].failurePolicy = &policy
+}
+
+// failurePolicyFromEnv returns the failure policy of webhooks registered without one
+func failurePolicyFromEnv() (apiAdmissionregistrationv1.FailurePolicyType, error) {
+	v := os.Getenv(FailurePolicyEnv)
+	switch {
+	case v == "", strings.EqualFold(v, string(apiAdmissionregistrationv1.Fail)):
+		return apiAdmissionregistrationv1.Fail, nil
+	case strings.EqualFold(v, string(apiAdmissionregistrationv1.Ignore)):
+		return apiAdmissionregistrationv1.Ignore, nil
+	}
+	return "", fmt.Errorf("invalid %s \"%s\": expected %s or %s", FailurePolicyEnv, v, apiAdmissionregistrationv1.Ignore, apiAdmissionregistrationv1.Fail)
+}
+
+// policy returns the registration's failure policy, or def if it has none
+func (r registration) policy(def apiAdmissionregistrationv1.FailurePolicyType) *apiAdmissionregistrationv1.FailurePolicyType {
+	if r.failurePolicy != nil {
+		p := *r.failurePolicy
+		return &p
+	}
+	return &def
+}
@@ -0,0 +1,43 @@
+package webhook
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apiAdmissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestFailurePolicyFromEnv(t *testing.T) {
+	p, err := failurePolicyFromEnv()
+	assert.Nil(t, err)
+	assert.Equal(t, apiAdmissionregistrationv1.Fail, p)
+
+	t.Setenv(FailurePolicyEnv, "ignore")
+	p, err = failurePolicyFromEnv()
+	assert.Nil(t, err)
+	assert.Equal(t, apiAdmissionregistrationv1.Ignore, p)
+
+	t.Setenv(FailurePolicyEnv, "Retry")
+	_, err = failurePolicyFromEnv()
+	assert.NotNil(t, err)
+}
+
+func TestValidatingWebhookConfigurationFailurePolicy(t *testing.T) {
+	saved := registrations
+	defer func() { registrations = saved }()
+	registrations = []registration{}
+	Register(schema.GroupVersionKind{Group: "spinnaker.io", Version: "v1alpha2", Kind: "SpinnakerService"}, "spinnakerservices", nil)
+	RegisterWithFailurePolicy(schema.GroupVersionKind{Group: "spinnaker.io", Version: "v1alpha2", Kind: "SpinnakerAccount"}, "spinnakeraccounts", nil, apiAdmissionregistrationv1.Fail)
+
+	t.Setenv(FailurePolicyEnv, "Ignore")
+	policy, err := failurePolicyFromEnv()
+	if !assert.Nil(t, err) {
+		return
+	}
+	cfg, err := validatingWebhookConfiguration("spinnaker-operator", "operator", &certContext{}, endpointSettings{}, policy)
+	if assert.Nil(t, err) && assert.Len(t, cfg.Webhooks, 2) {
+		assert.Equal(t, apiAdmissionregistrationv1.Ignore, *cfg.Webhooks[0].FailurePolicy, "env default applies")
+		assert.Equal(t, apiAdmissionregistrationv1.Fail, *cfg.Webhooks[1].FailurePolicy, "registration overrides the env default")
+	}
+}
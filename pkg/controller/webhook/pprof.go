@@ -0,0 +1,63 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/pprof"
+	"time"
+
+	"github.com/armory/spinnaker-operator/pkg/util"
+)
+
+const (
+	// PprofEnv enables the pprof handlers, they're served without TLS on PprofPortEnv
+	PprofEnv = "ENABLE_PPROF"
+	// PprofPortEnv is the port of the pprof handlers, they're only reachable from the pod, e.g. with kubectl port-forward
+	PprofPortEnv = "PPROF_PORT"
+
+	defaultPprofPort = 6060
+)
+
+// pprofServer returns the server of the pprof handlers, or nil if profiling isn't enabled
+func pprofServer() (*http.Server, error) {
+	enabled, err := util.BoolFromEnv(PprofEnv, false)
+	if err != nil || !enabled {
+		return nil, err
+	}
+	port, err := util.IntFromEnv(PprofPortEnv, defaultPprofPort)
+	if err != nil {
+		return nil, err
+	}
+	if port == servicePort {
+		return nil, fmt.Errorf("invalid %s %d: the port is used by the webhook server", PprofPortEnv, port)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	return &http.Server{Addr: fmt.Sprintf("127.0.0.1:%d", port), Handler: mux}, nil
+}
+
+// servePprof serves the pprof handlers until the context is done
+func servePprof(ctx context.Context, srv *http.Server) error {
+	errs := make(chan error, 1)
+	go func() {
+		log.Info("Serving pprof handlers", "address", srv.Addr)
+		errs <- srv.ListenAndServe()
+	}()
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	}
+}
@@ -0,0 +1,53 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPprofServer(t *testing.T) {
+	srv, err := pprofServer()
+	assert.Nil(t, err)
+	assert.Nil(t, srv, "disabled by default")
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.Nil(t, err) {
+		return
+	}
+	port := l.Addr().(*net.TCPAddr).Port
+	l.Close()
+	t.Setenv(PprofEnv, "true")
+	t.Setenv(PprofPortEnv, fmt.Sprint(port))
+	srv, err = pprofServer()
+	if !assert.Nil(t, err) || !assert.NotNil(t, srv) {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	done := make(chan error)
+	go func() { done <- servePprof(ctx, srv) }()
+	url := fmt.Sprintf("http://127.0.0.1:%d/debug/pprof/", port)
+	assert.Eventually(t, func() bool {
+		resp, err := http.Get(url)
+		if err != nil {
+			return false
+		}
+		resp.Body.Close()
+		return resp.StatusCode == http.StatusOK
+	}, 5*time.Second, 50*time.Millisecond)
+
+	cancel()
+	assert.Nil(t, <-done)
+	_, err = http.Get(url)
+	assert.NotNil(t, err, "stopped with the manager")
+
+	t.Setenv(PprofPortEnv, fmt.Sprint(servicePort))
+	_, err = pprofServer()
+	assert.NotNil(t, err)
+}
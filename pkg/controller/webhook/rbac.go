@@ -0,0 +1,80 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// managedVerbs are the verbs used to create or update the webhook objects, which the operator never deletes
+var managedVerbs = []string{"get", "create", "update"}
+
+// tlsSecretVerbs are the verbs used to load and watch the TLS secret
+var tlsSecretVerbs = []string{"get", "list", "watch"}
+
+// permission is an action the operator performs while setting up the webhook
+type permission struct {
+	verb      string
+	group     string
+	resource  string
+	namespace string
+}
+
+func (p permission) String() string {
+	if p.namespace == "" {
+		return fmt.Sprintf("%s %s (cluster scoped)", p.verb, p.resource)
+	}
+	return fmt.Sprintf("%s %s in namespace %s", p.verb, p.resource, p.namespace)
+}
+
+// requiredPermissions returns the permissions needed to manage the webhook service and configuration, and to load
+// the TLS secret ("name" or "namespace/name") when certificates come from one
+func requiredPermissions(ns string, endpoint endpointSettings, tlsSecret string) []permission {
+	perms := make([]permission, 0)
+	for _, v := range managedVerbs {
+		if !endpoint.skipService {
+			perms = append(perms, permission{verb: v, resource: "services", namespace: ns})
+		}
+		perms = append(perms, permission{verb: v, group: "admissionregistration.k8s.io", resource: "validatingwebhookconfigurations"})
+	}
+	if tlsSecret != "" {
+		secretNs, _ := splitNamespacedName(tlsSecret, ns)
+		for _, v := range tlsSecretVerbs {
+			perms = append(perms, permission{verb: v, resource: "secrets", namespace: secretNs})
+		}
+	}
+	return perms
+}
+
+// checkPermissions reviews the permissions with SelfSubjectAccessReviews and returns a single error listing all
+// permissions the operator is missing.
+func checkPermissions(ctx context.Context, c kubernetes.Interface, perms []permission) error {
+	missing := make([]string, 0)
+	for _, p := range perms {
+		review := &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Verb:      p.verb,
+					Group:     p.group,
+					Resource:  p.resource,
+					Namespace: p.namespace,
+				},
+			},
+		}
+		res, err := c.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("unable to review operator permission to %s: %w", p, err)
+		}
+		if !res.Status.Allowed {
+			missing = append(missing, p.String())
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("the operator is missing permissions required to set up the validating webhook, grant them in its Role or ClusterRole:\n  - %s", strings.Join(missing, "\n  - "))
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package webhook
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// newFakeAuthorizer returns a client allowing every action but the denied ones, keyed by verb and resource
+func newFakeAuthorizer(denied ...string) *fake.Clientset {
+	c := fake.NewSimpleClientset()
+	c.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		attrs := review.Spec.ResourceAttributes
+		review.Status.Allowed = true
+		for _, d := range denied {
+			if d == attrs.Verb+" "+attrs.Resource {
+				review.Status.Allowed = false
+			}
+		}
+		return true, review, nil
+	})
+	return c
+}
+
+func TestCheckPermissions(t *testing.T) {
+	perms := requiredPermissions("operator", endpointSettings{}, "")
+	assert.Len(t, perms, 6)
+	assert.Nil(t, checkPermissions(context.TODO(), newFakeAuthorizer(), perms))
+
+	perms = requiredPermissions("operator", endpointSettings{}, "certs/webhook-tls")
+	assert.Len(t, perms, 9)
+	err := checkPermissions(context.TODO(), newFakeAuthorizer("create validatingwebhookconfigurations", "watch secrets"), perms)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, `the operator is missing permissions required to set up the validating webhook, grant them in its Role or ClusterRole:
+  - create validatingwebhookconfigurations (cluster scoped)
+  - watch secrets in namespace certs`, err.Error())
+	}
+}
+
+func TestRequiredPermissionsWithoutService(t *testing.T) {
+	for _, p := range requiredPermissions("operator", endpointSettings{skipService: true, host: "webhook.example.com:443"}, "") {
+		assert.NotEqual(t, "services", p.resource)
+	}
+}
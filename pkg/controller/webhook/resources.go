@@ -0,0 +1,42 @@
+package webhook
+
+import (
+	"fmt"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/discovery"
+)
+
+// servedRegistrations returns the registrations whose kind the API server serves, skipping the others with a
+// warning, e.g. SpinnakerAccountGroup when its CRD isn't installed. Resources must be the plural the API server
+// serves their kind as, otherwise the API server would never call the webhook.
+func servedRegistrations(d discovery.DiscoveryInterface) ([]registration, error) {
+	var served []registration
+	for _, r := range registrations {
+		gv := r.kind.GroupVersion().String()
+		plural := ""
+		list, err := d.ServerResourcesForGroupVersion(gv)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("unable to discover the resources of %s: %w", gv, err)
+		}
+		if list != nil {
+			for _, res := range list.APIResources {
+				// subresources such as spinnakerservices/status share the kind of their resource
+				if res.Kind == r.kind.Kind && !strings.Contains(res.Name, "/") {
+					plural = res.Name
+					break
+				}
+			}
+		}
+		if plural == "" {
+			log.Info("Skipping the webhook of a kind not served by the API server, check its CRD is installed", "kind", r.kind.String())
+			continue
+		}
+		if plural != r.r {
+			return nil, fmt.Errorf("webhook for kind %s is registered for resource \"%s\" but the API server serves it as \"%s\"", r.kind, r.r, plural)
+		}
+		served = append(served, r)
+	}
+	return served, nil
+}
@@ -0,0 +1,51 @@
+package webhook
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestCheckResources(t *testing.T) {
+	saved := registrations
+	defer func() { registrations = saved }()
+
+	c := fake.NewSimpleClientset()
+	c.Resources = []*metav1.APIResourceList{{
+		GroupVersion: "spinnaker.io/v1alpha2",
+		APIResources: []metav1.APIResource{
+			{Name: "spinnakerservices/status", Kind: "SpinnakerService"},
+			{Name: "spinnakerservices", Kind: "SpinnakerService"},
+		},
+	}}
+	gvk := schema.GroupVersionKind{Group: "spinnaker.io", Version: "v1alpha2", Kind: "SpinnakerService"}
+
+	registrations = []registration{}
+	Register(gvk, "spinnakerservices", nil)
+	served, err := servedRegistrations(c.Discovery())
+	if assert.Nil(t, err) && assert.Len(t, served, 1) {
+		assert.Equal(t, gvk, served[0].kind)
+	}
+
+	registrations = []registration{}
+	Register(gvk, "spinnakerservice", nil)
+	_, err = servedRegistrations(c.Discovery())
+	assert.EqualError(t, err, `webhook for kind spinnaker.io/v1alpha2, Kind=SpinnakerService is registered for resource "spinnakerservice" but the API server serves it as "spinnakerservices"`)
+
+	registrations = []registration{}
+	Register(gvk, "spinnakerservices", nil)
+	Register(schema.GroupVersionKind{Group: "spinnaker.io", Version: "v1alpha2", Kind: "SpinnakerAccountGroup"}, "spinnakeraccountgroups", nil)
+	served, err = servedRegistrations(c.Discovery())
+	if assert.Nil(t, err) && assert.Len(t, served, 1) {
+		assert.Equal(t, gvk, served[0].kind)
+	}
+
+	registrations = []registration{}
+	Register(schema.GroupVersionKind{Group: "spinnaker.io", Version: "v1alpha2", Kind: "SpinnakerAccount"}, "spinnakeraccounts", nil)
+	served, err = servedRegistrations(c.Discovery())
+	assert.Nil(t, err)
+	assert.Empty(t, served)
+}
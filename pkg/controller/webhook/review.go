@@ -0,0 +1,62 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// admissionReviewVersions are the AdmissionReview versions advertised to the API server
+var admissionReviewVersions = []string{"v1"}
+
+// reviewVersionHandler rejects requests that aren't AdmissionReviews of an advertised version. controller-runtime
+// decodes all the review versions it knows into the same request, so handlers can't tell them apart.
+type reviewVersionHandler struct {
+	next http.Handler
+}
+
+func (h *reviewVersionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Body == nil {
+		h.next.ServeHTTP(w, r)
+		return
+	}
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("unable to read admission request: %v", err), readErrorStatus(err))
+		return
+	}
+	// empty requests are answered by controller-runtime
+	if len(body) > 0 {
+		if err := checkReviewVersion(body); err != nil {
+			log.Info("Rejecting admission request", "error", err.Error())
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	h.next.ServeHTTP(w, r)
+}
+
+func checkReviewVersion(body []byte) error {
+	tm := metav1.TypeMeta{}
+	if err := json.Unmarshal(body, &tm); err != nil {
+		return fmt.Errorf("unable to decode admission review: %w", err)
+	}
+	gv, err := schema.ParseGroupVersion(tm.APIVersion)
+	if err == nil && gv.Group == admissionv1.GroupName && tm.Kind == "AdmissionReview" {
+		for _, v := range admissionReviewVersions {
+			if gv.Version == v {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("unexpected admission review apiVersion %q and kind %q, expected kind AdmissionReview with apiVersion %s/%s",
+		tm.APIVersion, tm.Kind, admissionv1.GroupName, strings.Join(admissionReviewVersions, ", "+admissionv1.GroupName+"/"))
+}
@@ -0,0 +1,49 @@
+package webhook
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReviewVersionHandler(t *testing.T) {
+	var handled string
+	h := &reviewVersionHandler{next: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		handled = string(b)
+	})}
+
+	t.Run("advertised version", func(t *testing.T) {
+		body := `{"apiVersion":"admission.k8s.io/v1","kind":"AdmissionReview","request":{}}`
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/validate", strings.NewReader(body)))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, body, handled)
+	})
+
+	t.Run("unexpected version", func(t *testing.T) {
+		handled = ""
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/validate", strings.NewReader(`{"apiVersion":"admission.k8s.io/v1beta1","kind":"AdmissionReview"}`)))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), `unexpected admission review apiVersion "admission.k8s.io/v1beta1" and kind "AdmissionReview", expected kind AdmissionReview with apiVersion admission.k8s.io/v1`)
+		assert.Empty(t, handled)
+	})
+
+	t.Run("unexpected kind", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/validate", strings.NewReader(`{"apiVersion":"v1","kind":"Pod"}`)))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("malformed body", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/validate", strings.NewReader(`not json`)))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "unable to decode admission review")
+	})
+}
@@ -0,0 +1,221 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/armory/spinnaker-operator/pkg/util"
+	"github.com/go-logr/logr"
+	"sigs.k8s.io/controller-runtime/pkg/certwatcher"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+const (
+	maxConcurrentRequestsEnv = "WEBHOOK_MAX_CONCURRENT_REQUESTS"
+	readTimeoutEnv           = "WEBHOOK_READ_TIMEOUT"
+	writeTimeoutEnv          = "WEBHOOK_WRITE_TIMEOUT"
+	cacheMaxAgeEnv           = "WEBHOOK_CACHE_MAX_AGE"
+	maxRequestBodySizeEnv    = "WEBHOOK_MAX_REQUEST_BODY_SIZE"
+
+	defaultMaxConcurrentRequests = 32
+	defaultReadTimeout           = 10 * time.Second
+	defaultWriteTimeout          = 30 * time.Second
+	// defaultMaxRequestBodySize fits the object and old object of an update, each up to etcd's default limit of 1.5MiB
+	defaultMaxRequestBodySize = 3*1024*1024 + 64*1024
+)
+
+// serverSettings tunes how admission requests are served by the webhook server. The read timeout is applied to
+// connections, other settings by wrapping each handler.
+type serverSettings struct {
+	maxConcurrentRequests int
+	// readTimeout bounds the time spent reading a request from its connection
+	readTimeout time.Duration
+	// writeTimeout bounds the time spent handling the request before a response is written
+	writeTimeout time.Duration
+	// cacheMaxAge is how long proxies may cache cacheable responses, no caching headers are set if zero
+	cacheMaxAge time.Duration
+	// maxRequestBodySize is the maximum size in bytes of admission requests, their size isn't limited if zero
+	maxRequestBodySize int
+}
+
+func loadServerSettings() (serverSettings, error) {
+	s := serverSettings{}
+	var err error
+	if s.maxConcurrentRequests, err = util.IntFromEnv(maxConcurrentRequestsEnv, defaultMaxConcurrentRequests); err != nil {
+		return s, err
+	}
+	if s.readTimeout, err = util.DurationFromEnv(readTimeoutEnv, defaultReadTimeout); err != nil {
+		return s, err
+	}
+	if s.writeTimeout, err = util.DurationFromEnv(writeTimeoutEnv, defaultWriteTimeout); err != nil {
+		return s, err
+	}
+	if s.cacheMaxAge, err = util.NonNegativeDurationFromEnv(cacheMaxAgeEnv, 0); err != nil {
+		return s, err
+	}
+	if s.maxRequestBodySize, err = util.NonNegativeIntFromEnv(maxRequestBodySizeEnv, defaultMaxRequestBodySize); err != nil {
+		return s, err
+	}
+	return s, nil
+}
+
+// wrap applies the concurrency limit, write timeout, body size limit, review version check and caching headers to the
+// given handler
+func (s serverSettings) wrap(h http.Handler) http.Handler {
+	var next http.Handler = h
+	if s.cacheMaxAge > 0 {
+		next = &cacheHeaderHandler{maxAge: s.cacheMaxAge, next: h}
+	}
+	next = &reviewVersionHandler{next: next}
+	if s.maxRequestBodySize > 0 {
+		next = &bodyLimitHandler{max: int64(s.maxRequestBodySize), next: next}
+	}
+	return &limitedHandler{
+		Handler: http.TimeoutHandler(&concurrencyLimiter{
+			slots: make(chan struct{}, s.maxConcurrentRequests),
+			next:  next,
+		}, s.writeTimeout, "admission request timed out"),
+		next: h,
+	}
+}
+
+// limitedHandler forwards dependency injection to the wrapped webhook
+type limitedHandler struct {
+	http.Handler
+	next http.Handler
+}
+
+var _ inject.Injector = &limitedHandler{}
+var _ inject.Logger = &limitedHandler{}
+
+func (l *limitedHandler) InjectFunc(f inject.Func) error {
+	return f(l.next)
+}
+
+func (l *limitedHandler) InjectLogger(lg logr.Logger) error {
+	_, err := inject.LoggerInto(lg, l.next)
+	return err
+}
+
+type concurrencyLimiter struct {
+	slots chan struct{}
+	next  http.Handler
+}
+
+func (c *concurrencyLimiter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	select {
+	case c.slots <- struct{}{}:
+		defer func() { <-c.slots }()
+		c.next.ServeHTTP(w, r)
+	case <-r.Context().Done():
+		http.Error(w, "too many concurrent admission requests", http.StatusServiceUnavailable)
+	}
+}
+
+// webhookServer serves the webhooks registered with the controller-runtime server, which doesn't expose its
+// http.Server, on a server whose connections time out while reading requests
+type webhookServer struct {
+	*webhook.Server
+	settings serverSettings
+}
+
+// Start serves the registered webhooks over TLS until the context is done, reloading the certificates when they
+// change in the certs dir
+func (s *webhookServer) Start(ctx context.Context) error {
+	certName, keyName := s.CertName, s.KeyName
+	if certName == "" {
+		certName, keyName = "tls.crt", "tls.key"
+	}
+	watcher, err := certwatcher.New(filepath.Join(s.CertDir, certName), filepath.Join(s.CertDir, keyName))
+	if err != nil {
+		return err
+	}
+	go func() {
+		if err := watcher.Start(ctx); err != nil {
+			log.Error(err, "Unable to watch webhook certificates")
+		}
+	}()
+	listener, err := tls.Listen("tcp", net.JoinHostPort(s.Host, strconv.Itoa(s.Port)), &tls.Config{
+		NextProtos:     []string{"h2"},
+		GetCertificate: watcher.GetCertificate,
+	})
+	if err != nil {
+		return err
+	}
+	log.Info("Serving webhooks", "port", s.Port)
+	srv := s.settings.httpServer(s.WebhookMux)
+	go func() {
+		<-ctx.Done()
+		if err := srv.Shutdown(context.Background()); err != nil {
+			log.Error(err, "Unable to shut down the webhook server")
+		}
+	}()
+	if err := srv.Serve(listener); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// httpServer returns the server of the given handler, timing out connections whose request isn't read within the
+// read timeout
+func (s serverSettings) httpServer(h http.Handler) *http.Server {
+	return &http.Server{
+		Handler:           h,
+		MaxHeaderBytes:    1 << 20,
+		IdleTimeout:       90 * time.Second,
+		ReadHeaderTimeout: s.readTimeout,
+		ReadTimeout:       s.readTimeout,
+	}
+}
+
+// readErrorStatus returns the status answering a request whose body can't be read
+func readErrorStatus(err error) int {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return http.StatusRequestTimeout
+	}
+	return http.StatusBadRequest
+}
+
+// errBodyTooLarge is returned when reading past the maximum request body size
+var errBodyTooLarge = errors.New("admission request body too large")
+
+// bodyLimitHandler reads the request body, rejecting requests larger than max bytes before they're read in full.
+// Handlers down the chain read the buffered body, so they never see the limit being exceeded.
+type bodyLimitHandler struct {
+	max  int64
+	next http.Handler
+}
+
+func (h *bodyLimitHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.ContentLength > h.max {
+		http.Error(w, fmt.Sprintf("%s: %d bytes, the maximum is %d (%s)", errBodyTooLarge, r.ContentLength, h.max, maxRequestBodySizeEnv), http.StatusRequestEntityTooLarge)
+		return
+	}
+	if r.Body == nil {
+		h.next.ServeHTTP(w, r)
+		return
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, h.max+1))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("unable to read admission request: %v", err), readErrorStatus(err))
+		return
+	}
+	if int64(len(body)) > h.max {
+		http.Error(w, fmt.Sprintf("%s: the maximum is %d bytes (%s)", errBodyTooLarge, h.max, maxRequestBodySizeEnv), http.StatusRequestEntityTooLarge)
+		return
+	}
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	h.next.ServeHTTP(w, r)
+}
@@ -0,0 +1,192 @@
+package webhook
+
+import (
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadServerSettings(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		s, err := loadServerSettings()
+		assert.Nil(t, err)
+		assert.Equal(t, defaultMaxConcurrentRequests, s.maxConcurrentRequests)
+		assert.Equal(t, defaultReadTimeout, s.readTimeout)
+		assert.Equal(t, defaultWriteTimeout, s.writeTimeout)
+		assert.Equal(t, defaultMaxRequestBodySize, s.maxRequestBodySize)
+	})
+
+	t.Run("configured", func(t *testing.T) {
+		t.Setenv(maxConcurrentRequestsEnv, "4")
+		t.Setenv(readTimeoutEnv, "2s")
+		t.Setenv(writeTimeoutEnv, "5s")
+		s, err := loadServerSettings()
+		assert.Nil(t, err)
+		assert.Equal(t, 4, s.maxConcurrentRequests)
+		assert.Equal(t, 2*time.Second, s.readTimeout)
+		assert.Equal(t, 5*time.Second, s.writeTimeout)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		t.Setenv(cacheMaxAgeEnv, "0")
+		t.Setenv(maxRequestBodySizeEnv, "0")
+		s, err := loadServerSettings()
+		assert.Nil(t, err)
+		assert.Zero(t, s.cacheMaxAge)
+		assert.Zero(t, s.maxRequestBodySize)
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		for env, v := range map[string]string{
+			maxConcurrentRequestsEnv: "0",
+			readTimeoutEnv:           "ten",
+			writeTimeoutEnv:          "-1s",
+		} {
+			t.Run(env, func(t *testing.T) {
+				t.Setenv(env, v)
+				_, err := loadServerSettings()
+				if assert.NotNil(t, err) {
+					assert.Contains(t, err.Error(), env)
+				}
+			})
+		}
+	})
+}
+
+func TestServerSettingsWriteTimeout(t *testing.T) {
+	s := serverSettings{maxConcurrentRequests: 1, readTimeout: time.Second, writeTimeout: 20 * time.Millisecond}
+	h := s.wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/validate", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Contains(t, rec.Body.String(), "admission request timed out")
+}
+
+func TestServerSettingsReadTimeout(t *testing.T) {
+	s := serverSettings{readTimeout: 50 * time.Millisecond}
+	readErr := make(chan error, 1)
+	srv := httptest.NewUnstartedServer(nil)
+	srv.Config = s.httpServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := ioutil.ReadAll(r.Body)
+		readErr <- err
+	}))
+	srv.Start()
+	defer srv.Close()
+
+	conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer conn.Close()
+	// the body is never sent in full
+	_, err = io.WriteString(conn, "POST /validate HTTP/1.1\r\nHost: webhook\r\nContent-Length: 100\r\n\r\n{")
+	assert.Nil(t, err)
+	select {
+	case err := <-readErr:
+		assert.Equal(t, http.StatusRequestTimeout, readErrorStatus(err))
+	case <-time.After(5 * time.Second):
+		t.Error("request body read didn't time out")
+	}
+}
+
+// slowReader takes a millisecond for each read
+type slowReader struct {
+	io.Reader
+}
+
+func (s *slowReader) Read(p []byte) (int, error) {
+	time.Sleep(time.Millisecond)
+	return s.Reader.Read(p)
+}
+
+func TestServerSettingsMaxRequestBodySize(t *testing.T) {
+	review := `{"apiVersion":"admission.k8s.io/v1","kind":"AdmissionReview","request":{"object":{"data":"` + strings.Repeat("x", 100) + `"}}}`
+	s := serverSettings{maxConcurrentRequests: 1, readTimeout: time.Second, writeTimeout: time.Second, maxRequestBodySize: 64}
+	h := s.wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request should not be handled")
+	}))
+
+	t.Run("declared length", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/validate", strings.NewReader(review)))
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+		assert.Contains(t, rec.Body.String(), "admission request body too large: 194 bytes, the maximum is 64 (WEBHOOK_MAX_REQUEST_BODY_SIZE)")
+	})
+
+	t.Run("unknown length", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/validate", &slowReader{strings.NewReader(review)}))
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+		assert.Contains(t, rec.Body.String(), "admission request body too large: the maximum is 64 bytes (WEBHOOK_MAX_REQUEST_BODY_SIZE)")
+	})
+
+	t.Run("under the limit", func(t *testing.T) {
+		s.maxRequestBodySize = len(review)
+		handled := false
+		h := s.wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			handled = true
+		}))
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/validate", &slowReader{strings.NewReader(review)}))
+		assert.True(t, handled)
+	})
+}
+
+func TestServerSettingsConcurrency(t *testing.T) {
+	s := serverSettings{maxConcurrentRequests: 1, readTimeout: time.Second, writeTimeout: 50 * time.Millisecond}
+	release := make(chan struct{})
+	started := make(chan struct{})
+	h := s.wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	}))
+
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/validate", nil))
+	}()
+	<-started
+
+	// The only slot is taken, the second request can't be served before timing out
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/validate", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	close(release)
+	wg.Wait()
+}
+
+func TestServerSettingsCacheHeaders(t *testing.T) {
+	s := serverSettings{maxConcurrentRequests: 1, readTimeout: time.Second, writeTimeout: time.Second, cacheMaxAge: time.Minute}
+	serve := func(body string) *httptest.ResponseRecorder {
+		h := s.wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(body))
+		}))
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/validate", nil))
+		return rec
+	}
+
+	rec := serve(`{"response":{"allowed":true,"auditAnnotations":{"cacheable":"true"}}}`)
+	assert.Equal(t, "public, max-age=60", rec.Header().Get("Cache-Control"))
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.Equal(t, `{"response":{"allowed":true,"auditAnnotations":{"cacheable":"true"}}}`, rec.Body.String())
+
+	rec = serve(`{"response":{"allowed":true,"auditAnnotations":{"cacheable":"false"}}}`)
+	assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+
+	rec = serve(`{"response":{"allowed":true}}`)
+	assert.Equal(t, "", rec.Header().Get("Cache-Control"))
+}
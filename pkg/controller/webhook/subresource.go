@@ -0,0 +1,33 @@
+package webhook
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/armory/spinnaker-operator/pkg/util"
+)
+
+// SubresourcesEnv lists the subresources validated on top of the main resource, e.g. status. None are validated by
+// default so that controllers updating the status of resources don't go through validation.
+const SubresourcesEnv = "WEBHOOK_SUBRESOURCES"
+
+// subresourcesFromEnv returns the subresources of SubresourcesEnv
+func subresourcesFromEnv() ([]string, error) {
+	subs := util.ListFromEnv(SubresourcesEnv)
+	for _, s := range subs {
+		if s == "*" || strings.Contains(s, "/") {
+			return nil, fmt.Errorf("invalid %s \"%s\": expected the name of a subresource", SubresourcesEnv, s)
+		}
+	}
+	return subs, nil
+}
+
+// resources returns the resources of the registration's rule: the main resource, which doesn't match its
+// subresources, followed by the given subresources of it
+func (r registration) resources(subresources []string) []string {
+	res := []string{r.r}
+	for _, s := range subresources {
+		res = append(res, r.r+"/"+s)
+	}
+	return res
+}
@@ -0,0 +1,57 @@
+package webhook
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apiAdmissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ruleMatches tells whether the rule matches an update of the resource or of one of its subresources, as the API
+// server does
+func ruleMatches(rule apiAdmissionregistrationv1.RuleWithOperations, resource, subresource string) bool {
+	for _, r := range rule.Resources {
+		res, sub := r, ""
+		if i := strings.Index(r, "/"); i >= 0 {
+			res, sub = r[:i], r[i+1:]
+		}
+		if (res == "*" || res == resource) && (sub == subresource || (sub == "*" && subresource != "")) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestValidatingWebhookConfigurationSubresources(t *testing.T) {
+	saved := registrations
+	defer func() { registrations = saved }()
+	registrations = []registration{}
+	Register(schema.GroupVersionKind{Group: "spinnaker.io", Version: "v1alpha2", Kind: "SpinnakerAccount"}, "spinnakeraccounts", nil)
+
+	t.Run("main resource only", func(t *testing.T) {
+		cfg, err := validatingWebhookConfiguration("spinnaker-operator", "operator", &certContext{}, endpointSettings{}, apiAdmissionregistrationv1.Fail)
+		if assert.Nil(t, err) && assert.Len(t, cfg.Webhooks, 1) {
+			rule := cfg.Webhooks[0].Rules[0]
+			assert.True(t, ruleMatches(rule, "spinnakeraccounts", ""), "spec updates are validated")
+			assert.False(t, ruleMatches(rule, "spinnakeraccounts", "status"), "status updates bypass validation")
+		}
+	})
+
+	t.Run("status included", func(t *testing.T) {
+		t.Setenv(SubresourcesEnv, "status")
+		cfg, err := validatingWebhookConfiguration("spinnaker-operator", "operator", &certContext{}, endpointSettings{}, apiAdmissionregistrationv1.Fail)
+		if assert.Nil(t, err) && assert.Len(t, cfg.Webhooks, 1) {
+			rule := cfg.Webhooks[0].Rules[0]
+			assert.Equal(t, []string{"spinnakeraccounts", "spinnakeraccounts/status"}, rule.Resources)
+			assert.True(t, ruleMatches(rule, "spinnakeraccounts", "status"))
+		}
+	})
+
+	t.Run("invalid subresource", func(t *testing.T) {
+		t.Setenv(SubresourcesEnv, "*")
+		_, err := validatingWebhookConfiguration("spinnaker-operator", "operator", &certContext{}, endpointSettings{}, apiAdmissionregistrationv1.Fail)
+		assert.NotNil(t, err)
+	})
+}
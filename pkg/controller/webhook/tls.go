@@ -8,12 +8,14 @@ import (
 	"crypto/x509/pkix"
 	"encoding/pem"
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"math"
 	"math/big"
 	"net"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"k8s.io/client-go/util/cert"
@@ -35,11 +37,13 @@ type certContext struct {
 	key         []byte
 	signingCert []byte
 	certDir     string
+	// injectCAFrom is the cert-manager Certificate to inject the CA bundle from
+	injectCAFrom string
 }
 
 var CertsDir string
 
-func getCertContext(operatorNamespace string, operatorServiceName string) (*certContext, error) {
+func getCertContext(operatorNamespace string, operatorServiceName string, hosts ...string) (*certContext, error) {
 	err := os.Mkdir(CertsDir, 0700)
 	if err != nil && !os.IsExist(err) {
 		return nil, err
@@ -47,10 +51,11 @@ func getCertContext(operatorNamespace string, operatorServiceName string) (*cert
 	_ = os.Remove(filepath.Join(CertsDir, caName))
 	_ = os.Remove(filepath.Join(CertsDir, certName))
 	_ = os.Remove(filepath.Join(CertsDir, keyName))
-	return createCerts(operatorNamespace, operatorServiceName)
+	return createCerts(operatorNamespace, operatorServiceName, hosts...)
 }
 
-func createCerts(operatorNamespace string, operatorServiceName string) (*certContext, error) {
+// createCerts generates a certificate for the operator service, also valid for the given hosts
+func createCerts(operatorNamespace string, operatorServiceName string, hosts ...string) (*certContext, error) {
 	signingKey, err := newPrivateKey()
 	if err != nil {
 		return nil, err
@@ -66,14 +71,25 @@ func createCerts(operatorNamespace string, operatorServiceName string) (*certCon
 	if err != nil {
 		return nil, err
 	}
+	altNames := cert.AltNames{
+		DNSNames: []string{
+			operatorServiceName + "." + operatorNamespace + ".svc",
+			operatorServiceName + "." + operatorNamespace + ".svc.cluster.local",
+		},
+		IPs: []net.IP{net.ParseIP("::")},
+	}
+	for _, h := range hosts {
+		if ip := net.ParseIP(h); ip != nil {
+			altNames.IPs = append(altNames.IPs, ip)
+		} else {
+			altNames.DNSNames = append(altNames.DNSNames, h)
+		}
+	}
 	signedCert, err := newSignedCert(
 		&cert.Config{
 			CommonName: operatorServiceName + "." + operatorNamespace + ".svc",
 			Usages:     []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
-			AltNames: cert.AltNames{
-				DNSNames: []string{operatorServiceName + "." + operatorNamespace + ".svc"},
-				IPs:      []net.IP{net.ParseIP("::")},
-			},
+			AltNames:   altNames,
 		},
 		key, signingCert, signingKey,
 	)
@@ -98,6 +114,25 @@ func createCerts(operatorNamespace string, operatorServiceName string) (*certCon
 	}, nil
 }
 
+// checkCertNames returns an error if the first certificate of the given PEM data isn't valid for all the given names
+func checkCertNames(pemData []byte, names []string) error {
+	certs, err := cert.ParseCertsPEM(pemData)
+	if err != nil {
+		return fmt.Errorf("unable to parse webhook certificate: %w", err)
+	}
+	var missing []string
+	for _, n := range names {
+		if certs[0].VerifyHostname(n) != nil {
+			missing = append(missing, n)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("webhook certificate is not valid for %s called by the API server, its DNS names are %s and its IP addresses %v",
+			strings.Join(missing, ", "), strings.Join(certs[0].DNSNames, ", "), certs[0].IPAddresses)
+	}
+	return nil
+}
+
 // newPrivateKey creates an RSA private key
 func newPrivateKey() (*rsa.PrivateKey, error) {
 	return rsa.GenerateKey(rand.Reader, rsaKeySize)
@@ -0,0 +1,28 @@
+package webhook
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckCertNames(t *testing.T) {
+	defer func(d string) { CertsDir = d }(CertsDir)
+	CertsDir = t.TempDir()
+
+	c, err := createCerts("operator", "spinnaker-operator", "10.0.0.1")
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Nil(t, checkCertNames(c.cert, []string{"spinnaker-operator.operator.svc", "spinnaker-operator.operator.svc.cluster.local"}))
+	assert.Nil(t, checkCertNames(c.cert, endpointSettings{skipService: true, host: "10.0.0.1:9876"}.serverNames("operator", "spinnaker-operator")))
+
+	err = checkCertNames(c.cert, endpointSettings{}.serverNames("other", "spinnaker-operator"))
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "webhook certificate is not valid for spinnaker-operator.other.svc called by the API server, its DNS names are "+
+			"spinnaker-operator.operator.svc, spinnaker-operator.operator.svc.cluster.local and its IP addresses [:: 10.0.0.1]", err.Error())
+	}
+
+	err = checkCertNames([]byte("not a certificate"), []string{"spinnaker-operator.operator.svc"})
+	assert.NotNil(t, err)
+}
@@ -1,6 +1,7 @@
 package webhook
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
@@ -20,7 +21,8 @@ import (
 )
 
 const (
-	servicePort = 9876
+	servicePort       = 9876
+	webhookConfigName = "spinnakervalidatingwebhook"
 )
 
 var registrations = []registration{}
@@ -30,6 +32,10 @@ type registration struct {
 	h    admission.Handler
 	p    string
 	r    string
+	// failurePolicy overrides the default failure policy
+	failurePolicy *apiAdmissionregistrationv1.FailurePolicyType
+	// deletes sends deletions to the handler, on top of creations and updates
+	deletes bool
 }
 
 func Register(kind schema.GroupVersionKind, resources string, h admission.Handler) {
@@ -41,11 +47,45 @@ func Register(kind schema.GroupVersionKind, resources string, h admission.Handle
 	})
 }
 
+// RegisterWithDeletes registers a handler also validating the deletion of the resources
+func RegisterWithDeletes(kind schema.GroupVersionKind, resources string, h admission.Handler) {
+	Register(kind, resources, h)
+	registrations[len(registrations)-1].deletes = true
+}
+
+func (r registration) operations() []apiAdmissionregistrationv1.OperationType {
+	ops := []apiAdmissionregistrationv1.OperationType{apiAdmissionregistrationv1.Create, apiAdmissionregistrationv1.Update}
+	if r.deletes {
+		ops = append(ops, apiAdmissionregistrationv1.Delete)
+	}
+	return ops
+}
+
 func Start(m manager.Manager) error {
 	if len(registrations) == 0 {
 		return errors.New("no kind registered for validation")
 	}
 
+	settings, err := loadServerSettings()
+	if err != nil {
+		return err
+	}
+
+	endpoint, err := loadEndpointSettings()
+	if err != nil {
+		return err
+	}
+
+	policy, err := failurePolicyFromEnv()
+	if err != nil {
+		return err
+	}
+
+	pprofSrv, err := pprofServer()
+	if err != nil {
+		return err
+	}
+
 	ns, name, err := getOperatorNameAndNamespace()
 	if err != nil {
 		return err
@@ -53,26 +93,58 @@ func Start(m manager.Manager) error {
 
 	// Create Kubernetes service for listening to requests from API server
 	rawClient := kubernetes.NewForConfigOrDie(m.GetConfig())
-	err = deployWebhookService(ns, name, servicePort, rawClient)
-	if err != nil {
+	if registrations, err = servedRegistrations(rawClient.Discovery()); err != nil {
+		return err
+	}
+	if len(registrations) == 0 {
+		return errors.New("no kind registered for validation is served by the API server")
+	}
+	if err = checkPermissions(context.TODO(), rawClient, requiredPermissions(ns, endpoint, os.Getenv(TLSSecretEnv))); err != nil {
+		return err
+	}
+	if endpoint.skipService {
+		log.Info("Skipping webhook service creation", "host", endpoint.host)
+	} else if err = deployWebhookService(ns, name, servicePort, rawClient); err != nil {
 		return err
 	}
 
 	// Create or get certificates
-	c, err := getCertContext(ns, name)
+	c, err := resolveCertContext(context.TODO(), rawClient, ns, name)
 	if err != nil {
 		return err
 	}
+	if err = checkCertNames(c.cert, endpoint.serverNames(ns, name)); err != nil {
+		return err
+	}
 
-	hookServer := m.GetWebhookServer()
-	hookServer.CertDir = c.certDir
-	hookServer.Port = servicePort
-
+	hookServer := &webhookServer{Server: &webhook.Server{CertDir: c.certDir, Port: servicePort}, settings: settings}
 	for _, r := range registrations {
-		hookServer.Register(r.p, &webhook.Admission{Handler: r.h})
+		hookServer.Register(r.p, settings.wrap(&webhook.Admission{Handler: r.h}))
+	}
+	if err := m.Add(hookServer); err != nil {
+		return err
 	}
 	// Create validating webhook configuration for registering our webhook with the API server
-	return deployValidatingWebhookConfiguration(name, ns, rawClient, c.signingCert)
+	if err := deployValidatingWebhookConfiguration(name, ns, rawClient, c, endpoint, policy); err != nil {
+		return err
+	}
+
+	if pprofSrv != nil {
+		if err := m.Add(manager.RunnableFunc(func(ctx context.Context) error {
+			return servePprof(ctx, pprofSrv)
+		})); err != nil {
+			return err
+		}
+	}
+
+	// Reload certificates rotated in the external secret
+	if v := os.Getenv(TLSSecretEnv); v != "" {
+		secretNs, secretName := splitNamespacedName(v, ns)
+		return m.Add(manager.RunnableFunc(func(ctx context.Context) error {
+			return watchTLSSecret(ctx, rawClient, secretNs, secretName, c)
+		}))
+	}
+	return nil
 }
 
 func getOperatorNameAndNamespace() (string, string, error) {
@@ -80,15 +152,25 @@ func getOperatorNameAndNamespace() (string, string, error) {
 	if err != nil {
 		return "", "", err
 	}
+	ns, err := OperatorNamespace()
+	if err != nil {
+		return "", "", err
+	}
+	return ns, name, nil
+}
+
+// OperatorNamespace returns the namespace the operator runs in, read from ADMISSION_PROXY_NAMESPACE when running
+// outside of a cluster
+func OperatorNamespace() (string, error) {
 	ns, err := k8sutil.GetOperatorNamespace()
 	if err != nil {
 		envNs := os.Getenv("ADMISSION_PROXY_NAMESPACE")
 		if envNs == "" {
-			return "", "", fmt.Errorf("unable to determine operator namespace. Error: %s and ADMISSION_PROXY_NAMESPACE env var not set", err.Error())
+			return "", fmt.Errorf("unable to determine operator namespace. Error: %s and ADMISSION_PROXY_NAMESPACE env var not set", err.Error())
 		}
 		ns = envNs
 	}
-	return ns, name, nil
+	return ns, nil
 }
 
 func generateValidatePath(gvk schema.GroupVersionKind) string {
@@ -119,43 +201,63 @@ func deployWebhookService(ns string, name string, port int, rawClient *kubernete
 	return util.CreateOrUpdateService(service, rawClient)
 }
 
-func deployValidatingWebhookConfiguration(svcName, ns string, rawClient *kubernetes.Clientset, cert []byte) error {
+func deployValidatingWebhookConfiguration(svcName, ns string, rawClient kubernetes.Interface, c *certContext, endpoint endpointSettings, policy apiAdmissionregistrationv1.FailurePolicyType) error {
+	webhookConfig, err := validatingWebhookConfiguration(svcName, ns, c, endpoint, policy)
+	if err != nil {
+		return err
+	}
+	return util.CreateOrUpdateValidatingWebhookConfiguration(webhookConfig, rawClient)
+}
+
+// validatingWebhookConfiguration returns the configuration of the registered webhooks, using the given failure
+// policy for registrations without one
+func validatingWebhookConfiguration(svcName, ns string, c *certContext, endpoint endpointSettings, policy apiAdmissionregistrationv1.FailurePolicyType) (*apiAdmissionregistrationv1.ValidatingWebhookConfiguration, error) {
 	webhookConfig := &apiAdmissionregistrationv1.ValidatingWebhookConfiguration{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "spinnakervalidatingwebhook",
-			Namespace: ns,
+			Name: webhookConfigName,
 		},
 		Webhooks: []apiAdmissionregistrationv1.ValidatingWebhook{},
 	}
+	if c.injectCAFrom != "" {
+		webhookConfig.Annotations = map[string]string{certManagerInjectAnnotation: c.injectCAFrom}
+	}
 
+	tmpl := os.Getenv(NameTemplateEnv)
+	if tmpl == "" {
+		tmpl = DefaultNameTemplate
+	}
+	subresources, err := subresourcesFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	names := map[string]bool{}
 	for i := range registrations {
 		r := registrations[i]
+		name, err := WebhookName(tmpl, r.r, r.kind)
+		if err != nil {
+			return nil, err
+		}
+		if names[name] {
+			return nil, fmt.Errorf("webhook name %s is used by more than one resource, check %s", name, NameTemplateEnv)
+		}
+		names[name] = true
 		webhookConfig.Webhooks = append(webhookConfig.Webhooks, apiAdmissionregistrationv1.ValidatingWebhook{
-			Name: fmt.Sprintf("webhook-%s-%s.%s", r.r, r.kind.Version, strings.ToLower(r.kind.Group)),
-			ClientConfig: apiAdmissionregistrationv1.WebhookClientConfig{
-				Service: &apiAdmissionregistrationv1.ServiceReference{
-					Namespace: ns,
-					Name:      svcName,
-					Path:      &r.p,
-				},
-				CABundle: cert,
-			},
+			Name:         name,
+			ClientConfig: endpoint.clientConfig(ns, svcName, r.p, c.signingCert),
 			Rules: []apiAdmissionregistrationv1.RuleWithOperations{{
-				Operations: []apiAdmissionregistrationv1.OperationType{
-					apiAdmissionregistrationv1.Create,
-					apiAdmissionregistrationv1.Update,
-				},
+				Operations: r.operations(),
 				Rule: apiAdmissionregistrationv1.Rule{
 					APIGroups:   []string{r.kind.Group},
 					APIVersions: []string{r.kind.Version},
-					Resources:   []string{r.r}, // should be "spinnakerservices"
+					Resources:   r.resources(subresources), // should be "spinnakerservices"
 				},
 			}},
+			FailurePolicy:           r.policy(policy),
 			SideEffects:             sideEffect(apiAdmissionregistrationv1.SideEffectClassNone),
-			AdmissionReviewVersions: []string{"v1"},
+			AdmissionReviewVersions: admissionReviewVersions,
 		})
 	}
-	return util.CreateOrUpdateValidatingWebhookConfiguration(webhookConfig, rawClient)
+	return webhookConfig, nil
 }
 
 func sideEffect(sideEffect apiAdmissionregistrationv1.SideEffectClass) *apiAdmissionregistrationv1.SideEffectClass {
@@ -0,0 +1,59 @@
+package webhook
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apiAdmissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestDeployValidatingWebhookConfigurationUpgrade(t *testing.T) {
+	saved := registrations
+	defer func() { registrations = saved }()
+	services := schema.GroupVersionKind{Group: "spinnaker.io", Version: "v1alpha2", Kind: "SpinnakerService"}
+	accounts := schema.GroupVersionKind{Group: "spinnaker.io", Version: "v1alpha2", Kind: "SpinnakerAccount"}
+	client := fake.NewSimpleClientset()
+	deploy := func(c *certContext) *apiAdmissionregistrationv1.ValidatingWebhookConfiguration {
+		if !assert.Nil(t, deployValidatingWebhookConfiguration("spinnaker-operator", "operator", client, c, endpointSettings{}, apiAdmissionregistrationv1.Fail)) {
+			t.FailNow()
+		}
+		cfg, err := client.AdmissionregistrationV1().ValidatingWebhookConfigurations().Get(context.TODO(), webhookConfigName, metav1.GetOptions{})
+		if !assert.Nil(t, err) {
+			t.FailNow()
+		}
+		return cfg
+	}
+	names := func(cfg *apiAdmissionregistrationv1.ValidatingWebhookConfiguration) []string {
+		n := make([]string, 0, len(cfg.Webhooks))
+		for _, w := range cfg.Webhooks {
+			n = append(n, w.Name)
+		}
+		return n
+	}
+
+	// previous version validating services and accounts
+	registrations = []registration{}
+	Register(services, "spinnakerservices", nil)
+	Register(accounts, "spinnakeraccounts", nil)
+	cfg := deploy(&certContext{signingCert: []byte("ca")})
+	assert.Equal(t, []string{"webhook-spinnakerservices-v1alpha2.spinnaker.io", "webhook-spinnakeraccounts-v1alpha2.spinnaker.io"}, names(cfg))
+
+	// upgrade no longer validating accounts, with the CA bundle injected by cert-manager
+	registrations = []registration{}
+	Register(services, "spinnakerservices", nil)
+	cfg = deploy(&certContext{injectCAFrom: "operator/spinnaker-operator"})
+	if assert.Equal(t, []string{"webhook-spinnakerservices-v1alpha2.spinnaker.io"}, names(cfg)) {
+		assert.Equal(t, []byte("ca"), cfg.Webhooks[0].ClientConfig.CABundle, "CA bundle is kept")
+	}
+	assert.Equal(t, "operator/spinnaker-operator", cfg.Annotations[certManagerInjectAnnotation])
+
+	// rotated CA bundle
+	cfg = deploy(&certContext{signingCert: []byte("ca2")})
+	if assert.Len(t, cfg.Webhooks, 1) {
+		assert.Equal(t, []byte("ca2"), cfg.Webhooks[0].ClientConfig.CABundle)
+	}
+}
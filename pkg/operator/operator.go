@@ -7,12 +7,14 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"time"
 
 	"github.com/armory/spinnaker-operator/pkg/controller"
 	"github.com/armory/spinnaker-operator/pkg/controller/accountvalidating"
 	"github.com/armory/spinnaker-operator/pkg/controller/spinnakerservice"
 	"github.com/armory/spinnaker-operator/pkg/controller/spinnakervalidating"
 	"github.com/armory/spinnaker-operator/pkg/controller/webhook"
+	"github.com/armory/spinnaker-operator/pkg/util"
 	"github.com/armory/spinnaker-operator/pkg/version"
 	"github.com/operator-framework/operator-sdk/pkg/k8sutil"
 	kubemetrics "github.com/operator-framework/operator-sdk/pkg/kube-metrics"
@@ -40,6 +42,9 @@ var (
 )
 var log = logf.Log.WithName("cmd")
 
+// cacheSyncPeriodEnv overrides how often the manager's cache is resynced, controller-runtime defaults to 10 hours
+const cacheSyncPeriodEnv = "CACHE_RESYNC_PERIOD"
+
 func printVersion() {
 	log.Info(fmt.Sprintf("Spinnaker Operator Version: %v", version.GetOperatorVersion()))
 	log.Info(fmt.Sprintf("Go Version: %s", runtime.Version()))
@@ -47,6 +52,15 @@ func printVersion() {
 	log.Info(fmt.Sprintf("Version of operator-sdk: %v", sdkVersion.Version))
 }
 
+// cacheSyncPeriod returns the sync period of the manager's cache, nil for controller-runtime's default
+func cacheSyncPeriod() (*time.Duration, error) {
+	if os.Getenv(cacheSyncPeriodEnv) == "" {
+		return nil, nil
+	}
+	d, err := util.DurationFromEnv(cacheSyncPeriodEnv, 0)
+	return &d, err
+}
+
 func Start(apiScheme func(s *kruntime.Scheme) error) {
 	// Add the zap logger flag set to the CLI. The flag set must
 	// be added before calling pflag.Parse().
@@ -101,9 +115,16 @@ func Start(apiScheme func(s *kruntime.Scheme) error) {
 		os.Exit(1)
 	}
 
+	syncPeriod, err := cacheSyncPeriod()
+	if err != nil {
+		log.Error(err, "")
+		os.Exit(1)
+	}
+
 	// Create a new Cmd to provide shared dependencies and start components
 	mgr, err := manager.New(cfg, manager.Options{
 		Namespace:          namespace,
+		SyncPeriod:         syncPeriod,
 		MapperProvider:     apiutil.NewDiscoveryRESTMapper,
 		MetricsBindAddress: fmt.Sprintf("%s:%d", metricsHost, metricsPort),
 	})
@@ -0,0 +1,110 @@
+package operator
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/armory/spinnaker-operator/pkg/controller/accountvalidating"
+	kruntime "k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+)
+
+// ValidateAccountCommand validates the SpinnakerAccounts of a file without running the operator
+const ValidateAccountCommand = "validate-account"
+
+// Exit codes of the validate-account command
+const (
+	ExitValid   = 0
+	ExitInvalid = 1
+	ExitError   = 2
+)
+
+type clientFactory func(s *kruntime.Scheme) (client.Client, *rest.Config, error)
+
+// ValidateAccount validates the SpinnakerAccounts in the (multi-document) file given in args using the ambient
+// kubeconfig and cloud credentials. Warnings and errors are printed to out and the exit code is returned.
+func ValidateAccount(args []string, apiScheme func(s *kruntime.Scheme) error, out io.Writer) int {
+	return validateAccount(args, apiScheme, newAmbientClient, out)
+}
+
+func newAmbientClient(s *kruntime.Scheme) (client.Client, *rest.Config, error) {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+	c, err := client.New(cfg, client.Options{Scheme: s})
+	return c, cfg, err
+}
+
+func validateAccount(args []string, apiScheme func(s *kruntime.Scheme) error, newClient clientFactory, out io.Writer) int {
+	fs := flag.NewFlagSet(ValidateAccountCommand, flag.ContinueOnError)
+	fs.SetOutput(out)
+	namespace := fs.String("namespace", "", "Namespace of the account if not set in the file")
+	fs.Usage = func() {
+		fmt.Fprintf(out, "Usage: %s [--namespace <namespace>] <file.yaml>\n", ValidateAccountCommand)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return ExitError
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return ExitError
+	}
+
+	b, err := ioutil.ReadFile(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(out, "ERROR: %s\n", err)
+		return ExitError
+	}
+	ns := *namespace
+	if ns == "" {
+		ns = "default"
+	}
+
+	s := kruntime.NewScheme()
+	for _, add := range []func(s *kruntime.Scheme) error{clientgoscheme.AddToScheme, apiScheme} {
+		if err := add(s); err != nil {
+			fmt.Fprintf(out, "ERROR: %s\n", err)
+			return ExitError
+		}
+	}
+	c, cfg, err := newClient(s)
+	if err != nil {
+		fmt.Fprintf(out, "ERROR: unable to connect to Kubernetes: %s\n", err)
+		return ExitError
+	}
+
+	results, err := accountvalidating.ValidateAccountsYAML(context.Background(), c, cfg, b, ns)
+	if err != nil {
+		fmt.Fprintf(out, "ERROR: unable to read accounts from %s: %s\n", fs.Arg(0), err)
+		return ExitError
+	}
+	if len(results) == 0 {
+		fmt.Fprintf(out, "ERROR: no account found in %s\n", fs.Arg(0))
+		return ExitError
+	}
+	code := ExitValid
+	for _, r := range results {
+		prefix := ""
+		if len(results) > 1 {
+			prefix = fmt.Sprintf("document %d: ", r.Document)
+		}
+		for _, w := range r.Warnings {
+			fmt.Fprintf(out, "%sWARNING: %s\n", prefix, w)
+		}
+		if r.Status == accountvalidating.AccountFailed {
+			fmt.Fprintf(out, "%sERROR: %s\n", prefix, r.Error)
+			code = ExitInvalid
+			continue
+		}
+		fmt.Fprintf(out, "%saccount %s is valid\n", prefix, r.Name)
+	}
+	return code
+}
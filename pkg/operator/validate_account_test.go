@@ -0,0 +1,45 @@
+package operator
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/armory/spinnaker-operator/pkg/apis"
+	"github.com/armory/spinnaker-operator/pkg/controller/accountvalidating"
+	"github.com/armory/spinnaker-operator/pkg/test"
+	"github.com/stretchr/testify/assert"
+	kruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestValidateAccount(t *testing.T) {
+	accountvalidating.TypesFactory = test.TypesFactory
+	// The fixtures point to a cluster that doesn't exist
+	t.Setenv("VALIDATE_ACCOUNT_CONNECTIVITY", "false")
+	newClient := func(s *kruntime.Scheme) (client.Client, *rest.Config, error) {
+		return fake.NewClientBuilder().WithScheme(s).Build(), &rest.Config{}, nil
+	}
+
+	cases := []struct {
+		name     string
+		args     []string
+		exitCode int
+		output   string
+	}{
+		{"valid account", []string{"testdata/account-valid.yml"}, ExitValid, "account kube is valid\n"},
+		{"invalid account", []string{"--namespace", "ns1", "testdata/account-invalid.yml"}, ExitInvalid, `ERROR: at most one of "namespaces" and "omitNamespaces" can be supplied`},
+		{"multiple accounts", []string{"testdata/accounts-multi.yml"}, ExitInvalid, "document 0: account kube is valid\ndocument 1: ERROR: at most one of"},
+		{"missing file", []string{"testdata/missing.yml"}, ExitError, "ERROR: open testdata/missing.yml"},
+		{"no file", []string{}, ExitError, "Usage: validate-account"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			code := validateAccount(c.args, apis.AddToScheme, newClient, out)
+			assert.Equal(t, c.exitCode, code)
+			assert.Contains(t, out.String(), c.output)
+		})
+	}
+}
@@ -12,6 +12,10 @@ type SecretContext struct {
 	FileCache  map[string]string
 	RestConfig *rest.Config
 	Namespace  string
+	// FallbackNamespace is where Kubernetes secrets missing from Namespace are read, see WithLookupNamespace
+	FallbackNamespace string
+	// Overrides replace the value of secrets, see NewContextWithOverrides
+	Overrides map[string][]byte
 }
 
 var errContextNotInitialized = errors.New("secret context not initialized")
@@ -0,0 +1,51 @@
+package secrets
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+)
+
+var (
+	ErrSecretFileNotFound   = errors.New("referenced secret file not found")
+	ErrSecretFileUnreadable = errors.New("referenced secret file is not readable")
+	ErrEmptySecret          = errors.New("empty secret")
+)
+
+// CheckNotEmpty verifies that the value resolved for the named secret reference isn't empty or whitespace only,
+// as left by automation writing a secret before its value is known
+func CheckNotEmpty(name string, data []byte) error {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return fmt.Errorf("%w %s: the referenced value is empty", ErrEmptySecret, name)
+	}
+	return nil
+}
+
+// CheckFile verifies that a file referenced by a secret exists and can be read
+func CheckFile(path string) error {
+	fi, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("%w at %s", ErrSecretFileNotFound, path)
+		}
+		return fmt.Errorf("%w at %s:\n  %v", ErrSecretFileUnreadable, path, err)
+	}
+	if fi.IsDir() {
+		return fmt.Errorf("%w at %s: path is a directory", ErrSecretFileUnreadable, path)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("%w at %s:\n  %v", ErrSecretFileUnreadable, path, err)
+	}
+	return f.Close()
+}
+
+// ReadFile reads a file referenced by a secret after checking it exists and is readable
+func ReadFile(path string) ([]byte, error) {
+	if err := CheckFile(path); err != nil {
+		return nil, err
+	}
+	return ioutil.ReadFile(path)
+}
@@ -0,0 +1,63 @@
+package secrets
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckFile(t *testing.T) {
+	dir := t.TempDir()
+	present := filepath.Join(dir, "kubeconfig")
+	if !assert.Nil(t, ioutil.WriteFile(present, []byte("content"), 0600)) {
+		return
+	}
+
+	t.Run("present file", func(t *testing.T) {
+		b, err := ReadFile(present)
+		assert.Nil(t, err)
+		assert.Equal(t, "content", string(b))
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		missing := filepath.Join(dir, "missing")
+		err := CheckFile(missing)
+		if assert.NotNil(t, err) {
+			assert.True(t, errors.Is(err, ErrSecretFileNotFound))
+			assert.Equal(t, "referenced secret file not found at "+missing, err.Error())
+		}
+	})
+
+	t.Run("directory", func(t *testing.T) {
+		err := CheckFile(dir)
+		assert.True(t, errors.Is(err, ErrSecretFileUnreadable))
+	})
+
+	t.Run("unreadable file", func(t *testing.T) {
+		if os.Geteuid() == 0 {
+			t.Skip("file permissions are not enforced for root")
+		}
+		unreadable := filepath.Join(dir, "unreadable")
+		if !assert.Nil(t, ioutil.WriteFile(unreadable, []byte("content"), 0000)) {
+			return
+		}
+		err := CheckFile(unreadable)
+		assert.True(t, errors.Is(err, ErrSecretFileUnreadable))
+	})
+}
+
+func TestCheckNotEmpty(t *testing.T) {
+	assert.Nil(t, CheckNotEmpty("kubeconfigs/config", []byte("apiVersion: v1\n")))
+
+	for _, v := range []string{"", " \t\n"} {
+		err := CheckNotEmpty("kubeconfigs/config", []byte(v))
+		if assert.NotNil(t, err) {
+			assert.True(t, errors.Is(err, ErrEmptySecret))
+			assert.Equal(t, "empty secret kubeconfigs/config: the referenced value is empty", err.Error())
+		}
+	}
+}
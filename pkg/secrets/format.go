@@ -0,0 +1,158 @@
+package secrets
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"sigs.k8s.io/yaml"
+)
+
+var (
+	ErrMalformedSecret = errors.New("malformed secret")
+	// ErrCredentialMismatch is returned for secrets holding another kind of credential than expected, e.g. the key
+	// of a GCP service account instead of a kubeconfig. Such errors are also malformed secret errors.
+	ErrCredentialMismatch = errors.New("credential mismatch")
+)
+
+// Format is the expected format of a secret's content
+type Format string
+
+const (
+	KubeconfigFormat Format = "kubeconfig"
+	GCPKeyFormat     Format = "GCP JSON key"
+	PEMFormat        Format = "PEM"
+	TokenFormat      Format = "bearer token"
+	// AWSKeysFormat is only detected, to tell AWS access keys apart from expected credentials
+	AWSKeysFormat Format = "AWS access key"
+)
+
+var formatChecks = map[Format]func([]byte) error{
+	KubeconfigFormat: checkKubeconfig,
+	GCPKeyFormat:     checkGCPKey,
+	PEMFormat:        checkPEM,
+	TokenFormat:      checkToken,
+}
+
+// detectedFormats are the credentials recognized in secrets not having their expected format, from the most specific
+var detectedFormats = []struct {
+	format Format
+	detect func([]byte) bool
+}{
+	{GCPKeyFormat, isGCPKey},
+	{AWSKeysFormat, isAWSKeys},
+	{KubeconfigFormat, func(data []byte) bool { return checkKubeconfig(data) == nil }},
+	{PEMFormat, func(data []byte) bool { return checkPEM(data) == nil }},
+}
+
+// awsAccessKeyPattern matches the IDs of long-term (AKIA) and temporary (ASIA) AWS access keys
+var awsAccessKeyPattern = regexp.MustCompile(`\b(AKIA|ASIA)[A-Z0-9]{16}\b`)
+
+type credentialMismatchError struct {
+	name     string
+	expected Format
+	actual   Format
+}
+
+func (e *credentialMismatchError) Error() string {
+	return fmt.Sprintf("%s %s: credential does not look like a %s credential (found %s instead)", ErrMalformedSecret, e.name, e.expected, e.actual)
+}
+
+func (e *credentialMismatchError) Is(target error) bool {
+	return target == ErrCredentialMismatch
+}
+
+func (e *credentialMismatchError) Unwrap() error {
+	return ErrMalformedSecret
+}
+
+// CheckFormat checks that the content of the named secret has the given format. Content that only
+// has the right format once base64 decoded, or that is another kind of credential, is reported as such.
+func CheckFormat(name string, data []byte, f Format) error {
+	check, ok := formatChecks[f]
+	if !ok {
+		return fmt.Errorf("unknown secret format %s", f)
+	}
+	if err := CheckNotEmpty(name, data); err != nil {
+		return err
+	}
+	err := check(data)
+	if err == nil {
+		return nil
+	}
+	if decoded, derr := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(data))); derr == nil && check(decoded) == nil {
+		return fmt.Errorf("%w %s: content is base64 encoded, expected a %s once decoded", ErrMalformedSecret, name, f)
+	}
+	for _, d := range detectedFormats {
+		if d.format != f && d.detect(data) {
+			return &credentialMismatchError{name: name, expected: f, actual: d.format}
+		}
+	}
+	return fmt.Errorf("%w %s: not a valid %s: %v", ErrMalformedSecret, name, f, err)
+}
+
+func checkKubeconfig(data []byte) error {
+	m := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return err
+	}
+	if _, ok := m["clusters"]; !ok {
+		return errors.New("no clusters defined")
+	}
+	return nil
+}
+
+func checkGCPKey(data []byte) error {
+	k := struct {
+		Type string `json:"type"`
+	}{}
+	if err := json.Unmarshal(data, &k); err != nil {
+		return err
+	}
+	if k.Type == "" {
+		return errors.New("missing type field")
+	}
+	return nil
+}
+
+// checkToken checks the content is a single opaque string, as bearer tokens are
+func checkToken(data []byte) error {
+	t := bytes.TrimSpace(data)
+	if bytes.ContainsAny(t, " \t\r\n{}") {
+		return errors.New("tokens can't contain whitespace or braces")
+	}
+	return nil
+}
+
+func isGCPKey(data []byte) bool {
+	k := struct {
+		Type       string `json:"type"`
+		PrivateKey string `json:"private_key"`
+		ClientID   string `json:"client_id"`
+	}{}
+	return json.Unmarshal(data, &k) == nil && k.Type != "" && (k.PrivateKey != "" || k.ClientID != "")
+}
+
+func isAWSKeys(data []byte) bool {
+	return bytes.Contains(data, []byte("aws_access_key_id")) || awsAccessKeyPattern.Match(data)
+}
+
+func checkPEM(data []byte) error {
+	rest := bytes.TrimSpace(data)
+	if len(rest) == 0 {
+		return errors.New("content is empty")
+	}
+	for len(rest) > 0 {
+		var b *pem.Block
+		b, rest = pem.Decode(rest)
+		if b == nil {
+			return errors.New("no PEM block found")
+		}
+		rest = bytes.TrimSpace(rest)
+	}
+	return nil
+}
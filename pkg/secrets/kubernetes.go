@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"github.com/armory/go-yaml-tools/pkg/secrets"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/rest"
 	"strings"
@@ -15,6 +14,7 @@ type KubernetesDecrypter struct {
 	key        string
 	restConfig *rest.Config
 	namespace  string
+	fallback   string
 	isFile     bool
 	ctx        context.Context
 }
@@ -24,7 +24,7 @@ func NewKubernetesSecretDecrypter(ctx context.Context, isFile bool, params strin
 	if err != nil {
 		return nil, err
 	}
-	k := &KubernetesDecrypter{restConfig: c.RestConfig, namespace: c.Namespace, isFile: isFile, ctx: ctx}
+	k := &KubernetesDecrypter{restConfig: c.RestConfig, namespace: c.Namespace, fallback: c.FallbackNamespace, isFile: isFile, ctx: ctx}
 	if err := k.parse(params); err != nil {
 		return nil, err
 	}
@@ -32,11 +32,16 @@ func NewKubernetesSecretDecrypter(ctx context.Context, isFile bool, params strin
 }
 
 func (k *KubernetesDecrypter) Decrypt() (string, error) {
+	if c, ok := FromContext(k.ctx); ok {
+		if v, ok, err := c.override(kubernetesOverrideKey(k.name, k.key), k.isFile); ok {
+			return v, err
+		}
+	}
 	client, err := corev1.NewForConfig(k.restConfig)
 	if err != nil {
 		return "", fmt.Errorf("Error creating kubernetes client:\n  %w", err)
 	}
-	sec, err := client.Secrets(k.namespace).Get(k.ctx, k.name, metav1.GetOptions{})
+	sec, err := getSecret(k.ctx, client, k.namespace, k.fallback, k.name)
 	if err != nil {
 		return "", fmt.Errorf("Error reading secret with name '%s' from kubernetes:\n  %w", k.name, err)
 	}
@@ -0,0 +1,31 @@
+package secrets
+
+import (
+	"context"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+)
+
+// WithLookupNamespace makes the secret context read Kubernetes secrets from namespace, falling back on the context's
+// namespace for secrets that don't exist there. The context is unchanged if namespace is empty.
+func WithLookupNamespace(ctx context.Context, namespace string) context.Context {
+	sc, ok := FromContext(ctx)
+	if !ok || namespace == "" || namespace == sc.Namespace {
+		return ctx
+	}
+	sc.FallbackNamespace = sc.Namespace
+	sc.Namespace = namespace
+	return ctx
+}
+
+// getSecret reads a secret from namespace, or from fallback if it's not found in namespace
+func getSecret(ctx context.Context, client corev1.SecretsGetter, namespace, fallback, name string) (*v1.Secret, error) {
+	sec, err := client.Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) && fallback != "" && fallback != namespace {
+		return client.Secrets(fallback).Get(ctx, name, metav1.GetOptions{})
+	}
+	return sec, err
+}
@@ -0,0 +1,45 @@
+package secrets
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestGetSecretFallback(t *testing.T) {
+	c := fake.NewSimpleClientset(
+		&v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "shared", Namespace: "secrets"}, Data: map[string][]byte{"k": []byte("configured")}},
+		&v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "shared", Namespace: "accounts"}, Data: map[string][]byte{"k": []byte("account")}},
+		&v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "local", Namespace: "accounts"}, Data: map[string][]byte{"k": []byte("local")}},
+	).CoreV1()
+
+	s, err := getSecret(context.TODO(), c, "secrets", "accounts", "shared")
+	if assert.Nil(t, err) {
+		assert.Equal(t, "configured", string(s.Data["k"]), "the configured namespace comes first")
+	}
+	s, err = getSecret(context.TODO(), c, "secrets", "accounts", "local")
+	if assert.Nil(t, err) {
+		assert.Equal(t, "local", string(s.Data["k"]), "missing secrets are read from the fallback namespace")
+	}
+	_, err = getSecret(context.TODO(), c, "secrets", "", "local")
+	assert.True(t, apierrors.IsNotFound(err), "no fallback")
+	_, err = getSecret(context.TODO(), c, "secrets", "accounts", "missing")
+	assert.True(t, apierrors.IsNotFound(err))
+}
+
+func TestWithLookupNamespace(t *testing.T) {
+	ctx := WithLookupNamespace(NewContext(context.TODO(), nil, "accounts"), "secrets")
+	sc, _ := FromContext(ctx)
+	assert.Equal(t, "secrets", sc.Namespace)
+	assert.Equal(t, "accounts", sc.FallbackNamespace)
+
+	ctx = WithLookupNamespace(NewContext(context.TODO(), nil, "accounts"), "")
+	sc, _ = FromContext(ctx)
+	assert.Equal(t, "accounts", sc.Namespace)
+	assert.Equal(t, "", sc.FallbackNamespace)
+}
@@ -0,0 +1,61 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/armory/go-yaml-tools/pkg/secrets"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+)
+
+// NewContextWithOverrides returns a secret context resolving the given secrets to the given values instead of reading
+// them from their backend. Overrides are keyed by the secret reference as written in the account
+// (e.g. encrypted:s3!r:us-west-2!b:bucket!f:token) or by <name>/<key> for Kubernetes secrets of the namespace.
+func NewContextWithOverrides(ctx context.Context, c *rest.Config, namespace string, overrides map[string][]byte) context.Context {
+	ctx = NewContext(ctx, c, namespace)
+	sc, _ := FromContext(ctx)
+	sc.Overrides = overrides
+	return ctx
+}
+
+// override returns the value overriding the given secret reference, written to a temporary file if isFile
+func (s *SecretContext) override(ref string, isFile bool) (string, bool, error) {
+	o, ok := s.Overrides[ref]
+	if !ok {
+		return "", false, nil
+	}
+	if isFile {
+		f, err := secrets.ToTempFile(o)
+		return f, true, err
+	}
+	return string(o), true, nil
+}
+
+// GetKubernetesSecret returns the value of a key of a Kubernetes secret in the context's namespace, or its fallback
+// namespace if the secret doesn't exist there
+func GetKubernetesSecret(ctx context.Context, name, key string) (string, error) {
+	sc, err := FromContextWithError(ctx)
+	if err != nil {
+		return "", err
+	}
+	if v, ok, _ := sc.override(kubernetesOverrideKey(name, key), false); ok {
+		return v, nil
+	}
+	client, err := corev1.NewForConfig(sc.RestConfig)
+	if err != nil {
+		return "", err
+	}
+	sec, err := getSecret(ctx, client, sc.Namespace, sc.FallbackNamespace, name)
+	if err != nil {
+		return "", err
+	}
+	if d, ok := sec.Data[key]; ok {
+		return string(d), nil
+	}
+	return "", fmt.Errorf("secret not found: no key %s in secret %s", key, name)
+}
+
+func kubernetesOverrideKey(name, key string) string {
+	return fmt.Sprintf("%s/%s", name, key)
+}
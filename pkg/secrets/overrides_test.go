@@ -0,0 +1,36 @@
+package secrets
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeWithOverrides(t *testing.T) {
+	ctx := NewContextWithOverrides(context.TODO(), nil, "ns", map[string][]byte{
+		"encrypted:noop!value": []byte("overridden"),
+		"kubeconfigs/next":     []byte("next-kubeconfig"),
+		"encryptedFile:noop!f": []byte("file-content"),
+	})
+	defer Cleanup(ctx)
+
+	v, _, err := Decode(ctx, "encrypted:noop!value")
+	assert.Nil(t, err)
+	assert.Equal(t, "overridden", v)
+
+	v, _, err = Decode(ctx, "encrypted:k8s!n:kubeconfigs!k:next")
+	assert.Nil(t, err)
+	assert.Equal(t, "next-kubeconfig", v)
+
+	f, err := DecodeAsFile(ctx, "encryptedFile:noop!f")
+	if assert.Nil(t, err) {
+		b, err := ReadFile(f)
+		assert.Nil(t, err)
+		assert.Equal(t, "file-content", string(b))
+	}
+
+	v, err = GetKubernetesSecret(ctx, "kubeconfigs", "next")
+	assert.Nil(t, err)
+	assert.Equal(t, "next-kubeconfig", v)
+}
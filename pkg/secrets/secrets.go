@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"github.com/armory/go-yaml-tools/pkg/secrets"
-	"os"
 )
 
 func init() {
@@ -39,7 +38,10 @@ func Decode(ctx context.Context, val string) (string, bool, error) {
 		return v, true, nil
 	}
 
-	v, err = dec.Decrypt()
+	v, ok, err := c.override(val, dec.IsFile())
+	if !ok {
+		v, err = dec.Decrypt()
+	}
 	if err != nil {
 		return "", false, fmt.Errorf("Error decrypting secret value '%s':\n  %w", val, err)
 	}
@@ -60,11 +62,10 @@ func DecodeAsFile(ctx context.Context, val string) (string, error) {
 	if err != nil {
 		return "", fmt.Errorf("Error decoding string \"%s\":\n  %w", val, err)
 	}
-	_, err = os.Stat(s)
-	if err != nil {
+	if err = CheckFile(s); err != nil {
 		return s, fmt.Errorf("Error decoding string \"%s\" into a file:\n  %w\nDid you use \"encrypted\" instead of \"encryptedFile\"?", val, err)
 	}
-	return s, err
+	return s, nil
 }
 
 // ShouldDecryptToValidate should we decrypt that value before sending to Halyard for validation?
@@ -0,0 +1,40 @@
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Now()
+	b := NewCircuitBreaker(2, time.Minute)
+	b.now = func() time.Time { return now }
+
+	b.Record(true)
+	assert.True(t, b.Allow(), "closed below the threshold")
+	b.Record(true)
+	assert.False(t, b.Allow(), "open at the threshold")
+
+	now = now.Add(time.Minute)
+	assert.True(t, b.Allow(), "probe after the cooldown")
+	assert.False(t, b.Allow(), "a single probe at a time")
+	b.Record(true)
+	assert.False(t, b.Allow(), "open again after a failed probe")
+
+	now = now.Add(time.Minute)
+	assert.True(t, b.Allow())
+	b.Record(false)
+	assert.True(t, b.Allow(), "closed after a successful probe")
+	b.Record(true)
+	assert.True(t, b.Allow(), "failures are counted again from zero")
+}
+
+func TestCircuitBreakerDisabled(t *testing.T) {
+	b := NewCircuitBreaker(0, time.Minute)
+	for i := 0; i < 10; i++ {
+		b.Record(true)
+	}
+	assert.True(t, b.Allow())
+}
@@ -0,0 +1,86 @@
+package util
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ListFromEnv reads a comma separated list from the given environment variable
+func ListFromEnv(env string) []string {
+	l := make([]string, 0)
+	for _, s := range strings.Split(os.Getenv(env), ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			l = append(l, s)
+		}
+	}
+	return l
+}
+
+// BoolFromEnv reads a boolean from the given environment variable, returning def if not set
+func BoolFromEnv(env string, def bool) (bool, error) {
+	v := os.Getenv(env)
+	if v == "" {
+		return def, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return def, fmt.Errorf("invalid %s \"%s\": expected true or false", env, v)
+	}
+	return b, nil
+}
+
+// IntFromEnv reads a positive integer from the given environment variable, returning def if not set
+func IntFromEnv(env string, def int) (int, error) {
+	v := os.Getenv(env)
+	if v == "" {
+		return def, nil
+	}
+	i, err := strconv.Atoi(v)
+	if err != nil || i < 1 {
+		return def, fmt.Errorf("invalid %s \"%s\": expected a positive integer", env, v)
+	}
+	return i, nil
+}
+
+// NonNegativeIntFromEnv reads a positive integer or 0 from the given environment variable, returning def if not set
+func NonNegativeIntFromEnv(env string, def int) (int, error) {
+	v := os.Getenv(env)
+	if v == "" {
+		return def, nil
+	}
+	i, err := strconv.Atoi(v)
+	if err != nil || i < 0 {
+		return def, fmt.Errorf("invalid %s \"%s\": expected a non negative integer", env, v)
+	}
+	return i, nil
+}
+
+// DurationFromEnv reads a positive duration from the given environment variable, returning def if not set
+func DurationFromEnv(env string, def time.Duration) (time.Duration, error) {
+	v := os.Getenv(env)
+	if v == "" {
+		return def, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		return def, fmt.Errorf("invalid %s \"%s\": expected a positive duration (e.g. 10s)", env, v)
+	}
+	return d, nil
+}
+
+// NonNegativeDurationFromEnv reads a positive or zero duration from the given environment variable, returning def if
+// not set
+func NonNegativeDurationFromEnv(env string, def time.Duration) (time.Duration, error) {
+	v := os.Getenv(env)
+	if v == "" {
+		return def, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		return def, fmt.Errorf("invalid %s \"%s\": expected a non negative duration (e.g. 10s)", env, v)
+	}
+	return d, nil
+}
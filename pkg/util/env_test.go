@@ -0,0 +1,44 @@
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("TEST_LIST", " a, b,,c ")
+	assert.Equal(t, []string{"a", "b", "c"}, ListFromEnv("TEST_LIST"))
+	assert.Equal(t, []string{}, ListFromEnv("TEST_UNSET"))
+
+	t.Setenv("TEST_BOOL", "false")
+	b, err := BoolFromEnv("TEST_BOOL", true)
+	assert.Nil(t, err)
+	assert.False(t, b)
+	t.Setenv("TEST_BOOL", "nope")
+	_, err = BoolFromEnv("TEST_BOOL", true)
+	assert.NotNil(t, err)
+
+	t.Setenv("TEST_INT", "0")
+	_, err = IntFromEnv("TEST_INT", 1)
+	assert.NotNil(t, err)
+	i, err := NonNegativeIntFromEnv("TEST_INT", 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, i)
+	t.Setenv("TEST_INT", "-1")
+	_, err = NonNegativeIntFromEnv("TEST_INT", 1)
+	assert.NotNil(t, err)
+
+	t.Setenv("TEST_DURATION", "3s")
+	d, err := DurationFromEnv("TEST_DURATION", time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, 3*time.Second, d)
+	d, err = DurationFromEnv("TEST_UNSET", time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, time.Second, d)
+	t.Setenv("TEST_DURATION", "0s")
+	d, err = NonNegativeDurationFromEnv("TEST_DURATION", time.Second)
+	assert.Nil(t, err)
+	assert.Zero(t, d)
+}
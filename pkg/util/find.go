@@ -136,7 +136,7 @@ func GetServiceAccountData(ctx context.Context, name, ns string, c client.Client
 	return "", "", fmt.Errorf("no secret for service account %s was found on namespace %s", name, ns)
 }
 
-func GetSpinnakerServices(list interfaces.SpinnakerServiceList, ns string, c client.Client) ([]interfaces.SpinnakerService, error) {
+func GetSpinnakerServices(list interfaces.SpinnakerServiceList, ns string, c client.Reader) ([]interfaces.SpinnakerService, error) {
 	var opts client.ListOption
 	opts = client.InNamespace(ns)
 	err := c.List(context.TODO(), list, opts)
@@ -18,6 +18,7 @@ const (
 	POST   HttpMethod = "POST"
 	PUT    HttpMethod = "PUT"
 	DELETE HttpMethod = "DELETE"
+	HEAD   HttpMethod = "HEAD"
 )
 
 func (s *HttpService) Request(ctx context.Context, method HttpMethod, url string, requestParams map[string]string, headers map[string]string, body io.Reader) (*http.Request, error) {
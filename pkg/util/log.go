@@ -0,0 +1,164 @@
+package util
+
+import (
+	"encoding/json"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/go-logr/logr"
+)
+
+// LogVerbosityEnv sets the V-level up to which webhook and validation messages are logged
+const LogVerbosityEnv = "WEBHOOK_LOG_VERBOSITY"
+
+const redacted = "**REDACTED**"
+
+// sensitiveKeys are substrings of keys whose values are never logged
+var sensitiveKeys = []string{"password", "token", "secret", "credential", "privatekey", "kubeconfig", "authorization", "keydata", "certdata", "certificatedata", "cadata", "apikey"}
+
+// VerboseLogger returns a logger logging messages up to the V-level read from LogVerbosityEnv (0 if unset or
+// invalid) at info level, so they are output without changing the level of the whole operator.
+// Values of sensitive keys are redacted at all levels.
+func VerboseLogger(l logr.Logger) logr.Logger {
+	v, err := strconv.Atoi(os.Getenv(LogVerbosityEnv))
+	if err != nil || v < 0 {
+		v = 0
+	}
+	return NewVerboseLogger(l, v)
+}
+
+// NewVerboseLogger returns a logger logging messages up to the given V-level at info level, redacting sensitive values
+func NewVerboseLogger(l logr.Logger, verbosity int) logr.Logger {
+	return logr.New(&verbositySink{LogSink: l.GetSink(), verbosity: verbosity})
+}
+
+type verbositySink struct {
+	logr.LogSink
+	verbosity int
+}
+
+func (s *verbositySink) Enabled(level int) bool {
+	return level <= s.verbosity && s.LogSink.Enabled(0)
+}
+
+func (s *verbositySink) Info(_ int, msg string, keysAndValues ...interface{}) {
+	s.LogSink.Info(0, msg, redactKeysAndValues(keysAndValues)...)
+}
+
+func (s *verbositySink) Error(err error, msg string, keysAndValues ...interface{}) {
+	s.LogSink.Error(err, msg, redactKeysAndValues(keysAndValues)...)
+}
+
+func (s *verbositySink) WithValues(keysAndValues ...interface{}) logr.LogSink {
+	return &verbositySink{LogSink: s.LogSink.WithValues(redactKeysAndValues(keysAndValues)...), verbosity: s.verbosity}
+}
+
+func (s *verbositySink) WithName(name string) logr.LogSink {
+	return &verbositySink{LogSink: s.LogSink.WithName(name), verbosity: s.verbosity}
+}
+
+// RedactJSON parses the given JSON document and returns it with the values of sensitive keys redacted,
+// for logging full objects.
+func RedactJSON(raw []byte) interface{} {
+	var obj interface{}
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		return redacted
+	}
+	return redactValue(obj)
+}
+
+func redactKeysAndValues(kv []interface{}) []interface{} {
+	out := make([]interface{}, len(kv))
+	for i := range kv {
+		if i%2 == 1 {
+			if k, ok := kv[i-1].(string); ok && isSensitiveKey(k) {
+				out[i] = redacted
+				continue
+			}
+			out[i] = redactValue(kv[i])
+			continue
+		}
+		out[i] = kv[i]
+	}
+	return out
+}
+
+func redactValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, e := range t {
+			if isSensitiveKey(k) {
+				m[k] = redacted
+			} else {
+				m[k] = redactValue(e)
+			}
+		}
+		return m
+	case []interface{}:
+		l := make([]interface{}, len(t))
+		for i, e := range t {
+			l[i] = redactValue(e)
+		}
+		return l
+	case map[string]string:
+		m := make(map[string]string, len(t))
+		for k, e := range t {
+			if isSensitiveKey(k) {
+				m[k] = redacted
+			} else {
+				m[k] = e
+			}
+		}
+		return m
+	}
+	return v
+}
+
+func isSensitiveKey(k string) bool {
+	k = strings.ToLower(strings.NewReplacer("-", "", "_", "").Replace(k))
+	for _, s := range sensitiveKeys {
+		if strings.Contains(k, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// SensitiveValues returns the string values of sensitive keys in the given JSON document, to redact them from
+// messages built from the document with RedactValues.
+func SensitiveValues(raw []byte) []string {
+	var obj interface{}
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		return nil
+	}
+	var values []string
+	collectSensitiveValues(obj, false, &values)
+	return values
+}
+
+func collectSensitiveValues(v interface{}, sensitive bool, values *[]string) {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, e := range t {
+			collectSensitiveValues(e, sensitive || isSensitiveKey(k), values)
+		}
+	case []interface{}:
+		for _, e := range t {
+			collectSensitiveValues(e, sensitive, values)
+		}
+	case string:
+		if sensitive && t != "" {
+			*values = append(*values, t)
+		}
+	}
+}
+
+// RedactValues replaces the given values in s
+func RedactValues(s string, values []string) string {
+	for _, v := range values {
+		s = strings.ReplaceAll(s, v, redacted)
+	}
+	return s
+}
@@ -0,0 +1,79 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/go-logr/logr/funcr"
+	"github.com/stretchr/testify/assert"
+)
+
+func newCapturingLogger(lines *[]string) logr.Logger {
+	return funcr.New(func(prefix, args string) {
+		*lines = append(*lines, prefix+" "+args)
+	}, funcr.Options{})
+}
+
+func TestVerboseLoggerLevels(t *testing.T) {
+	var lines []string
+	l := NewVerboseLogger(newCapturingLogger(&lines), 2)
+	l.Info("level 0")
+	l.V(2).Info("level 2")
+	l.V(3).Info("level 3")
+	assert.Equal(t, 2, len(lines))
+	assert.Contains(t, lines[1], "level 2")
+}
+
+func TestVerboseLoggerFromEnv(t *testing.T) {
+	var lines []string
+	t.Setenv(LogVerbosityEnv, "1")
+	l := VerboseLogger(newCapturingLogger(&lines))
+	l.V(1).Info("level 1")
+	l.V(2).Info("level 2")
+	assert.Equal(t, 1, len(lines))
+
+	t.Setenv(LogVerbosityEnv, "loud")
+	lines = nil
+	l = VerboseLogger(newCapturingLogger(&lines))
+	l.Info("level 0")
+	l.V(1).Info("level 1")
+	assert.Equal(t, 1, len(lines))
+}
+
+func TestVerboseLoggerRedactsSecrets(t *testing.T) {
+	const secret = "s3cr3t-value"
+	object := []byte(fmt.Sprintf(`{"metadata":{"name":"kube"},"spec":{"kubernetes":{"kubeconfig":{"users":[{"user":{"token":"%s"}}]}},"settings":{"password":"%s","nested":[{"client-key-data":"%s"}]}}}`, secret, secret, secret))
+
+	for level := 0; level <= 3; level++ {
+		t.Run(fmt.Sprintf("level %d", level), func(t *testing.T) {
+			var lines []string
+			l := NewVerboseLogger(newCapturingLogger(&lines), 3)
+			l.V(level).Info("admission request", "object", RedactJSON(object), "token", secret)
+			l.V(level).WithValues("password", secret).Info("with values")
+			l.V(level).Error(errors.New("failed"), "validation failed", "settings", map[string]interface{}{"apiKey": secret, "region": "us-west-2"})
+			l.V(level).Info("labels", "annotations", map[string]string{"bearer-token": secret})
+
+			assert.Equal(t, 4, len(lines))
+			for _, line := range lines {
+				assert.NotContains(t, line, secret)
+				assert.Contains(t, line, redacted)
+			}
+			assert.True(t, strings.Contains(lines[0], `"name":"kube"`))
+			assert.True(t, strings.Contains(lines[2], `"region":"us-west-2"`))
+		})
+	}
+}
+
+func TestRedactJSONInvalid(t *testing.T) {
+	assert.Equal(t, redacted, RedactJSON([]byte("not json")))
+}
+
+func TestRedactValues(t *testing.T) {
+	raw := []byte(`{"name":"kube","settings":{"password":"hunter2","auth":{"tokens":["t1","t2"]},"region":"us-west-2"}}`)
+	values := SensitiveValues(raw)
+	assert.ElementsMatch(t, []string{"hunter2", "t1", "t2"}, values)
+	assert.Equal(t, "login with "+redacted+" in us-west-2 failed", RedactValues("login with hunter2 in us-west-2 failed", values))
+}
@@ -7,10 +7,12 @@ import (
 	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
 	apiAdmissionregistrationv1 "k8s.io/api/admissionregistration/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
 	"net/url"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"strconv"
@@ -174,20 +176,43 @@ func CreateOrUpdateService(svc *corev1.Service, rawClient *kubernetes.Clientset)
 	return err
 }
 
-func CreateOrUpdateValidatingWebhookConfiguration(config *apiAdmissionregistrationv1.ValidatingWebhookConfiguration, rawClient *kubernetes.Clientset) error {
+// CreateOrUpdateValidatingWebhookConfiguration creates the configuration, or replaces the webhooks of the existing one
+// so that webhooks and rules no longer desired are removed. Webhooks without a CA bundle keep the bundle of the
+// existing webhook of the same name, e.g. when it's injected by cert-manager.
+func CreateOrUpdateValidatingWebhookConfiguration(config *apiAdmissionregistrationv1.ValidatingWebhookConfiguration, rawClient kubernetes.Interface) error {
 	c := rawClient.AdmissionregistrationV1().ValidatingWebhookConfigurations()
-	_, err := c.Get(context.TODO(), config.Name, v1.GetOptions{})
-	if err != nil {
-		if !errors.IsNotFound(err) {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		existing, err := c.Get(context.TODO(), config.Name, v1.GetOptions{})
+		if err != nil {
+			if !errors.IsNotFound(err) {
+				return err
+			}
+			_, err := c.Create(context.TODO(), config, v1.CreateOptions{})
 			return err
 		}
-		_, err := c.Create(context.TODO(), config, v1.CreateOptions{})
-		return err
-	}
-	data, err := json.Marshal(config)
-	if err != nil {
+		updated := existing.DeepCopy()
+		for k, v := range config.Annotations {
+			if updated.Annotations == nil {
+				updated.Annotations = map[string]string{}
+			}
+			updated.Annotations[k] = v
+		}
+		bundles := map[string][]byte{}
+		for _, w := range existing.Webhooks {
+			bundles[w.Name] = w.ClientConfig.CABundle
+		}
+		updated.Webhooks = make([]apiAdmissionregistrationv1.ValidatingWebhook, 0, len(config.Webhooks))
+		for _, w := range config.Webhooks {
+			w = *w.DeepCopy()
+			if len(w.ClientConfig.CABundle) == 0 {
+				w.ClientConfig.CABundle = bundles[w.Name]
+			}
+			updated.Webhooks = append(updated.Webhooks, w)
+		}
+		if equality.Semantic.DeepEqual(existing, updated) {
+			return nil
+		}
+		_, err = c.Update(context.TODO(), updated, v1.UpdateOptions{})
 		return err
-	}
-	_, err = c.Patch(context.TODO(), config.Name, types.MergePatchType, data, v1.PatchOptions{})
-	return err
+	})
 }
@@ -0,0 +1,69 @@
+package validate
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// addressForm is the form a provider expects an account address in
+type addressForm struct {
+	// example is shown in errors as the expected form
+	example string
+	// schemes are the schemes the address can use, it must be a host[:port] without scheme if empty
+	schemes []string
+	// schemeOptional also accepts a host[:port] without scheme
+	schemeOptional bool
+	// pathAllowed accepts addresses with a path
+	pathAllowed bool
+}
+
+var (
+	// dockerAddressForm is the form of registry addresses, Clouddriver defaults to https and adds the /v2/ API path
+	dockerAddressForm = addressForm{example: "index.docker.io or https://index.docker.io", schemes: []string{"https", "http"}, schemeOptional: true}
+	// cloudFoundryURIForm is the form of the apps manager and metrics URIs of Cloud Foundry accounts
+	cloudFoundryURIForm = addressForm{example: "https://apps.sys.example.com", schemes: []string{"https", "http"}, pathAllowed: true}
+)
+
+// checkAddress returns an error if the address isn't in the expected form, along with the correct form
+func checkAddress(field, address string, form addressForm) error {
+	if err := form.check(address); err != nil {
+		return fmt.Errorf("invalid %s \"%s\": %s, expected e.g. %s", field, address, err.Error(), form.example)
+	}
+	return nil
+}
+
+func (f addressForm) check(address string) error {
+	if strings.TrimSpace(address) != address || strings.ContainsAny(address, " \t\n") {
+		return fmt.Errorf("address must not contain whitespace")
+	}
+	scheme, rest := "", address
+	if i := strings.Index(address, "://"); i >= 0 {
+		scheme, rest = address[:i], address[i+3:]
+	}
+	switch {
+	case scheme == "" && len(f.schemes) > 0 && !f.schemeOptional:
+		return fmt.Errorf("scheme %s is missing", strings.Join(f.schemes, " or "))
+	case scheme != "" && len(f.schemes) == 0:
+		return fmt.Errorf("scheme %s must be removed", scheme)
+	case scheme != "" && !containsFold(f.schemes, scheme):
+		return fmt.Errorf("scheme %s is not supported, use %s", scheme, strings.Join(f.schemes, " or "))
+	}
+	u, err := url.Parse("//" + rest)
+	if err != nil || u.Host == "" || u.User != nil || u.RawQuery != "" || u.Fragment != "" {
+		return fmt.Errorf("host is invalid")
+	}
+	if !f.pathAllowed && strings.Trim(u.Path, "/") != "" {
+		return fmt.Errorf("path %s must be removed", u.Path)
+	}
+	return nil
+}
+
+func containsFold(list []string, s string) bool {
+	for _, l := range list {
+		if strings.EqualFold(l, s) {
+			return true
+		}
+	}
+	return false
+}
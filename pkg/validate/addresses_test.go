@@ -0,0 +1,59 @@
+package validate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckAddress(t *testing.T) {
+	hostForm := addressForm{example: "api.example.com"}
+	cases := []struct {
+		name     string
+		address  string
+		form     addressForm
+		expected string
+	}{
+		{"docker host", "index.docker.io", dockerAddressForm, ""},
+		{"docker URL", "https://registry.example.com:5000", dockerAddressForm, ""},
+		{"docker unsupported scheme", "docker://index.docker.io", dockerAddressForm,
+			`invalid address "docker://index.docker.io": scheme docker is not supported, use https or http, expected e.g. index.docker.io or https://index.docker.io`},
+		{"docker path", "https://index.docker.io/v2/", dockerAddressForm,
+			`invalid address "https://index.docker.io/v2/": path /v2/ must be removed, expected e.g. index.docker.io or https://index.docker.io`},
+		{"host with scheme", "https://api.example.com", hostForm,
+			`invalid address "https://api.example.com": scheme https must be removed, expected e.g. api.example.com`},
+		{"URL without scheme", "apps.sys.example.com", cloudFoundryURIForm,
+			`invalid address "apps.sys.example.com": scheme https or http is missing, expected e.g. https://apps.sys.example.com`},
+		{"URL", "https://apps.sys.example.com/metrics", cloudFoundryURIForm, ""},
+		{"no host", "https://", dockerAddressForm,
+			`invalid address "https://": host is invalid, expected e.g. index.docker.io or https://index.docker.io`},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := checkAddress("address", c.address, c.form)
+			if c.expected == "" {
+				assert.Nil(t, err)
+			} else if assert.NotNil(t, err) {
+				assert.Equal(t, c.expected, err.Error())
+			}
+		})
+	}
+}
+
+func Test_dockerRegistryValidator_Validate_Registry_Address(t *testing.T) {
+	spinsvc, err := getSpinnakerService()
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	ok, errs := (&dockerRegistryValidator{}).validateRegistry(dockerRegistryAccount{Name: "registry", Address: "htps://index.docker.io"}, context.TODO(), spinsvc)
+	assert.False(t, ok)
+	if assert.Len(t, errs, 1) {
+		assert.Equal(t, `error validating docker account "registry": invalid address "htps://index.docker.io": scheme htps is not supported, use https or http, expected e.g. index.docker.io or https://index.docker.io`, errs[0].Error())
+	}
+
+	ok, errs = (&dockerRegistryValidator{}).validateRegistry(dockerRegistryAccount{Name: "registry", Address: "index.docker.io"}, context.TODO(), spinsvc)
+	assert.True(t, ok)
+	assert.Empty(t, errs)
+}
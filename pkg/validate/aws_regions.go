@@ -0,0 +1,103 @@
+package validate
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/armory/spinnaker-operator/pkg/accounts/account"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+// awsRuntimeRegionsEnv fetches the AWS regions from the EC2 API instead of using the regions bundled with the SDK
+const awsRuntimeRegionsEnv = "AWS_RUNTIME_REGIONS"
+
+// awsRegions is shared by all validations so regions are only fetched once
+var awsRegions = &regionList{bundled: bundledAWSRegions, fetch: describeAWSRegions}
+
+// regionFetchRetry is how long the bundled regions are used after regions couldn't be fetched
+const regionFetchRetry = 5 * time.Minute
+
+// regionList returns the regions of a provider, fetching them at runtime when enabled. Fetched regions are cached
+// and added to the bundled ones, which cover the partitions the fetch doesn't.
+type regionList struct {
+	bundled func() []string
+	fetch   func(ctx context.Context) ([]string, error)
+	mu      sync.Mutex
+	fetched []string
+	// failed is when regions last couldn't be fetched
+	failed   time.Time
+	fetching bool
+}
+
+// regions returns the known regions, fetching them if runtime is true and connectivity is enabled for the provider.
+// fetched is false when only the bundled regions are returned, they may miss regions launched after the SDK release.
+func (l *regionList) regions(ctx context.Context, provider string, runtime bool) (known map[string]bool, fetched bool) {
+	known = map[string]bool{}
+	for _, name := range l.bundled() {
+		known[name] = true
+	}
+	if runtime && account.ProviderConnectivityEnabled(ctx, provider) {
+		if r := l.runtimeRegions(ctx); r != nil {
+			for _, name := range r {
+				known[name] = true
+			}
+			fetched = true
+		}
+	}
+	return known, fetched
+}
+
+// runtimeRegions returns the fetched regions, or nil while they are being fetched or after a recent failure
+func (l *regionList) runtimeRegions(ctx context.Context) []string {
+	l.mu.Lock()
+	if l.fetched != nil || l.fetching || time.Since(l.failed) < regionFetchRetry {
+		defer l.mu.Unlock()
+		return l.fetched
+	}
+	l.fetching = true
+	l.mu.Unlock()
+
+	names, err := l.fetch(ctx)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.fetching = false
+	if err != nil || len(names) == 0 {
+		l.failed = time.Now()
+		return nil
+	}
+	l.fetched = names
+	return l.fetched
+}
+
+// bundledAWSRegions returns the regions of all partitions known to the SDK
+func bundledAWSRegions() []string {
+	names := make([]string, 0)
+	for _, p := range endpoints.DefaultPartitions() {
+		for id := range p.Regions() {
+			names = append(names, id)
+		}
+	}
+	return names
+}
+
+// describeAWSRegions fetches the regions of the operator's partition with the operator's credentials
+func describeAWSRegions(ctx context.Context) ([]string, error) {
+	sess, err := session.NewSession(&aws.Config{Region: aws.String(endpoints.UsEast1RegionID)})
+	if err != nil {
+		return nil, err
+	}
+	out, err := ec2.New(sess).DescribeRegionsWithContext(ctx, &ec2.DescribeRegionsInput{AllRegions: aws.Bool(true)})
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(out.Regions))
+	for _, r := range out.Regions {
+		names = append(names, aws.StringValue(r.RegionName))
+	}
+	return names, nil
+}
@@ -1,7 +1,12 @@
 package validate
 
 import (
+	"errors"
+	"fmt"
+
+	"github.com/armory/spinnaker-operator/pkg/accounts/account"
 	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
+	"github.com/armory/spinnaker-operator/pkg/util"
 	"github.com/mitchellh/mapstructure"
 )
 
@@ -12,6 +17,7 @@ const (
 )
 
 type AwsAccount struct {
+	Name           string             `json:"name,omitempty"`
 	DefaultKeyPair string             `json:"defaultKeyPair,omitempty"`
 	Edda           string             `json:"edda,omitempty"`
 	Discovery      string             `json:"discovery,omitempty"`
@@ -29,6 +35,8 @@ type AwsRegion struct {
 
 type awsAccountValidator struct {
 	awsLifecycleHookValidation awsLifecycleHookValidation
+	// regions defaults to awsRegions
+	regions *regionList
 }
 
 func (d *awsAccountValidator) Validate(spinSvc interfaces.SpinnakerService, options Options) ValidationResult {
@@ -48,16 +56,46 @@ func (d *awsAccountValidator) Validate(spinSvc interfaces.SpinnakerService, opti
 		return ValidationResult{}
 	}
 
+	runtimeRegions, err := util.BoolFromEnv(awsRuntimeRegionsEnv, false)
+	if err != nil {
+		return NewResultFromError(err, true)
+	}
+	regions := d.regions
+	if regions == nil {
+		regions = awsRegions
+	}
+	known, fetched := regions.regions(options.Ctx, awsAccountType, runtimeRegions)
+
 	for _, a := range awsAccounts {
 		var awsAccount AwsAccount
 		if err := mapstructure.Decode(a, &awsAccount); err != nil {
 			return NewResultFromError(err, true)
 		}
+		if err := checkAwsAccountID(awsAccount); err != nil {
+			return NewResultFromError(err, true)
+		}
 		for _, hook := range awsAccount.LifecycleHooks {
 			if errs := d.awsLifecycleHookValidation.validate(hook); errs != nil && len(errs) > 0 {
 				return NewResultFromErrors(errs, true)
 			}
 		}
+		for _, r := range awsAccount.Regions {
+			if known[r.Name] {
+				continue
+			}
+			if fetched {
+				account.Warn(options.Ctx, "aws account %s uses unknown region %s", awsAccount.AccountId, r.Name)
+				continue
+			}
+			account.Warn(options.Ctx, "aws account %s uses region %s which is not bundled with the operator, set %s to check it against the regions of your partition", awsAccount.AccountId, r.Name, awsRuntimeRegionsEnv)
+		}
+		if awsAccount.AssumeRole != "" && awsAccount.ExternalId == "" {
+			msg := fmt.Sprintf("aws account %s assumes role %s without an external ID, roles trusting other accounts usually require one", awsAccount.Name, awsAccount.AssumeRole)
+			if account.Strict(options.Ctx) {
+				return NewResultFromError(errors.New(msg), true)
+			}
+			account.Warn(options.Ctx, msg)
+		}
 	}
 
 	return ValidationResult{}
@@ -2,10 +2,13 @@ package validate
 
 import (
 	"context"
+	"fmt"
+	"github.com/armory/spinnaker-operator/pkg/accounts/account"
 	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
 	"github.com/armory/spinnaker-operator/pkg/test"
 	"github.com/stretchr/testify/assert"
 	"testing"
+	"time"
 )
 
 func Test_AwsAccountShouldPass(t *testing.T) {
@@ -54,3 +57,153 @@ func Test_AwsAccountValidationEnabled_Provider_Not_Enabled(t *testing.T) {
 	// then
 	assert.Equal(t, false, validate)
 }
+
+func Test_AwsAccountUnknownRegion(t *testing.T) {
+	spinsvc := test.ManifestFileToSpinService("testdata/spinvc_aws.yml", t)
+	t.Setenv(awsRuntimeRegionsEnv, "true")
+	regions := &regionList{
+		bundled: func() []string { return []string{"us-gov-west-1"} },
+		fetch: func(ctx context.Context) ([]string, error) {
+			return []string{"us-east-1"}, nil
+		},
+	}
+	awsValidator := awsAccountValidator{regions: regions}
+	ctx := account.NewValidationContext(context.TODO(), account.ValidationOptions{Connectivity: true})
+
+	result := awsValidator.Validate(spinsvc, Options{Ctx: ctx})
+
+	assert.Empty(t, result.Errors)
+	vc, _ := account.ValidationContextFrom(ctx)
+	assert.Contains(t, vc.Warnings(), "aws account 111111111111 uses unknown region us-west-2")
+}
+
+func Test_AwsAccountRegionNotBundled(t *testing.T) {
+	spinsvc := test.ManifestFileToSpinService("testdata/spinvc_aws.yml", t)
+	regions := &regionList{
+		bundled: func() []string { return []string{"us-east-1"} },
+	}
+	awsValidator := awsAccountValidator{regions: regions}
+	ctx := account.NewValidationContext(context.TODO(), account.ValidationOptions{})
+
+	result := awsValidator.Validate(spinsvc, Options{Ctx: ctx})
+
+	assert.Empty(t, result.Errors)
+	vc, _ := account.ValidationContextFrom(ctx)
+	assert.Contains(t, vc.Warnings(), "aws account 111111111111 uses region us-west-2 which is not bundled with the operator, set AWS_RUNTIME_REGIONS to check it against the regions of your partition")
+}
+
+func Test_RegionListRuntimeRegions(t *testing.T) {
+	fetches := 0
+	newList := func(err error) *regionList {
+		fetches = 0
+		return &regionList{
+			bundled: func() []string { return []string{"us-east-1"} },
+			fetch: func(ctx context.Context) ([]string, error) {
+				fetches++
+				return []string{"xx-new-1"}, err
+			},
+		}
+	}
+
+	regions := func(l *regionList, ctx context.Context, runtime bool) (map[string]bool, bool) {
+		return l.regions(ctx, "aws", runtime)
+	}
+	bundled := map[string]bool{"us-east-1": true}
+	runtime := map[string]bool{"us-east-1": true, "xx-new-1": true}
+
+	l := newList(nil)
+	known, fetched := regions(l, context.TODO(), false)
+	assert.Equal(t, bundled, known)
+	assert.False(t, fetched)
+	known, fetched = regions(l, context.TODO(), true)
+	assert.Equal(t, runtime, known)
+	assert.True(t, fetched)
+	known, _ = regions(l, context.TODO(), true)
+	assert.Equal(t, runtime, known)
+	assert.Equal(t, 1, fetches)
+
+	l = newList(fmt.Errorf("no credentials"))
+	known, fetched = regions(l, context.TODO(), true)
+	assert.Equal(t, bundled, known)
+	assert.False(t, fetched)
+	// failures are cached
+	regions(l, context.TODO(), true)
+	assert.Equal(t, 1, fetches)
+	l.failed = time.Now().Add(-regionFetchRetry)
+	regions(l, context.TODO(), true)
+	assert.Equal(t, 2, fetches)
+
+	l = newList(nil)
+	ctx := account.NewValidationContext(context.TODO(), account.ValidationOptions{Connectivity: false})
+	known, fetched = regions(l, ctx, true)
+	assert.Equal(t, bundled, known)
+	assert.False(t, fetched)
+	assert.Equal(t, 0, fetches)
+}
+
+func Test_AwsAccountExternalId(t *testing.T) {
+	cases := []struct {
+		name     string
+		account  map[string]interface{}
+		strict   bool
+		warnings []string
+		err      string
+	}{
+		{"assumeRole with externalId", map[string]interface{}{"name": "test", "assumeRole": "role/spinnaker", "externalId": "abc"}, true, nil, ""},
+		{"assumeRole without externalId", map[string]interface{}{"name": "test", "assumeRole": "role/spinnaker"}, false,
+			[]string{"aws account test assumes role role/spinnaker without an external ID, roles trusting other accounts usually require one"}, ""},
+		{"assumeRole without externalId in strict mode", map[string]interface{}{"name": "test", "assumeRole": "role/spinnaker"}, true, nil,
+			"aws account test assumes role role/spinnaker without an external ID, roles trusting other accounts usually require one"},
+		{"no assumeRole", map[string]interface{}{"name": "test"}, true, nil, ""},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			spinsvc := test.ManifestFileToSpinService("testdata/spinvc_aws.yml", t)
+			if !assert.Nil(t, spinsvc.GetSpinnakerConfig().SetHalConfigProp(awsAccountsKey, []interface{}{c.account})) {
+				return
+			}
+			ctx := account.NewValidationContext(context.TODO(), account.ValidationOptions{Strict: c.strict})
+			result := (&awsAccountValidator{}).Validate(spinsvc, Options{Ctx: ctx})
+			vc, _ := account.ValidationContextFrom(ctx)
+			if c.warnings == nil {
+				assert.Empty(t, vc.Warnings())
+			} else {
+				assert.Equal(t, c.warnings, vc.Warnings())
+			}
+			if c.err == "" {
+				assert.Empty(t, result.Errors)
+			} else if assert.Len(t, result.Errors, 1) {
+				assert.Equal(t, c.err, result.Errors[0].Error())
+			}
+		})
+	}
+}
+
+func Test_AwsAccountIdFormat(t *testing.T) {
+	cases := []struct {
+		name      string
+		accountId string
+		err       string
+	}{
+		{"12 digits", "012345678901", ""},
+		{"too short", "11111111", "aws account test has invalid account ID \"11111111\": expected 12 digits, e.g. 123456789012"},
+		{"letters", "12345678901a", "aws account test has invalid account ID \"12345678901a\": expected 12 digits, e.g. 123456789012"},
+		{"dashes", "1234-5678-9012", "aws account test has invalid account ID \"1234-5678-9012\": expected 12 digits, e.g. 123456789012"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			spinsvc := test.ManifestFileToSpinService("testdata/spinvc_aws.yml", t)
+			acc := map[string]interface{}{"name": "test", "accountId": c.accountId}
+			if !assert.Nil(t, spinsvc.GetSpinnakerConfig().SetHalConfigProp(awsAccountsKey, []interface{}{acc})) {
+				return
+			}
+			result := (&awsAccountValidator{}).Validate(spinsvc, Options{Ctx: context.TODO()})
+			if c.err == "" {
+				assert.Empty(t, result.Errors)
+			} else if assert.Len(t, result.Errors, 1) {
+				assert.Equal(t, c.err, result.Errors[0].Error())
+				assert.True(t, result.Fatal)
+			}
+		})
+	}
+}
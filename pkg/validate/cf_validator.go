@@ -87,6 +87,15 @@ func (d *cloudFoundryValidator) validateAccount(cfAccount cloudFoundryAccount, c
 		return false, append(errs, err)
 	}
 
+	for _, u := range []struct{ field, uri string }{{"appsManagerUri", cfAccount.AppsManagerUri}, {"metricsUri", cfAccount.MetricsUri}} {
+		if u.uri == "" {
+			continue
+		}
+		if err := checkAddress(u.field, u.uri, cloudFoundryURIForm); err != nil {
+			return false, append(errs, fmt.Errorf("error validating cloudFoundry account \"%s\": %w", cfAccount.Name, err))
+		}
+	}
+
 	cfClient := NewCloudFoundryClient()
 	cfService := NewCloudFoundryService(cfClient)
 
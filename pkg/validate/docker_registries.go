@@ -0,0 +1,46 @@
+package validate
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/armory/spinnaker-operator/pkg/util"
+)
+
+// allowedRegistriesEnv lists the registry hostnames Docker accounts can use, e.g. "docker.io,*.example.com".
+// All registries are allowed if empty.
+const allowedRegistriesEnv = "DOCKER_ALLOWED_REGISTRIES"
+
+// checkRegistryAllowed returns an error if the registry's host isn't on the allowlist
+func checkRegistryAllowed(registry dockerRegistryAccount) error {
+	allowed := util.ListFromEnv(allowedRegistriesEnv)
+	if len(allowed) == 0 {
+		return nil
+	}
+	u, err := url.Parse(registry.GetAddress())
+	if err != nil {
+		return fmt.Errorf("error validating docker account \"%s\": invalid address \"%s\": %w", registry.Name, registry.Address, err)
+	}
+	host := u.Hostname()
+	if !isRegistryAllowed(host, allowed) {
+		return fmt.Errorf("error validating docker account \"%s\": registry \"%s\" is not allowed, allowed registries are %s", registry.Name, host, strings.Join(allowed, ", "))
+	}
+	return nil
+}
+
+// isRegistryAllowed matches the host against the allowlist, "*.example.com" matching any subdomain of example.com
+func isRegistryAllowed(host string, allowed []string) bool {
+	host = strings.ToLower(host)
+	for _, a := range allowed {
+		a = strings.ToLower(a)
+		if strings.HasPrefix(a, "*.") {
+			if strings.HasSuffix(host, a[1:]) {
+				return true
+			}
+		} else if host == a {
+			return true
+		}
+	}
+	return false
+}
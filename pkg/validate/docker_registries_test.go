@@ -0,0 +1,38 @@
+package validate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_dockerRegistryValidator_allowedRegistries(t *testing.T) {
+	tests := []struct {
+		name    string
+		address string
+		allowed bool
+	}{
+		{"allowed host", "https://index.docker.io", true},
+		{"wildcard match", "registry.example.com:5000", true},
+		{"wildcard doesn't match the parent domain", "example.com", false},
+		{"disallowed host", "quay.io", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(allowedRegistriesEnv, "index.docker.io, *.example.com")
+			registry := dockerRegistryAccount{Name: "registry", Address: tt.address}
+			ok, errs := (&dockerRegistryValidator{}).validateRegistry(registry, context.TODO(), nil)
+			assert.Equal(t, tt.allowed, ok)
+			if !tt.allowed && assert.Len(t, errs, 1) {
+				assert.Contains(t, errs[0].Error(), "is not allowed, allowed registries are index.docker.io, *.example.com")
+			}
+		})
+	}
+
+	t.Run("empty allowlist", func(t *testing.T) {
+		registry := dockerRegistryAccount{Name: "registry", Address: "quay.io"}
+		ok, _ := (&dockerRegistryValidator{}).validateRegistry(registry, context.TODO(), nil)
+		assert.True(t, ok)
+	})
+}
@@ -48,6 +48,22 @@ func (s *dockerRegistryService) GetTagsCount(image string) (int, error) {
 	return len(tags), nil
 }
 
+// HasRepository returns false if the registry doesn't know the given repository
+func (s *dockerRegistryService) HasRepository(repository string) (bool, error) {
+	params := make(map[string]string)
+	params["n"] = "1"
+	resp, err := s.client(fmt.Sprintf("/v2/%s/tags/list", repository), params)
+	if err != nil {
+		var se *dockerStatusError
+		if errors.As(err, &se) && se.statusCode == http.StatusNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	resp.Body.Close()
+	return true, nil
+}
+
 func (s *dockerRegistryService) client(path string, params map[string]string) (*http.Response, error) {
 	url := fmt.Sprintf("%s%s", s.address, path)
 
@@ -89,12 +105,15 @@ func (s *dockerRegistryService) client(path string, params map[string]string) (*
 		if err != nil {
 			return nil, err
 		}
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, &dockerStatusError{url: url, statusCode: resp.StatusCode}
+		}
 		if resp.StatusCode != 200 {
 			return nil, errors.New(fmt.Sprintf("Error with registry %s, for request '%s': %v HTTP status code", s.address, url, resp.StatusCode))
 		}
 		return resp, nil
 	} else {
-		return nil, errors.New(fmt.Sprintf("URL: %s returns %v HTTP status code", url, resp.StatusCode))
+		return nil, &dockerStatusError{url: url, statusCode: resp.StatusCode}
 	}
 
 }
@@ -177,3 +196,13 @@ func (s *dockerRegistryService) parseBearerAuthenticateHeader(bearer []string) m
 	}
 	return out
 }
+
+// dockerStatusError is returned when the registry answers with an unexpected HTTP status
+type dockerStatusError struct {
+	url        string
+	statusCode int
+}
+
+func (e *dockerStatusError) Error() string {
+	return fmt.Sprintf("URL: %s returns %v HTTP status code", e.url, e.statusCode)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	tools "github.com/armory/go-yaml-tools/pkg/secrets"
+	"github.com/armory/spinnaker-operator/pkg/accounts/account"
 	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
 	"github.com/armory/spinnaker-operator/pkg/inspect"
 	"github.com/armory/spinnaker-operator/pkg/secrets"
@@ -25,6 +26,8 @@ const (
 	dockerRegistryAccountsEnabledKey = "providers.dockerRegistry.enabled"
 	dockerRegistryAccountsKey        = "providers.dockerRegistry.accounts"
 	namePattern                      = "^[a-z0-9]+([-a-z0-9]*[a-z0-9])?$"
+	// maxProbedRepositories is the number of repositories of an account checked against the registry
+	maxProbedRepositories = 20
 )
 
 type dockerRegistryAccount struct {
@@ -104,6 +107,16 @@ func (d *dockerRegistryValidator) validateRegistry(registry dockerRegistryAccoun
 		return false, append(errs, err)
 	}
 
+	if registry.Address != "" {
+		if err := checkAddress("address", registry.Address, dockerAddressForm); err != nil {
+			return false, append(errs, fmt.Errorf("error validating docker account \"%s\": %w", registry.Name, err))
+		}
+	}
+
+	if err := checkRegistryAllowed(registry); err != nil {
+		return false, append(errs, err)
+	}
+
 	resolvedPassword := ""
 	passwordProvided := len(registry.Password) != 0
 	passwordCommandProvided := len(registry.PasswordCommand) != 0
@@ -181,6 +194,10 @@ func (d *dockerRegistryValidator) validateRegistry(registry dockerRegistryAccoun
 		}
 	}
 
+	if len(registry.Repositories) != 0 && account.ProviderConnectivityEnabled(ctx, "docker") {
+		registry.Repositories = d.exposedRepositories(ctx, registry, &service)
+	}
+
 	if len(registry.Repositories) != 0 {
 		v := newDockerRepoValidator(ctx)
 		repositoryErrors := v.repository(registry, &service)
@@ -197,6 +214,28 @@ func (d *dockerRegistryValidator) validateRegistry(registry dockerRegistryAccoun
 	return true, nil
 }
 
+// exposedRepositories checks at most maxProbedRepositories repositories of the account against the registry and
+// drops the ones it doesn't know. Missing repositories only raise warnings: registries sometimes restrict their
+// catalog so the check isn't reliable. Repositories that weren't checked are kept.
+func (d *dockerRegistryValidator) exposedRepositories(ctx context.Context, registry dockerRegistryAccount, service *dockerRegistryService) []string {
+	var exposed []string
+	for i, r := range registry.Repositories {
+		if i >= maxProbedRepositories || ctx.Err() != nil {
+			account.Warn(ctx, "docker account %s: only checked %d of %d repositories against registry %s", registry.Name, i, len(registry.Repositories), registry.GetAddress())
+			return append(exposed, registry.Repositories[i:]...)
+		}
+		ok, err := service.HasRepository(r)
+		if err != nil {
+			account.Warn(ctx, "docker account %s: unable to check repository %s against registry %s: %v", registry.Name, r, registry.GetAddress(), err)
+		} else if !ok {
+			account.Warn(ctx, "docker account %s: repository %s not found in registry %s", registry.Name, r, registry.GetAddress())
+			continue
+		}
+		exposed = append(exposed, r)
+	}
+	return exposed
+}
+
 type dockerRepositoryValidate struct {
 	ctx                 context.Context
 	repositoryValidator dockerRepositoryValidator
@@ -254,6 +293,9 @@ func (d *dockerRegistryValidator) loadPasswordFromFile(passwordFile string, ctx
 		if err != nil {
 			return "", err
 		}
+		if err := secrets.CheckNotEmpty(passwordFile, content); err != nil {
+			return "", err
+		}
 		return string(content), nil
 	} else if filepath.IsAbs(passwordFile) {
 		// if file path is absolute, it may already be a path decoded by secret engines
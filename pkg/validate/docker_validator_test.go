@@ -3,11 +3,15 @@ package validate
 import (
 	"context"
 	"fmt"
+	"github.com/armory/spinnaker-operator/pkg/accounts/account"
 	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
 	"github.com/armory/spinnaker-operator/pkg/util"
 	"github.com/ghodss/yaml"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -291,3 +295,100 @@ func Test_dockerRepositoryValidate_imageTags(t *testing.T) {
 		})
 	}
 }
+
+// newMockRegistry returns a registry exposing the given repositories with a single tag each
+func newMockRegistry(t *testing.T, requests *int, repositories ...string) *httptest.Server {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		for _, repo := range repositories {
+			if r.URL.Path == fmt.Sprintf("/v2/%s/tags/list", repo) {
+				fmt.Fprintf(w, `{"name":"%s","tags":["latest"]}`, repo)
+				return
+			}
+		}
+		http.Error(w, `{"errors":[{"code":"NAME_UNKNOWN"}]}`, http.StatusNotFound)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func Test_dockerRegistryValidator_Validate_Registry_Repositories(t *testing.T) {
+	spinsvc, err := getSpinnakerService()
+	if !assert.Nil(t, err) {
+		return
+	}
+	requests := 0
+	registry := newMockRegistry(t, &requests, "team/app", "team/api")
+	ctx := account.NewValidationContext(context.TODO(), account.ValidationOptions{Connectivity: true})
+
+	ok, errs := (&dockerRegistryValidator{}).validateRegistry(dockerRegistryAccount{
+		Name:         "registry",
+		Address:      registry.URL,
+		Repositories: []string{"team/app", "team/typo", "team/api"},
+	}, ctx, spinsvc)
+
+	assert.True(t, ok)
+	assert.Empty(t, errs)
+	vc, _ := account.ValidationContextFrom(ctx)
+	assert.Equal(t, []string{
+		fmt.Sprintf("docker account registry: repository team/typo not found in registry %s", registry.URL),
+	}, vc.Warnings())
+}
+
+func Test_dockerRegistryValidator_exposedRepositories_Limit(t *testing.T) {
+	requests := 0
+	var repositories []string
+	for i := 0; i < maxProbedRepositories+5; i++ {
+		repositories = append(repositories, fmt.Sprintf("repo%d", i))
+	}
+	registry := newMockRegistry(t, &requests, repositories...)
+	ctx := account.NewValidationContext(context.TODO(), account.ValidationOptions{Connectivity: true})
+	r := dockerRegistryAccount{Name: "registry", Address: registry.URL, Repositories: repositories}
+	service := &dockerRegistryService{address: registry.URL, httpService: util.HttpService{}, ctx: ctx}
+
+	exposed := (&dockerRegistryValidator{}).exposedRepositories(ctx, r, service)
+
+	assert.Equal(t, repositories, exposed)
+	assert.Equal(t, maxProbedRepositories, requests)
+	vc, _ := account.ValidationContextFrom(ctx)
+	if assert.Len(t, vc.Warnings(), 1) {
+		assert.True(t, strings.HasPrefix(vc.Warnings()[0], "docker account registry: only checked 20 of 25 repositories"))
+	}
+}
+
+func Test_dockerRegistryValidator_exposedRepositories_Timeout(t *testing.T) {
+	requests := 0
+	registry := newMockRegistry(t, &requests, "team/app")
+	ctx, cancel := context.WithCancel(account.NewValidationContext(context.TODO(), account.ValidationOptions{Connectivity: true}))
+	cancel()
+	r := dockerRegistryAccount{Name: "registry", Address: registry.URL, Repositories: []string{"team/app"}}
+	service := &dockerRegistryService{address: registry.URL, httpService: util.HttpService{}, ctx: ctx}
+
+	exposed := (&dockerRegistryValidator{}).exposedRepositories(ctx, r, service)
+
+	assert.Equal(t, []string{"team/app"}, exposed)
+	assert.Equal(t, 0, requests)
+}
+
+func Test_dockerRegistryValidator_exposedRepositories_Errors(t *testing.T) {
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/team/app/tags/list":
+			fmt.Fprint(w, `{"name":"team/app","tags":["latest"]}`)
+		case "/v2/team/flaky/tags/list":
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		default:
+			http.Error(w, `{"errors":[{"code":"NAME_UNKNOWN"}]}`, http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(registry.Close)
+	ctx := account.NewValidationContext(context.TODO(), account.ValidationOptions{Connectivity: true})
+	r := dockerRegistryAccount{Name: "registry", Address: registry.URL, Repositories: []string{"team/app", "team/typo", "team/flaky"}}
+	service := &dockerRegistryService{address: registry.URL, httpService: util.HttpService{}, ctx: ctx}
+
+	exposed := (&dockerRegistryValidator{}).exposedRepositories(ctx, r, service)
+
+	assert.Equal(t, []string{"team/app", "team/flaky"}, exposed)
+	vc, _ := account.ValidationContextFrom(ctx)
+	assert.Len(t, vc.Warnings(), 2)
+}
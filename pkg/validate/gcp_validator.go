@@ -0,0 +1,295 @@
+package validate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	tools "github.com/armory/go-yaml-tools/pkg/secrets"
+	"github.com/armory/spinnaker-operator/pkg/accounts/account"
+	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
+	"github.com/armory/spinnaker-operator/pkg/secrets"
+	"github.com/mitchellh/mapstructure"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+const (
+	googleAccountType        = "google"
+	googleAccountsEnabledKey = "providers.google.enabled"
+	googleAccountsKey        = "providers.google.accounts"
+	canaryEnabledKey         = "canary.enabled"
+	canaryIntegrationsKey    = "canary.serviceIntegrations"
+
+	cloudResourceManagerURL = "https://cloudresourcemanager.googleapis.com"
+	cloudStorageURL         = "https://storage.googleapis.com"
+	cloudPlatformScope      = "https://www.googleapis.com/auth/cloud-platform"
+)
+
+// googleRequiredPermissions are representative permissions Clouddriver needs on the project of the account
+var googleRequiredPermissions = []string{
+	"compute.firewalls.list",
+	"compute.forwardingRules.list",
+	"compute.images.list",
+	"compute.instanceGroupManagers.create",
+	"compute.instanceGroupManagers.list",
+	"compute.instanceTemplates.create",
+	"compute.instances.list",
+	"compute.networks.list",
+	"compute.subnetworks.list",
+	"iam.serviceAccounts.actAs",
+}
+
+type GoogleAccount struct {
+	Name     string `json:"name,omitempty"`
+	Project  string `json:"project,omitempty"`
+	JsonPath string `json:"jsonPath,omitempty"`
+}
+
+// googleCanaryAccount is an account of the google canary service integration, storing canary results in a bucket
+type googleCanaryAccount struct {
+	Name     string `json:"name,omitempty"`
+	Project  string `json:"project,omitempty"`
+	JsonPath string `json:"jsonPath,omitempty"`
+	Bucket   string `json:"bucket,omitempty"`
+}
+
+type googleAccountValidator struct {
+	// endpoint defaults to cloudResourceManagerURL
+	endpoint string
+	// storageEndpoint defaults to cloudStorageURL
+	storageEndpoint string
+	// newClient defaults to googleClient
+	newClient func(ctx context.Context, acc GoogleAccount) (*http.Client, error)
+}
+
+// Validate checks the project ID and JSON key of each Google account are well-formed and warns about the permissions
+// its service account is missing on the project, and about the buckets of google canary accounts that can't be
+// accessed. Permissions and buckets are only tested when connectivity is enabled.
+func (g *googleAccountValidator) Validate(spinSvc interfaces.SpinnakerService, options Options) ValidationResult {
+	if !spinSvc.GetSpinnakerValidation().IsProviderValidationEnabled(googleAccountType) {
+		return ValidationResult{}
+	}
+	g.checkCanaryBuckets(options.Ctx, spinSvc)
+
+	accountEnabled, err := spinSvc.GetSpinnakerConfig().GetHalConfigPropBool(googleAccountsEnabledKey, false)
+	if err != nil || !accountEnabled {
+		return ValidationResult{}
+	}
+
+	googleAccounts, err := spinSvc.GetSpinnakerConfig().GetHalConfigObjectArray(options.Ctx, googleAccountsKey)
+	if err != nil {
+		// Ignore, key or format don't match expectations
+		return ValidationResult{}
+	}
+
+	for _, a := range googleAccounts {
+		var googleAccount GoogleAccount
+		if err := mapstructure.Decode(a, &googleAccount); err != nil {
+			return NewResultFromError(err, true)
+		}
+		if err := checkGoogleProjectID(googleAccount); err != nil {
+			return NewResultFromError(err, true)
+		}
+		if googleAccount.JsonPath != "" {
+			if _, err := readGoogleKey(options.Ctx, googleAccount); err != nil {
+				return NewResultFromError(err, true)
+			}
+		}
+		if googleAccount.Project == "" || !account.ProviderConnectivityEnabled(options.Ctx, googleAccountType) {
+			continue
+		}
+		c, err := g.client(options.Ctx, googleAccount)
+		if err != nil {
+			account.Warn(options.Ctx, "unable to test the access of google account %s: %v", googleAccount.Name, err)
+			continue
+		}
+		g.checkPermissions(options.Ctx, c, googleAccount)
+	}
+	return ValidationResult{}
+}
+
+func (g *googleAccountValidator) client(ctx context.Context, acc GoogleAccount) (*http.Client, error) {
+	if g.newClient != nil {
+		return g.newClient(ctx, acc)
+	}
+	return googleClient(ctx, acc)
+}
+
+// checkCanaryBuckets tests the access of the accounts of the google canary service integration to their bucket when
+// connectivity is enabled. Accounts without a bucket are skipped.
+func (g *googleAccountValidator) checkCanaryBuckets(ctx context.Context, spinSvc interfaces.SpinnakerService) {
+	cfg := spinSvc.GetSpinnakerConfig()
+	if enabled, err := cfg.GetHalConfigPropBool(canaryEnabledKey, false); err != nil || !enabled {
+		return
+	}
+	integrations, err := cfg.GetHalConfigObjectArray(ctx, canaryIntegrationsKey)
+	if err != nil {
+		// Ignore, key or format don't match expectations
+		return
+	}
+	for _, i := range integrations {
+		var integration struct {
+			Name     string                `json:"name"`
+			Enabled  bool                  `json:"enabled"`
+			Accounts []googleCanaryAccount `json:"accounts"`
+		}
+		if err := mapstructure.Decode(i, &integration); err != nil || integration.Name != googleAccountType || !integration.Enabled {
+			continue
+		}
+		for _, a := range integration.Accounts {
+			if a.Bucket == "" || !account.ProviderConnectivityEnabled(ctx, googleAccountType) {
+				continue
+			}
+			c, err := g.client(ctx, GoogleAccount{Name: a.Name, Project: a.Project, JsonPath: a.JsonPath})
+			if err != nil {
+				account.Warn(ctx, "unable to test the access of google canary account %s: %v", a.Name, err)
+				continue
+			}
+			g.checkBucket(ctx, c, a)
+		}
+	}
+}
+
+// checkPermissions tests the required permissions on the account's project. A denied test means the service
+// account can't access the project at all and is reported apart from missing permissions.
+func (g *googleAccountValidator) checkPermissions(ctx context.Context, c *http.Client, acc GoogleAccount) {
+	granted, status, err := g.testIamPermissions(ctx, c, acc.Project, googleRequiredPermissions)
+	switch {
+	case status == http.StatusForbidden:
+		account.Warn(ctx, "google account %s is not allowed to test its IAM permissions on project %s, check the project exists and the service account has a role on it", acc.Name, acc.Project)
+		return
+	case err != nil:
+		account.Warn(ctx, "unable to test the IAM permissions of google account %s on project %s: %v", acc.Name, acc.Project, err)
+		return
+	}
+	missing := make([]string, 0)
+	for _, p := range googleRequiredPermissions {
+		if !granted[p] {
+			missing = append(missing, p)
+		}
+	}
+	if len(missing) > 0 {
+		account.Warn(ctx, "google account %s is missing permissions on project %s: %s", acc.Name, acc.Project, strings.Join(missing, ", "))
+	}
+}
+
+// testIamPermissions returns the permissions granted among the given ones, along with the HTTP status of the test
+func (g *googleAccountValidator) testIamPermissions(ctx context.Context, c *http.Client, project string, permissions []string) (map[string]bool, int, error) {
+	endpoint := g.endpoint
+	if endpoint == "" {
+		endpoint = cloudResourceManagerURL
+	}
+	body, err := json.Marshal(map[string][]string{"permissions": permissions})
+	if err != nil {
+		return nil, 0, err
+	}
+	u := fmt.Sprintf("%s/v1/projects/%s:testIamPermissions", strings.TrimSuffix(endpoint, "/"), url.PathEscape(project))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, resp.StatusCode, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, resp.StatusCode, fmt.Errorf("%s returned %d", u, resp.StatusCode)
+	}
+	var r struct {
+		Permissions []string `json:"permissions"`
+	}
+	if err := json.Unmarshal(b, &r); err != nil {
+		return nil, resp.StatusCode, err
+	}
+	granted := map[string]bool{}
+	for _, p := range r.Permissions {
+		granted[p] = true
+	}
+	return granted, resp.StatusCode, nil
+}
+
+// checkBucket warns if the bucket of the canary account doesn't exist or can't be accessed by the account
+func (g *googleAccountValidator) checkBucket(ctx context.Context, c *http.Client, acc googleCanaryAccount) {
+	endpoint := g.storageEndpoint
+	if endpoint == "" {
+		endpoint = cloudStorageURL
+	}
+	status, _, err := getGCS(ctx, c, fmt.Sprintf("%s/storage/v1/b/%s", strings.TrimSuffix(endpoint, "/"), url.PathEscape(acc.Bucket)))
+	switch {
+	case status == http.StatusNotFound:
+		account.Warn(ctx, "bucket %s of google canary account %s does not exist", acc.Bucket, acc.Name)
+	case status == http.StatusForbidden || status == http.StatusUnauthorized:
+		account.Warn(ctx, "google canary account %s is not allowed to access bucket %s, check its service account has a storage role on it", acc.Name, acc.Bucket)
+	case err != nil:
+		account.Warn(ctx, "unable to check bucket %s of google canary account %s: %v", acc.Bucket, acc.Name, err)
+	}
+}
+
+// getGCS returns the status and body of a GET request to the GCS JSON API, failing on statuses other than 200
+func getGCS(ctx context.Context, c *http.Client, u string) (int, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return 0, nil, err
+	}
+	resp, err := c.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return resp.StatusCode, b, fmt.Errorf("%s returned %d", u, resp.StatusCode)
+	}
+	return resp.StatusCode, b, nil
+}
+
+// readGoogleKey returns the JSON key of the account, checking it's a GCP key and not another credential
+func readGoogleKey(ctx context.Context, acc GoogleAccount) ([]byte, error) {
+	path := acc.JsonPath
+	if tools.IsEncryptedSecret(path) {
+		f, err := secrets.DecodeAsFile(ctx, path)
+		if err != nil {
+			return nil, err
+		}
+		path = f
+	}
+	key, err := secrets.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := secrets.CheckFormat(acc.JsonPath, key, secrets.GCPKeyFormat); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// googleClient returns a client authenticated with the account's JSON key, or the operator's default credentials
+func googleClient(ctx context.Context, acc GoogleAccount) (*http.Client, error) {
+	if acc.JsonPath == "" {
+		return google.DefaultClient(ctx, cloudPlatformScope)
+	}
+	key, err := readGoogleKey(ctx, acc)
+	if err != nil {
+		return nil, err
+	}
+	creds, err := google.CredentialsFromJSON(ctx, key, cloudPlatformScope)
+	if err != nil {
+		return nil, err
+	}
+	return oauth2.NewClient(ctx, creds.TokenSource), nil
+}
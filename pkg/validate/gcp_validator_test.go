@@ -0,0 +1,236 @@
+package validate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/armory/spinnaker-operator/pkg/accounts/account"
+	"github.com/armory/spinnaker-operator/pkg/secrets"
+	"github.com/armory/spinnaker-operator/pkg/test"
+	"github.com/stretchr/testify/assert"
+)
+
+// googleKeyContext returns a context resolving the JSON key of testdata/spinvc_google.yml
+func googleKeyContext() context.Context {
+	return secrets.NewContextWithOverrides(context.TODO(), nil, "ns1", map[string][]byte{
+		"gce/key.json": []byte(`{"type": "service_account", "project_id": "my-project", "private_key": "key"}`),
+	})
+}
+
+func Test_googleAccountValidator_permissions(t *testing.T) {
+	cases := []struct {
+		name     string
+		handler  http.HandlerFunc
+		warnings []string
+	}{
+		{
+			"partial permissions",
+			func(w http.ResponseWriter, r *http.Request) {
+				var req struct {
+					Permissions []string `json:"permissions"`
+				}
+				if r.URL.Path != "/v1/projects/my-project:testIamPermissions" || json.NewDecoder(r.Body).Decode(&req) != nil {
+					http.NotFound(w, r)
+					return
+				}
+				granted := make([]string, 0)
+				for _, p := range req.Permissions {
+					if p != "compute.instanceTemplates.create" && p != "iam.serviceAccounts.actAs" {
+						granted = append(granted, p)
+					}
+				}
+				json.NewEncoder(w).Encode(map[string][]string{"permissions": granted})
+			},
+			[]string{"google account gce is missing permissions on project my-project: compute.instanceTemplates.create, iam.serviceAccounts.actAs"},
+		},
+		{
+			"all permissions",
+			func(w http.ResponseWriter, r *http.Request) {
+				json.NewEncoder(w).Encode(map[string][]string{"permissions": googleRequiredPermissions})
+			},
+			nil,
+		},
+		{
+			"not allowed to test permissions",
+			func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, `{"error":{"code":403,"status":"PERMISSION_DENIED"}}`, http.StatusForbidden)
+			},
+			[]string{"google account gce is not allowed to test its IAM permissions on project my-project, check the project exists and the service account has a role on it"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			iam := httptest.NewServer(c.handler)
+			defer iam.Close()
+			spinsvc := test.ManifestFileToSpinService("testdata/spinvc_google.yml", t)
+			ctx := account.NewValidationContext(googleKeyContext(), account.ValidationOptions{Connectivity: true})
+			g := &googleAccountValidator{
+				endpoint: iam.URL,
+				newClient: func(ctx context.Context, acc GoogleAccount) (*http.Client, error) {
+					return iam.Client(), nil
+				},
+			}
+			result := g.Validate(spinsvc, Options{Ctx: ctx})
+			assert.Empty(t, result.Errors)
+			vc, _ := account.ValidationContextFrom(ctx)
+			if c.warnings == nil {
+				assert.Empty(t, vc.Warnings())
+			} else {
+				assert.Equal(t, c.warnings, vc.Warnings())
+			}
+		})
+	}
+}
+
+func Test_googleAccountValidator_connectivityDisabled(t *testing.T) {
+	spinsvc := test.ManifestFileToSpinService("testdata/spinvc_google.yml", t)
+	ctx := account.NewValidationContext(googleKeyContext(), account.ValidationOptions{})
+	g := &googleAccountValidator{newClient: func(ctx context.Context, acc GoogleAccount) (*http.Client, error) {
+		return nil, fmt.Errorf("unexpected call")
+	}}
+	assert.Empty(t, g.Validate(spinsvc, Options{Ctx: ctx}).Errors)
+	vc, _ := account.ValidationContextFrom(ctx)
+	assert.Empty(t, vc.Warnings())
+}
+
+func Test_googleAccountValidator_projectIdFormat(t *testing.T) {
+	expected := "expected 6 to 30 lowercase letters, digits or hyphens, starting with a letter and not ending with a hyphen, e.g. my-project-123"
+	cases := []struct {
+		name    string
+		project string
+		err     string
+	}{
+		{"well-formed", "my-project-123", ""},
+		{"domain-scoped", "example.com:my-project", ""},
+		{"too short", "proj", "google account gce has invalid project ID \"proj\": " + expected},
+		{"uppercase", "My-Project", "google account gce has invalid project ID \"My-Project\": " + expected},
+		{"starts with a digit", "1project", "google account gce has invalid project ID \"1project\": " + expected},
+		{"ends with a hyphen", "my-project-", "google account gce has invalid project ID \"my-project-\": " + expected},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			spinsvc := test.ManifestFileToSpinService("testdata/spinvc_google.yml", t)
+			acc := map[string]interface{}{"name": "gce", "project": c.project}
+			if !assert.Nil(t, spinsvc.GetSpinnakerConfig().SetHalConfigProp(googleAccountsKey, []interface{}{acc})) {
+				return
+			}
+			// the format is checked before connectivity, no client is created
+			g := &googleAccountValidator{newClient: func(ctx context.Context, acc GoogleAccount) (*http.Client, error) {
+				return nil, fmt.Errorf("unexpected call")
+			}}
+			ctx := account.NewValidationContext(context.TODO(), account.ValidationOptions{})
+			result := g.Validate(spinsvc, Options{Ctx: ctx})
+			if c.err == "" {
+				assert.Empty(t, result.Errors)
+			} else if assert.Len(t, result.Errors, 1) {
+				assert.Equal(t, c.err, result.Errors[0].Error())
+				assert.True(t, result.Fatal)
+			}
+		})
+	}
+}
+
+func Test_googleAccountValidator_jsonKey(t *testing.T) {
+	dir := t.TempDir()
+	key := filepath.Join(dir, "key.json")
+	kubeconfig := filepath.Join(dir, "kubeconfig")
+	if !assert.Nil(t, os.WriteFile(key, []byte(`{"type": "service_account", "project_id": "my-project", "private_key": "key"}`), 0600)) ||
+		!assert.Nil(t, os.WriteFile(kubeconfig, []byte("apiVersion: v1\nkind: Config\nclusters:\n- name: cluster\n  cluster:\n    server: https://mycluster.com\n"), 0600)) {
+		return
+	}
+	cases := []struct {
+		name     string
+		jsonPath string
+		err      string
+	}{
+		{"GCP key", key, ""},
+		{"kubeconfig", kubeconfig, "malformed secret " + kubeconfig + ": credential does not look like a GCP JSON key credential (found kubeconfig instead)"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			spinsvc := test.ManifestFileToSpinService("testdata/spinvc_google.yml", t)
+			acc := map[string]interface{}{"name": "gce", "project": "my-project", "jsonPath": c.jsonPath}
+			if !assert.Nil(t, spinsvc.GetSpinnakerConfig().SetHalConfigProp(googleAccountsKey, []interface{}{acc})) {
+				return
+			}
+			// the key is checked offline, without connectivity
+			g := &googleAccountValidator{newClient: func(ctx context.Context, acc GoogleAccount) (*http.Client, error) {
+				return nil, fmt.Errorf("unexpected call")
+			}}
+			ctx := account.NewValidationContext(context.TODO(), account.ValidationOptions{})
+			result := g.Validate(spinsvc, Options{Ctx: ctx})
+			if c.err == "" {
+				assert.Empty(t, result.Errors)
+			} else if assert.Len(t, result.Errors, 1) {
+				assert.Equal(t, c.err, result.Errors[0].Error())
+				assert.True(t, errors.Is(result.Errors[0], secrets.ErrCredentialMismatch))
+			}
+		})
+	}
+}
+
+func Test_googleAccountValidator_canaryBuckets(t *testing.T) {
+	gcs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/storage/v1/b/canary":
+			json.NewEncoder(w).Encode(map[string]string{"name": "canary"})
+		case "/storage/v1/b/private":
+			http.Error(w, `{"error":{"code":403}}`, http.StatusForbidden)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer gcs.Close()
+
+	cases := []struct {
+		name     string
+		bucket   string
+		warnings []string
+	}{
+		{"existing bucket", "canary", nil},
+		{"missing bucket", "missing", []string{"bucket missing of google canary account kayenta does not exist"}},
+		{"permission denied", "private", []string{"google canary account kayenta is not allowed to access bucket private, check its service account has a storage role on it"}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			spinsvc := test.ManifestFileToSpinService("testdata/spinvc_google.yml", t)
+			canary := map[string]interface{}{
+				"enabled": true,
+				"serviceIntegrations": []interface{}{
+					map[string]interface{}{
+						"name":     "google",
+						"enabled":  true,
+						"accounts": []interface{}{map[string]interface{}{"name": "kayenta", "project": "my-project", "bucket": c.bucket}},
+					},
+				},
+			}
+			if !assert.Nil(t, spinsvc.GetSpinnakerConfig().SetHalConfigProp("canary", canary)) {
+				return
+			}
+			if !assert.Nil(t, spinsvc.GetSpinnakerConfig().SetHalConfigProp(googleAccountsKey, []interface{}{map[string]interface{}{"name": "gce"}})) {
+				return
+			}
+			ctx := account.NewValidationContext(context.TODO(), account.ValidationOptions{Connectivity: true})
+			g := &googleAccountValidator{
+				storageEndpoint: gcs.URL,
+				newClient: func(ctx context.Context, acc GoogleAccount) (*http.Client, error) {
+					return gcs.Client(), nil
+				},
+			}
+			assert.Empty(t, g.Validate(spinsvc, Options{Ctx: ctx}).Errors)
+			vc, _ := account.ValidationContextFrom(ctx)
+			if c.warnings == nil {
+				assert.Empty(t, vc.Warnings())
+			} else {
+				assert.Equal(t, c.warnings, vc.Warnings())
+			}
+		})
+	}
+}
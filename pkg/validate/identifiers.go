@@ -0,0 +1,30 @@
+package validate
+
+import (
+	"fmt"
+	"regexp"
+)
+
+var (
+	// awsAccountIDPattern matches the 12 digits of AWS account IDs, leading zeros included
+	awsAccountIDPattern = regexp.MustCompile(`^[0-9]{12}$`)
+	// googleProjectIDPattern matches project IDs, optionally scoped by a domain as in example.com:my-project
+	googleProjectIDPattern = regexp.MustCompile(`^([a-z0-9.-]+:)?[a-z][a-z0-9-]{4,28}[a-z0-9]$`)
+)
+
+// checkAwsAccountID returns an error if the account ID of the AWS account isn't well-formed
+func checkAwsAccountID(acc AwsAccount) error {
+	if acc.AccountId == "" || awsAccountIDPattern.MatchString(acc.AccountId) {
+		return nil
+	}
+	return fmt.Errorf("aws account %s has invalid account ID \"%s\": expected 12 digits, e.g. 123456789012", acc.Name, acc.AccountId)
+}
+
+// checkGoogleProjectID returns an error if the project of the Google account isn't a well-formed project ID
+func checkGoogleProjectID(acc GoogleAccount) error {
+	if acc.Project == "" || googleProjectIDPattern.MatchString(acc.Project) {
+		return nil
+	}
+	return fmt.Errorf("google account %s has invalid project ID \"%s\": expected 6 to 30 lowercase letters, digits or hyphens, "+
+		"starting with a letter and not ending with a hyphen, e.g. my-project-123", acc.Name, acc.Project)
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/armory/spinnaker-operator/pkg/apis/spinnaker/interfaces"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"time"
 )
 
 type ParallelValidator struct {
@@ -25,7 +26,9 @@ func (p *ParallelValidator) Validate(spinSvc interfaces.SpinnakerService, option
 		func(v SpinnakerValidator) {
 			valGrp.StartWithContext(ctx, func(ctx context.Context) {
 				options.Log.Info(fmt.Sprintf("Running validator %T", v))
+				start := time.Now()
 				res := v.Validate(spinSvc, options)
+				options.Log.V(2).Info(fmt.Sprintf("Validator %T finished", v), "duration", time.Since(start).String())
 				resCh <- res
 				if res.HasFatalErrors() {
 					options.Log.Info(fmt.Sprintf("Validator %T detected a fatal error", v))
@@ -19,6 +19,7 @@ var ParallelValidators = []SpinnakerValidator{
 	&cloudFoundryValidator{},
 	&awsAccountValidator{},
 	&lambdaValidator{},
+	&googleAccountValidator{},
 }
 
 type SpinnakerValidator interface {